@@ -0,0 +1,137 @@
+package explain
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/config"
+	"github.com/jakbytes/version_actions/internal/logger"
+	"github.com/jakbytes/version_actions/tools"
+	"github.com/jakbytes/version_actions/tools/changelog"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/jakbytes/version_actions/tools/github/composite"
+	"github.com/jakbytes/version_actions/tools/github/local"
+	"github.com/jakbytes/version_actions/tools/semver"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+var NewClient = github.NewClient
+
+type Args struct {
+	Token     string
+	Owner     string
+	Name      string
+	Head      string
+	Base      string
+	Graduate  bool
+	ReleaseAs *semver.Version
+	config.Settings
+}
+
+func setup(input []string) (client *github.Client, args Args, err error) {
+	flags := cli.NewFlagSet("explain", "Explains the next version the version action computes for the head branch: "+
+		"the base version, the range of commits, how each commit was classified, which commit requires the increment "+
+		"and how the prerelease number was derived.\nThe explanation is printed as Markdown and appended to "+
+		"GITHUB_STEP_SUMMARY. Nothing is written to the repository.")
+	var inputs config.Inputs
+	var releaseAs string
+	flags.TokenVar(&args.Token)
+	flags.StringVar(&args.Owner, "owner", "", "owner of the repository", cli.Env("GITHUB_REPOSITORY_OWNER"))
+	flags.StringVar(&args.Name, "name", "", "name of the repository", cli.RepositoryName)
+	flags.StringVar(&args.Head, "head", "", "the branch to version", cli.Env("GITHUB_REF_NAME"))
+	flags.StringVar(&args.Base, "base", "", "the base branch the release pull request is opened against", cli.Input("base"))
+	flags.StringVar(&inputs.Prerelease, "prerelease", "", "the prerelease identifier, overrides the configuration file (default \"rc\")", cli.Input("prerelease"))
+	flags.StringVar(&inputs.ReleaseBranch, "release-branch", "", "the primary release branch if it is not the default repository branch", cli.Input("release_branch"))
+	flags.StringVar(&inputs.Backend, "backend", "", "how the repository is read, api or local (default \"api\")", cli.Input("backend"))
+	flags.StringVar(&inputs.PreMajor, "pre-major", "", "below 1.0.0 increment the minor version for breaking changes, true or false, overrides the configuration file", cli.Input("pre_major"))
+	flags.BoolVar(&args.Graduate, "graduate", "explain the version proposed with --graduate of the version command", cli.Input("graduate"))
+	flags.StringVar(&releaseAs, "release-as", "", "explain the version proposed with --release-as of the version command", cli.Input("release_as"))
+	flags.Require("owner", "name", "head", "base")
+	if err = flags.Parse(input); err != nil {
+		return nil, args, err
+	}
+	if releaseAs != "" {
+		if args.ReleaseAs, err = conventional.ParseReleaseAs(releaseAs); err != nil {
+			return nil, args, cli.UsageError{Err: fmt.Errorf("invalid value for release-as: %w", err)}
+		}
+	}
+
+	if args.Settings, err = config.Resolve(inputs); err != nil {
+		return nil, args, err
+	}
+	if args.ReleaseAs != nil && len(args.Components) > 0 {
+		return nil, args, cli.UsageError{Err: errors.New("release-as can not be used with components, add a Release-As footer to a commit of the component instead")}
+	}
+
+	client, err = local.Select(NewClient(context.Background(), args.Token, args.Owner, args.Name), args.Backend)
+	if err != nil {
+		return nil, args, err
+	}
+	if err = args.ResolveReleaseBranch(client); err != nil {
+		return nil, args, err
+	}
+	return
+}
+
+func explain(input []string) error {
+	client, args, err := setup(input)
+	if err != nil {
+		return err
+	}
+	defer args.Config.Apply()()
+
+	h := &composite.Handler{
+		Client:               client,
+		Owner:                args.Owner,
+		Name:                 args.Name,
+		Head:                 args.Head,
+		Base:                 args.Base,
+		PrereleaseIdentifier: args.PrereleaseIdentifier,
+		ReleaseBranch:        args.ReleaseBranch,
+		PreMajor:             args.PreMajor,
+		Graduate:             args.Graduate,
+		ReleaseAs:            args.ReleaseAs,
+		ReleaseBranchPrefix:  args.ReleaseBranchPrefix,
+	}
+	handlers := []*composite.Handler{h}
+	if len(args.Components) > 0 {
+		handlers = nil
+		for _, component := range args.Components {
+			handlers = append(handlers, h.ForComponent(component))
+		}
+	}
+
+	var explanation changelog.Markdown
+	for i, handler := range handlers {
+		md, err := handler.Explain()
+		if err != nil {
+			if handler.Component != nil {
+				return fmt.Errorf("failed to explain component %s: %w", handler.Component.Name, err)
+			}
+			return err
+		}
+		if i > 0 {
+			explanation = append(explanation, "")
+		}
+		explanation = append(explanation, md...)
+	}
+
+	if _, err = fmt.Fprintln(os.Stdout, explanation.String()); err != nil {
+		return fmt.Errorf("failed to write the explanation: %w", err)
+	}
+	return tools.AppendSummary(explanation.String())
+}
+
+// Execute runs the explain command with the command line arguments that follow the subcommand.
+func Execute(input []string) error {
+	log.Logger = logger.Base()
+	err := explain(input)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,167 @@
+package explain
+
+import (
+	"context"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/mocks"
+	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// the token is read from the environment, it is not accepted as a flag
+	_ = os.Setenv("INPUT_TOKEN", "token")
+	os.Exit(m.Run())
+}
+
+func commit(sha string, message string, day int) *github.RepositoryCommit {
+	return &github.RepositoryCommit{
+		SHA: github.String(sha),
+		Commit: &github.Commit{
+			Message:   github.String(message),
+			Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}},
+		},
+	}
+}
+
+func newClient(repositories *mocks.RepositoryService) func(ctx context.Context, token string, owner string, name string) *github.Client {
+	return func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories:       repositories,
+			Git:                &mocks.GitService{CreateRefError: assert.AnError, UpdateRefError: assert.AnError},
+			PullRequests:       &mocks.PullRequestsService{},
+			RepositoryMetadata: github.RepositoryMetadata{Owner: owner, Name: name},
+		}
+	}
+}
+
+// explainWith runs the explain command and returns what it printed and appended to the step summary.
+func explainWith(t *testing.T, input []string) (string, string) {
+	summary := filepath.Join(t.TempDir(), "summary")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	os.Stdout = w
+	err = explain(input)
+	os.Stdout = stdout
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+	printed, err := io.ReadAll(r)
+	require.Nil(t, err)
+	content, err := os.ReadFile(summary)
+	require.Nil(t, err)
+	return string(printed), string(content)
+}
+
+func TestExplain(t *testing.T) {
+	NewClient = newClient(&mocks.RepositoryService{
+		Commits: []*github.RepositoryCommit{
+			commit("sha6-sha6", "revert: feat: api feature", 6),
+			commit("sha5-sha5", "wip: experiment", 5),
+			commit("sha4-sha4", "feat(api)!: drop v1 | legacy", 4),
+			commit("sha3-sha3", "feat: api feature", 3),
+			commit("sha2-sha2", "updated the readme", 2),
+			commit("sha1-sha1", "chore: init", 1),
+		},
+		Tags: []*github.RepositoryTag{
+			{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}},
+			{Name: github.String("v2.0.0-rc.1"), Commit: &github.Commit{SHA: github.String("sha4-sha4")}},
+		},
+	})
+
+	printed, summary := explainWith(t, []string{"--owner", "owner", "--name", "name", "--head", "development", "--base", "development", "--release-branch", "development"})
+	require.Equal(t, printed, summary)
+	require.Equal(t, strings.Join([]string{
+		"## Next version `v2.0.0`",
+		"",
+		"| | |",
+		"|---|---|",
+		"| Base version | `v1.0.0` at `sha1-sh`, the release tag nearest to the head of `development` |",
+		"| Latest prerelease | `v2.0.0-rc.1`, the highest `rc` prerelease tag |",
+		"| Commit range | the commits of `development` since `v1.0.0` |",
+		"| Increment | major, required by `sha4-sh`, the oldest commit classified as breaking |",
+		"| Next version | `v2.0.0` |",
+		"",
+		"### Commits",
+		"",
+		"| Commit | Message | Classification | Bump |",
+		"|---|---|---|---|",
+		"| `sha6-sh` | revert: feat: api feature | ignored, cancels out with `sha3-sh` | – |",
+		"| `sha5-sh` | wip: experiment | ignored, unrecognized type `wip` | – |",
+		"| `sha4-sh` | feat(api)!: drop v1 \\| legacy | breaking (`feat` with a breaking change) | major |",
+		"| `sha3-sh` | feat: api feature | ignored, cancels out with `sha6-sh` | – |",
+		"| `sha2-sh` | updated the readme | ignored, not a conventional commit | – |",
+		"",
+		"### Commits that failed to parse",
+		"",
+		"- `sha2-sh` updated the readme: early exit after 'e' character: col=17",
+		"",
+		"### Version derivation",
+		"",
+		"1. The major increment of 1.0.0 is 2.0.0.",
+		"2. The base branch development is the release branch, the version is a release version.",
+		"",
+	}, "\n"), printed)
+}
+
+func TestExplain_Prerelease(t *testing.T) {
+	NewClient = newClient(&mocks.RepositoryService{
+		Commits: []*github.RepositoryCommit{
+			commit("sha2-sha2", "fix: bug", 2),
+			commit("sha1-sha1", "chore: init", 1),
+		},
+		Comparison: &github.CommitsComparison{Commits: []*github.RepositoryCommit{
+			commit("sha3-sha3", "chore(deps): bump zerolog", 3),
+		}},
+		Tags: []*github.RepositoryTag{
+			{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}},
+			{Name: github.String("v1.0.1-rc.2"), Commit: &github.Commit{SHA: github.String("sha2-sha2")}},
+		},
+	})
+
+	printed, _ := explainWith(t, []string{"--owner", "owner", "--name", "name", "--head", "development", "--base", "development", "--release-branch", "main"})
+	require.Contains(t, printed, "## Next version `v1.0.1-rc.3`\n")
+	require.Contains(t, printed, "| Commit range | the commits of `development` that are not on the release branch `main` |\n")
+	require.Contains(t, printed, "| Increment | patch, required by the dependency update `sha3-sh` |\n")
+	require.Contains(t, printed, `1. The patch increment of 1.0.0 is 1.0.1.
+2. The base branch development is not the release branch main, the version is a rc prerelease.
+3. The latest prerelease 1.0.1-rc.2 is of the same version, the prerelease number is incremented to 3.
+`)
+}
+
+func TestExplain_ReleaseAs(t *testing.T) {
+	NewClient = newClient(&mocks.RepositoryService{
+		Commits: []*github.RepositoryCommit{
+			commit("sha2-sha2", "fix: bug", 2),
+			commit("sha1-sha1", "chore: init", 1),
+		},
+		Tags: []*github.RepositoryTag{{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}}},
+	})
+
+	printed, _ := explainWith(t, []string{"--owner", "owner", "--name", "name", "--head", "main", "--base", "main", "--release-branch", "main", "--release-as", "3.0.0"})
+	require.Contains(t, printed, "## Next version `v3.0.0`\n")
+}
+
+func TestSetup_ReleaseAs(t *testing.T) {
+	for _, value := range []string{"three", "3.0.0-rc.1"} {
+		_, _, err := setup([]string{"--owner", "owner", "--name", "name", "--head", "main", "--base", "main", "--release-as", value})
+		require.ErrorAs(t, err, &cli.UsageError{}, value)
+	}
+
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+	require.Nil(t, os.Chdir(t.TempDir()))
+	defer func() { require.Nil(t, os.Chdir(wd)) }()
+	require.Nil(t, os.WriteFile(".version_actions.yml", []byte("version: 1\ncomponents:\n  - name: api\n    path: services/api\n"), 0644))
+	_, _, err = setup([]string{"--owner", "owner", "--name", "name", "--head", "main", "--base", "main", "--release-as", "3.0.0"})
+	require.ErrorAs(t, err, &cli.UsageError{})
+	require.EqualError(t, err, "release-as can not be used with components, add a Release-As footer to a commit of the component instead")
+}
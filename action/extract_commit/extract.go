@@ -2,26 +2,51 @@ package extract_commit
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/config"
 	"github.com/jakbytes/version_actions/tools"
 	"github.com/jakbytes/version_actions/tools/conventional"
 	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/jakbytes/version_actions/tools/github/local"
 	"github.com/leodido/go-conventionalcommits"
 	cparser "github.com/leodido/go-conventionalcommits/parser"
-	"os"
+	"github.com/rs/zerolog/log"
 	"strings"
 )
 
 var MaxDepth = 10
 
-func ExtractCommit() {
-	input := os.Args[2:]
-	token := input[0]
-	owner := input[1]
-	name := input[2]
-	branchName := input[3]
+// ExtractCommit outputs the last valid conventional commit of the branch, input holds the command line arguments that
+// follow the subcommand.
+func ExtractCommit(input []string) error {
+	var token, owner, name, branchName, backend string
+	flags := cli.NewFlagSet("extract_commit", "Extracts the last valid conventional commit of the branch.")
+	flags.TokenVar(&token)
+	flags.StringVar(&owner, "owner", "", "owner of the repository", cli.Env("GITHUB_REPOSITORY_OWNER"))
+	flags.StringVar(&name, "name", "", "name of the repository", cli.RepositoryName)
+	flags.StringVar(&branchName, "branch", "", "the branch to extract the commit from", cli.Env("GITHUB_REF_NAME"))
+	flags.StringVar(&backend, "backend", "", "how the repository is read, api or local (default \"api\")", cli.Input("backend"))
+	flags.Require("owner", "name", "branch")
+	err := flags.Parse(input)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
 
-	client := github.NewClient(context.Background(), token, owner, name)
+	settings, err := config.Resolve(config.Inputs{Backend: backend})
+	if err != nil {
+		return err
+	}
+	defer settings.Config.Apply()()
+
+	client, err := local.Select(github.NewClient(context.Background(), token, owner, name), settings.Backend)
+	if err != nil {
+		return err
+	}
 
 	parser := conventional.Parser{Machine: cparser.NewMachine(
 		conventionalcommits.WithTypes(conventionalcommits.TypesFreeForm),
@@ -30,23 +55,20 @@ func ExtractCommit() {
 
 	branch, err := client.Repository().Branch(branchName)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to get branch %s: %w", branchName, err)
 	}
 
 	commits := branch.Commits(1)
-	if err != nil {
-		panic(err)
-	}
 
 	for commits.Next() {
 		for _, rawCommit := range commits.Commits() {
 			commit := parser.ParseCommit(rawCommit)
 			if commit != nil && commit.Ok() {
-				tools.OpenOutput(func(out tools.Output) {
+				return tools.OpenOutput(func(out *tools.Output) {
 					out.Set("type", &commit.Type)
 					out.Set("description", &commit.Description)
 					out.Set("scope", commit.Scope)
-					out.Set("exclamation", tools.String(fmt.Sprintf("%t", commit.Exclamation)))
+					out.SetBool("exclamation", commit.Exclamation)
 					out.Set("body", commit.Body)
 
 					// Handle footers; since footers are a map, you might want to join them as a single string
@@ -60,11 +82,12 @@ func ExtractCommit() {
 						out.Set("footers", tools.String(strings.Join(footers, "; ")))
 					}
 				})
-				if err != nil {
-					panic(err)
-				}
-				os.Exit(0)
 			}
 		}
 	}
+	if err = commits.Err(); err != nil {
+		return fmt.Errorf("failed to list the commits of %s: %w", branchName, err)
+	}
+	log.Info().Msgf("No conventional commit found on %s", branchName)
+	return nil
 }
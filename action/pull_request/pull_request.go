@@ -3,53 +3,56 @@ package pull_request
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/config"
 	"github.com/jakbytes/version_actions/internal/logger"
+	"github.com/jakbytes/version_actions/tools"
 	"github.com/jakbytes/version_actions/tools/changelog"
 	"github.com/jakbytes/version_actions/tools/conventional"
 	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/jakbytes/version_actions/tools/github/composite"
+	"github.com/jakbytes/version_actions/tools/github/local"
+	"github.com/jakbytes/version_actions/tools/semver"
 	"github.com/rs/zerolog/log"
-	"os"
 	"strings"
 )
 
 var NewClient = github.NewClient
 
 type Args struct {
-	Action string
-	Token  string
-	Owner  string
-	Name   string
-	Head   string
-	Base   string
+	Token   string
+	Owner   string
+	Name    string
+	Head    string
+	Base    string
+	Backend string
 }
 
-func getArgs() Args {
-	args := os.Args[1:]
-
-	if len(args) < 6 {
-		panic("Usage: program token owner name head base")
-	}
-
-	return Args{
-		Action: args[0],
-		Token:  args[1],
-		Owner:  args[2],
-		Name:   args[3],
-		Head:   args[4],
-		Base:   args[5],
-	}
+func getArgs(input []string) (args Args, err error) {
+	flags := cli.NewFlagSet("pull_request", "Opens or updates a draft pull request from the head branch into the base "+
+		"branch with the changelog of the commits it introduces.")
+	flags.TokenVar(&args.Token)
+	flags.StringVar(&args.Owner, "owner", "", "owner of the repository", cli.Env("GITHUB_REPOSITORY_OWNER"))
+	flags.StringVar(&args.Name, "name", "", "name of the repository", cli.RepositoryName)
+	flags.StringVar(&args.Head, "head", "", "the branch to open the pull request from", cli.Env("GITHUB_REF_NAME"))
+	flags.StringVar(&args.Base, "base", "", "the base branch to open the pull request against", cli.Input("base"))
+	flags.StringVar(&args.Backend, "backend", "", "how the repository is read, api or local (default \"api\")", cli.Input("backend"))
+	flags.Require("owner", "name", "head", "base")
+	err = flags.Parse(input)
+	return
 }
 
-// composeTitle composes a pull request title based on the commit message.
-func composeTitle(branch *github.Branch) (title string, err error) {
+// composeTitle composes a pull request title based on the commit message, titles longer than maxLength are truncated.
+func composeTitle(branch *github.Branch, maxLength int) (title string, err error) {
 	title, err = branch.GetLastCommitMessage()
 	if err != nil {
 		return
 	}
 
-	if len(title) > 70 {
-		title = title[:70] + "..."
+	if len(title) > maxLength {
+		title = title[:maxLength] + "..."
 	}
 	return title, nil
 }
@@ -60,27 +63,13 @@ func composeBody(head *github.Branch, base string, existing *string) (body chang
 		return
 	}
 	pc := conventional.ParseCommits(commits)
-	cl := changelog.GenerateNewChangelog(head.RepositoryMetadata.Owner, head.RepositoryMetadata.Name, nil, nil, pc, true)
-	if existing == nil { // Create a new body
-		body = append(changelog.Markdown{
-			"### :robot: I have created a pull request *beep* *boop*",
-			"",
-			"### Notes",
-			"",
-			"You can add your personal notes here (above the 'Changelog' section). To ensure your notes and the " +
-				"automated changelog updates are maintained correctly, keep the 'Changelog' marker in place. If the " +
-				"'Changelog' marker is removed, the automated updates to the changelog will not occur. Personal notes " +
-				"above the 'Changelog' will be retained during updates, while content below it will be updated with each " +
-				"new commit.",
-			"",
-		},
-			cl...)
-
-		body = append(body, "#", "",
-			"This Changelog was composed by [version_action](https://github.com/jakbytes/version_action)",
-		)
-
+	data := changelog.Default().Data(head.RepositoryMetadata.Owner, head.RepositoryMetadata.Name, nil, nil, pc, true)
+	cl, err := changelog.Render(changelog.ChangelogTemplate, data)
+	if err != nil {
 		return
+	}
+	if existing == nil { // Create a new body
+		return changelog.RenderWith(changelog.PullRequestTemplate, data, cl)
 	} else {
 		return updateBody(existing, cl), nil
 	}
@@ -99,10 +88,21 @@ func updateBody(body *string, changelog []string) (lines []string) {
 	}
 }
 
-func setPullRequest() error {
-	args := getArgs()
-	ctx := context.Background()
-	client := NewClient(ctx, args.Token, args.Owner, args.Name)
+func setPullRequest(input []string) error {
+	args, err := getArgs(input)
+	if err != nil {
+		return err
+	}
+	settings, err := config.Resolve(config.Inputs{Backend: args.Backend})
+	if err != nil {
+		return err
+	}
+	defer settings.Config.Apply()()
+
+	client, err := local.Select(NewClient(context.Background(), args.Token, args.Owner, args.Name), settings.Backend)
+	if err != nil {
+		return err
+	}
 	repository := client.Repository()
 	head, err := repository.Branch(args.Head)
 	if err != nil {
@@ -112,7 +112,7 @@ func setPullRequest() error {
 	var title string
 	pr, err := client.GetPullRequest(args.Head, args.Base)
 	if errors.Is(err, github.NoPullRequestFoundError{Head: args.Head, Base: args.Base}) {
-		title, err = composeTitle(head)
+		title, err = composeTitle(head, settings.TitleMaxLength)
 		if err != nil {
 			return fmt.Errorf("failed to compose pull request title: %w", err)
 		}
@@ -122,15 +122,130 @@ func setPullRequest() error {
 		title = *pr.Title
 	}
 
-	return client.SetPullRequest(args.Head, args.Base, title, true, func(body *string) (changelog.Markdown, error) {
+	err = client.SetPullRequest(args.Head, args.Base, title, true, func(body *string) (changelog.Markdown, error) {
 		return composeBody(head, args.Base, body)
 	})
+	if err != nil || changelog.Style != changelog.StyleKeepAChangelog {
+		return err
+	}
+	return updateUnreleased(client, head, args.Base, settings.Components, settings.Config.Changelog.Unreleased == changelog.UnreleasedCommit)
 }
 
-func Execute() {
-	log.Logger = logger.Base()
-	err := setPullRequest()
+// UnreleasedMessage is the message of the commit updating the Unreleased section of the changelog file.
+const UnreleasedMessage = "docs(changelog): update the unreleased changes"
+
+// updateUnreleased writes the changes of the head branch that are not on the base branch to the Unreleased section of
+// the changelog files of the keepachangelog style, of the repository or of each component. With commit the files that
+// changed are committed to the head branch, otherwise the sections are written to the step summary and nothing is
+// pushed. The changelog files are read from the head branch rather than from the checkout, which is the merge commit of
+// the pull request on pull_request events.
+func updateUnreleased(client *github.Client, head *github.Branch, base string, components []composite.Component, commit bool) error {
+	commits, err := head.GetDistinctCommits(base)
+	if err != nil {
+		return err
+	}
+	if len(components) == 0 {
+		components = []composite.Component{{Changelog: changelog.Path}}
+	}
+
+	var files []github.File
+	for _, component := range components {
+		c := *client
+		c.TagPrefix = component.TagPrefix
+		repository := c.Repository()
+		changes, err := repository.CommitsInPath(commits, component.Path)
+		if err != nil {
+			return err
+		}
+		file := changelog.File{Path: component.Changelog, TagPrefix: component.TagPrefix}
+		if !commit {
+			if err = summarizeUnreleased(repository, head, file, changes); err != nil {
+				return err
+			}
+			continue
+		}
+		content, err := unreleased(repository, head, file, changes)
+		if err != nil {
+			return err
+		}
+		if content != "" {
+			files = append(files, github.File{Path: file.Path, Content: content})
+		}
+	}
+	if !commit {
+		return nil
+	}
+	if len(files) == 0 {
+		log.Info().Msg("The unreleased changes of the changelog are up to date")
+		return nil
+	}
+
+	tree, parent, err := head.AddFiles(files)
+	if err != nil {
+		return fmt.Errorf("failed to add the changelog to %s: %w", head.Name, err)
+	}
+	if err = head.CommitChanges(tree, parent, UnreleasedMessage); err != nil {
+		return fmt.Errorf("failed to commit the changelog to %s: %w", head.Name, err)
+	}
+	return nil
+}
+
+// renderUnreleased renders the Unreleased section listing the commits, compared to the nearest version of the head
+// branch.
+func renderUnreleased(repository *github.Repository, head *github.Branch, file changelog.File, commits map[string]*github.RepositoryCommit) (changelog.Markdown, error) {
+	var previous *semver.Version
+	latest, err := repository.NearestVersion(head.Name)
+	if err == nil {
+		previous = latest.Version
+	} else if !errors.Is(err, github.NoReleaseVersionFound{}) {
+		return nil, fmt.Errorf("failed to get the latest version: %w", err)
+	}
+
+	data := file.UnreleasedData(head.RepositoryMetadata.Owner, head.RepositoryMetadata.Name, previous, conventional.ParseCommits(commits))
+	return changelog.Render(changelog.ChangelogTemplate, data)
+}
+
+// summarizeUnreleased writes the Unreleased section listing the commits to the step summary, nothing is written for a
+// component the pull request does not change.
+func summarizeUnreleased(repository *github.Repository, head *github.Branch, file changelog.File, commits map[string]*github.RepositoryCommit) error {
+	if len(commits) == 0 {
+		return nil
+	}
+	rendered, err := renderUnreleased(repository, head, file, commits)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	return tools.AppendSummary(fmt.Sprintf("Unreleased changes of `%s`:\n\n%s", file.Path, rendered.String()))
+}
+
+// unreleased returns the content of the changelog file on the head branch with the Unreleased section listing the
+// commits, or an empty string if the section is up to date.
+func unreleased(repository *github.Repository, head *github.Branch, file changelog.File, commits map[string]*github.RepositoryCommit) (string, error) {
+	rendered, err := renderUnreleased(repository, head, file, commits)
+	if err != nil {
+		return "", err
+	}
+	existing, err := head.GetFile(file.Path)
+	if errors.Is(err, github.FileNotFound{Path: file.Path, Branch: head.Name}) {
+		if len(commits) == 0 {
+			return "", nil // no changelog is created for a component the pull request does not change
+		}
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file.Path, err)
+	}
+	content := changelog.MergeUnreleased(existing, rendered).String() + "\n"
+	if content == existing {
+		return "", nil
+	}
+	return content, nil
+}
+
+// Execute runs the pull request action with the command line arguments that follow the subcommand.
+func Execute(input []string) error {
+	log.Logger = logger.Base()
+	err := setPullRequest(input)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
 	}
+	return err
 }
@@ -2,9 +2,14 @@ package pull_request
 
 import (
 	"context"
+	gogithub "github.com/google/go-github/v58/github"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/config"
 	"github.com/jakbytes/version_actions/internal/mocks"
+	"github.com/jakbytes/version_actions/tools/changelog"
 	"github.com/jakbytes/version_actions/tools/github"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -12,6 +17,20 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestMain(m *testing.M) {
+	// the token is read from the environment, it is not accepted as a flag
+	_ = os.Setenv("INPUT_TOKEN", "token")
+	os.Exit(m.Run())
+}
+
+// chdir changes the working directory to a temporary directory for the test.
+func chdir(t *testing.T) {
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+	require.Nil(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { require.Nil(t, os.Chdir(wd)) })
+}
+
 func TestComposePullRequestTitle(t *testing.T) {
 	client := github.NewClient(context.Background(), "token", "owner", "name")
 	client.Repositories = &mocks.RepositoryService{
@@ -29,7 +48,7 @@ func TestComposePullRequestTitle(t *testing.T) {
 	branch, err := client.Repository().Branch("branch")
 	require.Nil(t, err)
 
-	title, err := composeTitle(branch)
+	title, err := composeTitle(branch, config.DefaultTitleMaxLength)
 	require.Nil(t, err)
 
 	require.Equal(t, "feat: commit message", title)
@@ -44,7 +63,7 @@ func TestComposePullRequestTitle_Error(t *testing.T) {
 	branch, err := client.Repository().Branch("branch")
 	require.Nil(t, err)
 
-	_, err = composeTitle(branch)
+	_, err = composeTitle(branch, config.DefaultTitleMaxLength)
 	require.NotNil(t, err)
 	require.Equal(t, assert.AnError, err)
 }
@@ -66,7 +85,7 @@ func TestComposePullRequestTitle_LongMessage(t *testing.T) {
 	branch, err := client.Repository().Branch("branch")
 	require.Nil(t, err)
 
-	title, err := composeTitle(branch)
+	title, err := composeTitle(branch, config.DefaultTitleMaxLength)
 	require.Nil(t, err)
 
 	require.Equal(t, "feat: commit message with a long message that is over 70 characters, t...", title)
@@ -354,33 +373,159 @@ func TestComposePullRequestBody_Error(t *testing.T) {
 
 func TestGetArgsValid(t *testing.T) {
 	// Set up
-	originalArgs := os.Args
-	defer func() { os.Args = originalArgs }()
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}
 
 	// Execute
-	result := getArgs()
+	result, err := getArgs(input)
 
 	// Assert
+	require.Nil(t, err)
 	expected := Args{
-		Action: "action",
-		Token:  "token",
-		Owner:  "owner",
-		Name:   "name",
-		Head:   "head",
-		Base:   "base",
+		Token: "token",
+		Owner: "owner",
+		Name:  "name",
+		Head:  "head",
+		Base:  "base",
 	}
 	assert.Equal(t, expected, result, "The two structs should be equal")
 }
 
 func TestGetArgsInvalid(t *testing.T) {
 	// Set up
-	originalArgs := os.Args
-	defer func() { os.Args = originalArgs }()
-	os.Args = []string{"program", "action"}
+	t.Setenv("INPUT_BASE", "")
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head"}
 
 	// Execute and Assert
-	assert.Panics(t, func() { getArgs() }, "The function should panic for invalid args")
+	_, err := getArgs(input)
+	assert.Equal(t, cli.MissingInputError{Name: "base", Sources: []string{"--base", "INPUT_BASE"}}, err)
+}
+
+func TestSetPullRequest_KeepAChangelog(t *testing.T) {
+	style, tmpl := changelog.Style, changelog.Templates[changelog.ChangelogTemplate]
+	defer func() {
+		changelog.Style, changelog.Templates[changelog.ChangelogTemplate] = style, tmpl
+	}()
+	changelog.Style, changelog.Templates[changelog.ChangelogTemplate] = changelog.StyleKeepAChangelog, changelog.StyleTemplate(changelog.StyleKeepAChangelog)
+	// the changelog of the checkout, the merge commit of the pull request, is not the one on the head branch
+	chdir(t)
+	require.Nil(t, os.WriteFile(".version_actions.yml", []byte("version: 1\nchangelog:\n  style: keepachangelog\n  unreleased: commit\n"), 0644))
+	require.Nil(t, os.WriteFile(changelog.Path, []byte("# Changelog\n\n## [Unreleased]\n\n- merged\n"), 0644))
+
+	var blobs []*gogithub.Blob
+	var commits []*gogithub.Commit
+	repositories := &mocks.RepositoryService{
+		Commits: []*github.RepositoryCommit{{SHA: github.String("hash0"), Commit: &github.Commit{
+			Message: github.String("chore: init"),
+			Tree:    &github.Tree{SHA: github.String("tree0")},
+		}}},
+		Tags:     []*github.RepositoryTag{{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("hash0")}}},
+		Contents: map[string]string{"CHANGELOG.md": "# Changelog\n\n## [Unreleased]\n\n## [v1.0.0] - 2024-01-01\n\n- initial\n"},
+	}
+	prs := &mocks.PullRequestsService{PullRequests: []*github.PullRequest{}}
+	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories:       repositories,
+			Git:                &mocks.GitService{Blobs: &blobs, Commits: &commits},
+			PullRequests:       prs,
+			RepositoryMetadata: github.RepositoryMetadata{Owner: owner, Name: name},
+		}
+	}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}
+
+	require.Nil(t, setPullRequest(input))
+	expected := "# Changelog\n\n## [Unreleased]\n\n" +
+		"### Added\n\n- ([`hash1-h`](https://github.com/owner/name/commit/hash1-hash1)) message1\n\n" +
+		"### Fixed\n\n- ([`hash2-h`](https://github.com/owner/name/commit/hash2-hash2)) message2\n\n" +
+		"## [v1.0.0] - 2024-01-01\n\n- initial\n\n" +
+		"[Unreleased]: https://github.com/owner/name/compare/v1.0.0...HEAD\n"
+	require.Len(t, blobs, 1)
+	assert.Equal(t, expected, blobs[0].GetContent())
+	require.Len(t, commits, 1)
+	assert.Equal(t, UnreleasedMessage, commits[0].GetMessage())
+
+	// the changelog is not committed again if the unreleased changes on the head branch are up to date
+	repositories.Contents["CHANGELOG.md"] = expected
+	require.Nil(t, setPullRequest(input))
+	assert.Len(t, commits, 1)
+}
+
+func TestSetPullRequest_KeepAChangelog_Summary(t *testing.T) {
+	style, tmpl := changelog.Style, changelog.Templates[changelog.ChangelogTemplate]
+	defer func() {
+		changelog.Style, changelog.Templates[changelog.ChangelogTemplate] = style, tmpl
+	}()
+	changelog.Style, changelog.Templates[changelog.ChangelogTemplate] = changelog.StyleKeepAChangelog, changelog.StyleTemplate(changelog.StyleKeepAChangelog)
+	chdir(t)
+	summary := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+
+	var blobs []*gogithub.Blob
+	var commits []*gogithub.Commit
+	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories: &mocks.RepositoryService{
+				Commits: []*github.RepositoryCommit{{SHA: github.String("hash0"), Commit: &github.Commit{
+					Message: github.String("chore: init"),
+					Tree:    &github.Tree{SHA: github.String("tree0")},
+				}}},
+				Tags: []*github.RepositoryTag{{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("hash0")}}},
+			},
+			Git:                &mocks.GitService{Blobs: &blobs, Commits: &commits},
+			PullRequests:       &mocks.PullRequestsService{PullRequests: []*github.PullRequest{}},
+			RepositoryMetadata: github.RepositoryMetadata{Owner: owner, Name: name},
+		}
+	}
+
+	// without changelog.unreleased: commit nothing is pushed to the head branch
+	require.Nil(t, setPullRequest([]string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}))
+	assert.Empty(t, commits)
+	assert.Empty(t, blobs)
+	content, err := os.ReadFile(summary)
+	require.Nil(t, err)
+	assert.Equal(t, "Unreleased changes of `CHANGELOG.md`:\n\n## [Unreleased]\n\n"+
+		"### Added\n\n- ([`hash1-h`](https://github.com/owner/name/commit/hash1-hash1)) message1\n\n"+
+		"### Fixed\n\n- ([`hash2-h`](https://github.com/owner/name/commit/hash2-hash2)) message2\n\n"+
+		"[Unreleased]: https://github.com/owner/name/compare/v1.0.0...HEAD\n", string(content))
+}
+
+func TestSetPullRequest_KeepAChangelog_Components(t *testing.T) {
+	style, tmpl := changelog.Style, changelog.Templates[changelog.ChangelogTemplate]
+	defer func() {
+		changelog.Style, changelog.Templates[changelog.ChangelogTemplate] = style, tmpl
+	}()
+	changelog.Style, changelog.Templates[changelog.ChangelogTemplate] = changelog.StyleKeepAChangelog, changelog.StyleTemplate(changelog.StyleKeepAChangelog)
+	chdir(t)
+	require.Nil(t, os.WriteFile(".version_actions.yml", []byte("version: 1\nchangelog:\n  unreleased: commit\ncomponents:\n  - name: api\n    path: services/api\n  - name: web\n    path: web\n"), 0644))
+
+	var blobs []*gogithub.Blob
+	var commits []*gogithub.Commit
+	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories: &mocks.RepositoryService{
+				Commits: []*github.RepositoryCommit{{SHA: github.String("hash0"), Commit: &github.Commit{
+					Message: github.String("chore: init"),
+					Tree:    &github.Tree{SHA: github.String("tree0")},
+				}}},
+				Tags:  []*github.RepositoryTag{{Name: github.String("api/v1.0.0"), Commit: &github.Commit{SHA: github.String("hash0")}}},
+				Files: map[string][]string{"hash1-hash1": {"services/api/main.go"}, "hash2-hash2": {"README.md"}},
+			},
+			Git:                &mocks.GitService{Blobs: &blobs, Commits: &commits},
+			PullRequests:       &mocks.PullRequestsService{PullRequests: []*github.PullRequest{}},
+			RepositoryMetadata: github.RepositoryMetadata{Owner: owner, Name: name},
+		}
+	}
+
+	require.Nil(t, setPullRequest([]string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}))
+	// only the changelog of the component changed by the pull request is committed
+	require.Len(t, commits, 1)
+	require.Len(t, blobs, 1)
+	assert.Equal(t, "# Changelog\n\n"+
+		"All notable changes to this project will be documented in this file.\n\n"+
+		"The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),\n"+
+		"and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).\n\n"+
+		"## [Unreleased]\n\n"+
+		"### Added\n\n- ([`hash1-h`](https://github.com/owner/name/commit/hash1-hash1)) message1\n\n"+
+		"[Unreleased]: https://github.com/owner/name/compare/api/v1.0.0...HEAD\n", blobs[0].GetContent())
 }
 
 /*
@@ -398,11 +543,11 @@ func TestSetPullRequest(t *testing.T) {
 	}
 
 	// Set up command line arguments
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}
 
 	// Run the function
 	assert.NotPanics(t, func() {
-		Execute()
+		Execute(input)
 	}, "setPullRequest should not panic on successful run")
 
 	require.Equal(t, 1, len(prs.PullRequests))
@@ -470,7 +615,7 @@ func TestSetPullRequest_ExistingPullRequest(t *testing.T) {
 	}
 
 	// Set up command line arguments
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}
 
 	// Run the function
 	err := setPullRequest()
@@ -519,11 +664,11 @@ func TestSetPullRequest_FailedToComposePullRequestTitleError(t *testing.T) {
 	}
 
 	// Set up command line arguments
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}
 
 	// Run the function
 	assert.Panics(t, func() {
-		Execute()
+		Execute(input)
 	}, "setPullRequest should not panic on error")
 
 	err := setPullRequest()
@@ -546,7 +691,7 @@ func TestSetPullRequest_FailedToGetBranchError(t *testing.T) {
 	}
 
 	// Set up command line arguments
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}
 
 	// Run the function
 	err := setPullRequest()
@@ -565,7 +710,7 @@ func TestSetPullRequest_UnableToVerifyExistingPullRequestExistsError(t *testing.
 	}
 
 	// Set up command line arguments
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}
 
 	err := setPullRequest()
 	require.NotNil(t, err)
@@ -583,7 +728,7 @@ func TestSetPullRequest_FailedToComposePullRequestBodyError(t *testing.T) {
 	}
 
 	// Set up command line arguments
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}
 
 	err := setPullRequest()
 	require.NotNil(t, err)
@@ -612,7 +757,7 @@ func TestSetPullRequest_FailedToEditPullRequestError(t *testing.T) {
 	}
 
 	// Set up command line arguments
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"}
 
 	err := setPullRequest()
 	require.NotNil(t, err)
@@ -0,0 +1,187 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/config"
+	"github.com/jakbytes/version_actions/internal/logger"
+	"github.com/jakbytes/version_actions/tools"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/jakbytes/version_actions/tools/github/composite"
+	"github.com/jakbytes/version_actions/tools/github/local"
+	"github.com/jakbytes/version_actions/tools/semver"
+	"github.com/rs/zerolog/log"
+	"os"
+	"slices"
+	"strings"
+)
+
+var NewClient = github.NewClient
+
+type Args struct {
+	Token      string
+	Owner      string
+	Name       string
+	Branch     string
+	Graduate   bool
+	ReleaseAs  *semver.Version
+	DryRun     bool
+	PlanFormat string
+	config.Settings
+}
+
+// ComponentRelease is an entry of the components output, it describes a released component.
+type ComponentRelease struct {
+	Name         string `json:"name"`
+	Path         string `json:"path"`
+	Tag          string `json:"tag"`
+	URL          string `json:"url"`
+	ReleaseNotes string `json:"release_notes"`
+}
+
+func setup(input []string) (client *github.Client, args Args, err error) {
+	flags := cli.NewFlagSet("release", "Tags and publishes a GitHub release when the release pull request into the "+
+		"branch has been merged.")
+	var inputs config.Inputs
+	var releaseAs string
+	flags.TokenVar(&args.Token)
+	flags.StringVar(&args.Owner, "owner", "", "owner of the repository", cli.Env("GITHUB_REPOSITORY_OWNER"))
+	flags.StringVar(&args.Name, "name", "", "name of the repository", cli.RepositoryName)
+	flags.StringVar(&args.Branch, "branch", "", "the branch the release pull request was merged into", cli.Env("GITHUB_REF_NAME"))
+	flags.StringVar(&inputs.Prerelease, "prerelease", "", "the prerelease identifier, overrides the configuration file (default \"rc\")", cli.Input("prerelease"))
+	flags.StringVar(&inputs.ReleaseBranch, "release-branch", "", "the primary release branch if it is not the default repository branch", cli.Input("release_branch"))
+	flags.StringVar(&inputs.Backend, "backend", "", "how the repository is read, api or local (default \"api\")", cli.Input("backend"))
+	flags.StringVar(&inputs.PreMajor, "pre-major", "", "below 1.0.0 increment the minor version for breaking changes, true or false, overrides the configuration file", cli.Input("pre_major"))
+	flags.BoolVar(&args.Graduate, "graduate", "release 1.0.0 if the latest version is below 1.0.0, as proposed with --graduate of the version command", cli.Input("graduate"))
+	flags.StringVar(&releaseAs, "release-as", "", "release the version, e.g. 3.0.0, as proposed with --release-as of the version command", cli.Input("release_as"))
+	flags.BoolVar(&args.DryRun, "dry-run", "compute the release and print the plan of its writes without making them", cli.Input("dry_run"))
+	flags.StringVar(&args.PlanFormat, "plan-format", "text", "format of the dry run plan, text or json", cli.Input("plan_format"))
+	flags.Require("owner", "name", "branch")
+	if err = flags.Parse(input); err != nil {
+		return nil, args, err
+	}
+	if !slices.Contains(composite.PlanFormats, args.PlanFormat) {
+		return nil, args, cli.UsageError{Err: fmt.Errorf("unknown plan format %q, expected one of %s", args.PlanFormat, strings.Join(composite.PlanFormats, ", "))}
+	}
+
+	if releaseAs != "" {
+		if args.ReleaseAs, err = conventional.ParseReleaseAs(releaseAs); err != nil {
+			return nil, args, cli.UsageError{Err: fmt.Errorf("invalid value for release-as: %w", err)}
+		}
+	}
+
+	if args.Settings, err = config.Resolve(inputs); err != nil {
+		return nil, args, err
+	}
+	if args.ReleaseAs != nil && len(args.Components) > 0 {
+		return nil, args, cli.UsageError{Err: errors.New("release-as can not be used with components, add a Release-As footer to a commit of the component instead")}
+	}
+
+	client, err = local.Select(NewClient(context.Background(), args.Token, args.Owner, args.Name), args.Backend)
+	if err != nil {
+		return nil, args, err
+	}
+	if err = args.ResolveReleaseBranch(client); err != nil {
+		return nil, args, err
+	}
+	return
+}
+
+func release(input []string) error {
+	client, args, err := setup(input)
+	if err != nil {
+		return err
+	}
+	defer args.Config.Apply()()
+
+	h := &composite.Handler{
+		Client:               client,
+		Owner:                args.Owner,
+		Name:                 args.Name,
+		Head:                 args.Branch,
+		Base:                 args.Branch,
+		PrereleaseIdentifier: args.PrereleaseIdentifier,
+		ReleaseBranch:        args.ReleaseBranch,
+		PreMajor:             args.PreMajor,
+		Graduate:             args.Graduate,
+		ReleaseAs:            args.ReleaseAs,
+		ReleaseBranchPrefix:  args.ReleaseBranchPrefix,
+		Trigger:              "release",
+		DryRun:               args.DryRun,
+	}
+	if len(args.Components) > 0 {
+		return releaseComponents(h, args)
+	}
+
+	err = h.Release()
+	if err != nil {
+		return err
+	}
+	if args.DryRun {
+		if err = composite.WritePlans(os.Stdout, args.PlanFormat, h.Plan); err != nil {
+			return fmt.Errorf("failed to write the plan: %w", err)
+		}
+	}
+
+	if h.Released != nil {
+		return tools.OpenOutput(func(out *tools.Output) {
+			out.Set("version", h.Released.TagName)
+			out.Set("url", h.Released.HTMLURL)
+			out.SetString("release_notes", h.ReleaseNotes.String())
+		})
+	}
+	return nil
+}
+
+// releaseComponents releases each component whose release pull request was merged. The version and url outputs are
+// JSON objects keyed by component name, and the components output a JSON array of the released components.
+func releaseComponents(handler *composite.Handler, args Args) error {
+	versions := make(map[string]string)
+	urls := make(map[string]string)
+	released := make([]ComponentRelease, 0)
+	var plans []*composite.Plan
+	for _, component := range args.Components {
+		h := handler.ForComponent(component)
+		if err := h.Release(); err != nil {
+			return fmt.Errorf("failed to release component %s: %w", component.Name, err)
+		}
+		plans = append(plans, h.Plan)
+		if h.Released != nil {
+			versions[component.Name] = h.Released.GetTagName()
+			urls[component.Name] = h.Released.GetHTMLURL()
+			released = append(released, ComponentRelease{
+				Name:         component.Name,
+				Path:         component.Path,
+				Tag:          h.Released.GetTagName(),
+				URL:          h.Released.GetHTMLURL(),
+				ReleaseNotes: h.ReleaseNotes.String(),
+			})
+		}
+	}
+
+	if args.DryRun {
+		if err := composite.WritePlans(os.Stdout, args.PlanFormat, plans...); err != nil {
+			return fmt.Errorf("failed to write the plan: %w", err)
+		}
+	}
+
+	return tools.OpenOutput(func(out *tools.Output) {
+		out.SetJSON("version", versions)
+		out.SetJSON("url", urls)
+		out.SetJSON("components", released)
+	})
+}
+
+// Execute runs the release action with the command line arguments that follow the subcommand.
+func Execute(input []string) error {
+	log.Logger = logger.Base()
+	err := release(input)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,376 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/mocks"
+	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// the token is read from the environment, it is not accepted as a flag
+	_ = os.Setenv("INPUT_TOKEN", "token")
+	os.Exit(m.Run())
+}
+
+// chdir changes the working directory to a temporary directory for the test, where the release notes are written.
+func chdir(t *testing.T) {
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+	require.Nil(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { require.Nil(t, os.Chdir(wd)) })
+}
+
+func TestSetup(t *testing.T) {
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "branch", "--prerelease", "rc", "--release-branch", "main"}
+	client, args, err := setup(input)
+	require.Nil(t, err)
+
+	require.Equal(t, "token", args.Token)
+	require.Equal(t, "owner", args.Owner)
+	require.Equal(t, "name", args.Name)
+	require.Equal(t, "branch", args.Branch)
+	require.Equal(t, "rc", args.PrereleaseIdentifier)
+	require.Equal(t, "main", args.ReleaseBranch)
+	require.NotNil(t, client)
+}
+
+func TestSetup_MissingInput(t *testing.T) {
+	t.Setenv("GITHUB_REF_NAME", "")
+	_, _, err := setup([]string{"--owner", "owner", "--name", "name"})
+	require.Equal(t, cli.MissingInputError{Name: "branch", Sources: []string{"--branch", "GITHUB_REF_NAME"}}, err)
+}
+
+func TestSetup_DefaultReleaseBranch_Error(t *testing.T) {
+	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories: &mocks.RepositoryService{
+				GetError: assert.AnError,
+			},
+		}
+	}
+
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "branch", "--prerelease", "rc", "--release-branch", "."}
+	_, _, err := setup(input)
+	require.NotNil(t, err)
+	require.Equal(t, fmt.Errorf("failed to get default branch: %w", assert.AnError), err)
+}
+
+func newClient(repositories *mocks.RepositoryService, git *mocks.GitService, prs *mocks.PullRequestsService) func(ctx context.Context, token string, owner string, name string) *github.Client {
+	return func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories: repositories,
+			Git:          git,
+			PullRequests: prs,
+			RepositoryMetadata: github.RepositoryMetadata{
+				Owner: owner,
+				Name:  name,
+			},
+		}
+	}
+}
+
+// tags where v2.0.0 is on another line of history than the branch being released
+var tags = []*github.RepositoryTag{
+	{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("hash3-hash3")}},
+	{Name: github.String("v1.0.1"), Commit: &github.Commit{SHA: github.String("hash2-hash2")}},
+	{Name: github.String("v2.0.0"), Commit: &github.Commit{SHA: github.String("hash-other")}},
+}
+
+func TestRelease(t *testing.T) {
+	chdir(t)
+	output := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", output)
+
+	var refs []*github.Reference
+	repositories := &mocks.RepositoryService{Tags: tags}
+	prs := &mocks.PullRequestsService{
+		Closed: []*github.PullRequest{
+			{
+				Number:         github.Int(3),
+				Title:          github.String("release(main): v1.1.0"),
+				MergedAt:       &github.Timestamp{Time: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+				MergeCommitSHA: github.String("hash"),
+			},
+		},
+	}
+	NewClient = newClient(repositories, &mocks.GitService{Refs: &refs}, prs)
+
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "main", "--prerelease", "rc", "--release-branch", "main"}
+	require.Nil(t, release(input))
+
+	require.Len(t, refs, 1)
+	require.Equal(t, "refs/tags/v1.1.0", refs[0].GetRef())
+	require.Equal(t, "hash", refs[0].Object.GetSHA())
+
+	require.Len(t, repositories.Releases, 1)
+	published := repositories.Releases[0]
+	require.Equal(t, "v1.1.0", published.GetTagName())
+	require.False(t, published.GetPrerelease())
+	require.Contains(t, published.GetBody(), "## [v1.1.0](https://github.com/owner/name/compare/v1.0.1...v1.1.0)")
+	require.Contains(t, published.GetBody(), "message1")
+
+	outputs, err := os.ReadFile(output)
+	require.Nil(t, err)
+	require.Contains(t, string(outputs), "version=v1.1.0\n")
+	delimiter := regexp.MustCompile(`release_notes<<(ghadelimiter_[0-9a-f]+)\n`).FindStringSubmatch(string(outputs))
+	require.Len(t, delimiter, 2)
+	require.Contains(t, string(outputs), delimiter[0]+published.GetBody()+"\n"+delimiter[1]+"\n")
+}
+
+func TestRelease_DryRun(t *testing.T) {
+	chdir(t)
+	var refs []*github.Reference
+	repositories := &mocks.RepositoryService{Tags: tags}
+	prs := &mocks.PullRequestsService{
+		Closed: []*github.PullRequest{
+			{
+				Number:         github.Int(3),
+				Title:          github.String("release(main): v1.1.0"),
+				MergedAt:       &github.Timestamp{Time: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+				MergeCommitSHA: github.String("hash"),
+			},
+		},
+	}
+	NewClient = newClient(repositories, &mocks.GitService{Refs: &refs}, prs)
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	os.Stdout = w
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "main", "--prerelease", "rc", "--release-branch", "main", "--dry-run"}
+	err = release(input)
+	os.Stdout = stdout
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+	plan, err := io.ReadAll(r)
+	require.Nil(t, err)
+
+	require.Empty(t, refs)
+	require.Empty(t, repositories.Releases)
+	require.NoFileExists(t, "release.txt")
+	require.Contains(t, string(plan), "Latest version: v1.0.1\n")
+	require.Contains(t, string(plan), "Next version: v1.1.0\n")
+	require.Contains(t, string(plan), "Create tag v1.1.0 at hash\n")
+	require.Contains(t, string(plan), "Publish release v1.1.0:\n    | ## [v1.1.0](https://github.com/owner/name/compare/v1.0.1...v1.1.0)")
+	require.Contains(t, string(plan), "Write local files:\n  release.txt\n")
+}
+
+func TestRelease_Prerelease(t *testing.T) {
+	chdir(t)
+
+	var refs []*github.Reference
+	repositories := &mocks.RepositoryService{}
+	prs := &mocks.PullRequestsService{
+		Closed: []*github.PullRequest{
+			{
+				Number:         github.Int(4),
+				Title:          github.String("release(development): v1.1.0-rc.0"),
+				MergedAt:       &github.Timestamp{Time: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+				MergeCommitSHA: github.String("hash"),
+			},
+		},
+	}
+	NewClient = newClient(repositories, &mocks.GitService{Refs: &refs}, prs)
+
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "development", "--prerelease", "rc", "--release-branch", "main"}
+	require.Nil(t, release(input))
+
+	require.Len(t, refs, 1)
+	require.Equal(t, "refs/tags/v1.1.0-rc.0", refs[0].GetRef())
+	require.Len(t, repositories.Releases, 1)
+	require.True(t, repositories.Releases[0].GetPrerelease())
+}
+
+func TestRelease_NotMerged(t *testing.T) {
+	var refs []*github.Reference
+	repositories := &mocks.RepositoryService{}
+	prs := &mocks.PullRequestsService{
+		Closed: []*github.PullRequest{
+			{
+				Number:         github.Int(3),
+				Title:          github.String("release(main): v1.1.0"),
+				MergedAt:       &github.Timestamp{Time: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+				MergeCommitSHA: github.String("another-hash"),
+			},
+		},
+	}
+	NewClient = newClient(repositories, &mocks.GitService{Refs: &refs}, prs)
+
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "main", "--prerelease", "rc", "--release-branch", "main"}
+	require.Nil(t, release(input))
+
+	require.Empty(t, refs)
+	require.Empty(t, repositories.Releases)
+}
+
+func TestRelease_VersionMismatch(t *testing.T) {
+	var refs []*github.Reference
+	prs := &mocks.PullRequestsService{
+		Closed: []*github.PullRequest{
+			{
+				Number:         github.Int(3),
+				Title:          github.String("release(main): v2.0.0"),
+				MergedAt:       &github.Timestamp{Time: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+				MergeCommitSHA: github.String("hash"),
+			},
+		},
+	}
+	NewClient = newClient(&mocks.RepositoryService{Tags: tags}, &mocks.GitService{Refs: &refs}, prs)
+
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "main", "--prerelease", "rc", "--release-branch", "main"}
+	err := release(input)
+	require.NotNil(t, err)
+	require.Equal(t, "release pull request #3 proposes v2.0.0 but v1.1.0 was computed", err.Error())
+	require.Empty(t, refs)
+}
+
+func TestRelease_Graduate(t *testing.T) {
+	chdir(t)
+
+	var refs []*github.Reference
+	repositories := &mocks.RepositoryService{Tags: []*github.RepositoryTag{
+		{Name: github.String("v0.4.0"), Commit: &github.Commit{SHA: github.String("hash3-hash3")}},
+		{Name: github.String("v0.4.1"), Commit: &github.Commit{SHA: github.String("hash2-hash2")}},
+	}}
+	prs := &mocks.PullRequestsService{
+		Closed: []*github.PullRequest{
+			{
+				Number:         github.Int(3),
+				Title:          github.String("release(main): v1.0.0"),
+				MergedAt:       &github.Timestamp{Time: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+				MergeCommitSHA: github.String("hash"),
+			},
+		},
+	}
+	NewClient = newClient(repositories, &mocks.GitService{Refs: &refs}, prs)
+
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "main", "--prerelease", "rc", "--release-branch", "main"}
+	require.Nil(t, release(input))
+
+	require.Len(t, refs, 1)
+	require.Equal(t, "refs/tags/v1.0.0", refs[0].GetRef())
+	require.Len(t, repositories.Releases, 1)
+	require.Contains(t, repositories.Releases[0].GetBody(), "## [v1.0.0](https://github.com/owner/name/compare/v0.4.1...v1.0.0)")
+}
+
+func TestRelease_GraduateFlag(t *testing.T) {
+	chdir(t)
+
+	var refs []*github.Reference
+	repositories := &mocks.RepositoryService{Tags: []*github.RepositoryTag{
+		{Name: github.String("v0.4.0"), Commit: &github.Commit{SHA: github.String("hash3-hash3")}},
+		{Name: github.String("v0.4.1"), Commit: &github.Commit{SHA: github.String("hash2-hash2")}},
+	}}
+	prs := &mocks.PullRequestsService{
+		Closed: []*github.PullRequest{
+			{
+				Number:         github.Int(3),
+				Title:          github.String("release(main): v1.0.0"),
+				MergedAt:       &github.Timestamp{Time: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+				MergeCommitSHA: github.String("hash"),
+			},
+		},
+	}
+	NewClient = newClient(repositories, &mocks.GitService{Refs: &refs}, prs)
+
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "main", "--prerelease", "rc", "--release-branch", "main", "--graduate"}
+	require.Nil(t, release(input))
+
+	require.Len(t, refs, 1)
+	require.Equal(t, "refs/tags/v1.0.0", refs[0].GetRef())
+}
+
+func TestRelease_ReleaseAs(t *testing.T) {
+	chdir(t)
+
+	var refs []*github.Reference
+	date := func(day int) *github.CommitAuthor {
+		return &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2022, 1, day, 0, 0, 0, 0, time.UTC)}}
+	}
+	repositories := &mocks.RepositoryService{
+		Tags: []*github.RepositoryTag{
+			{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("hash3-hash3")}},
+			{Name: github.String("v1.0.1"), Commit: &github.Commit{SHA: github.String("hash2-hash2")}},
+		},
+		Commits: []*github.RepositoryCommit{
+			{SHA: github.String("hash9-hash9"), Commit: &github.Commit{Message: github.String("release(main): v3.0.0\n\nRelease-As: 3.0.0"), Committer: date(4)}},
+			{SHA: github.String("hash1-hash1"), Commit: &github.Commit{Message: github.String("feat: message1"), Committer: date(3)}},
+			{SHA: github.String("hash2-hash2"), Commit: &github.Commit{Message: github.String("fix: message2"), Committer: date(2)}},
+		},
+	}
+	prs := &mocks.PullRequestsService{
+		Closed: []*github.PullRequest{
+			{
+				Number:         github.Int(3),
+				Title:          github.String("release(main): v3.0.0"),
+				MergedAt:       &github.Timestamp{Time: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+				MergeCommitSHA: github.String("hash"),
+			},
+		},
+	}
+	NewClient = newClient(repositories, &mocks.GitService{Refs: &refs}, prs)
+
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "main", "--prerelease", "rc", "--release-branch", "main"}
+	require.Nil(t, release(input))
+
+	require.Len(t, refs, 1)
+	require.Equal(t, "refs/tags/v3.0.0", refs[0].GetRef())
+	require.Len(t, repositories.Releases, 1)
+	require.Contains(t, repositories.Releases[0].GetBody(), "> The version was set by the Release-As footer of commit hash9-h.")
+}
+
+func TestRelease_ReleaseAsSquashed(t *testing.T) {
+	chdir(t)
+
+	var refs []*github.Reference
+	date := func(day int) *github.CommitAuthor {
+		return &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2022, 1, day, 0, 0, 0, 0, time.UTC)}}
+	}
+	// the squash merge of the release pull request dropped the Release-As footer of its commit
+	repositories := &mocks.RepositoryService{
+		Tags: []*github.RepositoryTag{
+			{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("hash3-hash3")}},
+			{Name: github.String("v1.0.1"), Commit: &github.Commit{SHA: github.String("hash2-hash2")}},
+		},
+		Commits: []*github.RepositoryCommit{
+			{SHA: github.String("hash9-hash9"), Commit: &github.Commit{Message: github.String("release(main): v3.0.0 (#3)"), Committer: date(4)}},
+			{SHA: github.String("hash1-hash1"), Commit: &github.Commit{Message: github.String("feat: message1"), Committer: date(3)}},
+			{SHA: github.String("hash2-hash2"), Commit: &github.Commit{Message: github.String("fix: message2"), Committer: date(2)}},
+		},
+	}
+	prs := &mocks.PullRequestsService{
+		Closed: []*github.PullRequest{
+			{
+				Number:         github.Int(3),
+				Title:          github.String("release(main): v3.0.0"),
+				MergedAt:       &github.Timestamp{Time: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+				MergeCommitSHA: github.String("hash"),
+			},
+		},
+	}
+	NewClient = newClient(repositories, &mocks.GitService{Refs: &refs}, prs)
+
+	input := []string{"--owner", "owner", "--name", "name", "--branch", "main", "--prerelease", "rc", "--release-branch", "main"}
+	require.Equal(t, "release pull request #3 proposes v3.0.0 but v1.1.0 was computed", release(input).Error())
+	require.Empty(t, refs)
+
+	require.Nil(t, release(append(input, "--release-as", "3.0.0")))
+	require.Len(t, refs, 1)
+	require.Equal(t, "refs/tags/v3.0.0", refs[0].GetRef())
+	require.Contains(t, repositories.Releases[0].GetBody(), "> The version was set by the release-as input.")
+}
+
+func TestSetup_ReleaseAs(t *testing.T) {
+	_, _, err := setup([]string{"--owner", "owner", "--name", "name", "--branch", "main", "--release-as", "three"})
+	require.ErrorAs(t, err, &cli.UsageError{})
+}
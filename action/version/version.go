@@ -2,69 +2,107 @@ package version
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/config"
 	"github.com/jakbytes/version_actions/internal/logger"
 	"github.com/jakbytes/version_actions/tools"
+	"github.com/jakbytes/version_actions/tools/conventional"
 	"github.com/jakbytes/version_actions/tools/github"
 	"github.com/jakbytes/version_actions/tools/github/composite"
+	"github.com/jakbytes/version_actions/tools/github/local"
+	"github.com/jakbytes/version_actions/tools/semver"
 	"github.com/rs/zerolog/log"
 	"os"
+	"slices"
+	"strings"
 )
 
 var NewClient = github.NewClient
 
 type Args struct {
-	Action               string
-	Token                string
-	Owner                string
-	Name                 string
-	Head                 string
-	Base                 string
-	PrereleaseIdentifier string
-	ReleaseBranch        string
-	Trigger              string
-	CommitFiles          []string
+	Token      string
+	Owner      string
+	Name       string
+	Head       string
+	Base       string
+	Trigger    string
+	DryRun     bool
+	PlanFormat string
+	Graduate   bool
+	ReleaseAs  *semver.Version
+	config.Settings
 }
 
-func setup() (client *github.Client, args Args, err error) {
-	input := os.Args[1:]
+// ComponentVersion is an entry of the components output, it describes a component with a proposed version.
+type ComponentVersion struct {
+	Name         string `json:"name"`
+	Path         string `json:"path"`
+	Version      string `json:"version"`
+	Tag          string `json:"tag"`
+	ReleaseNotes string `json:"release_notes"`
+}
 
-	if len(input) < 9 {
-		panic("Usage: program token owner name head base")
+func setup(input []string) (client *github.Client, args Args, err error) {
+	flags := cli.NewFlagSet("version", "Computes the next version of the head branch and opens or updates the release "+
+		"pull request into the base branch.\nAdditional files to include in the release commit may be passed as "+
+		"arguments after the flags.")
+	var inputs config.Inputs
+	var commitFiles, releaseAs string
+	flags.TokenVar(&args.Token)
+	flags.StringVar(&args.Owner, "owner", "", "owner of the repository", cli.Env("GITHUB_REPOSITORY_OWNER"))
+	flags.StringVar(&args.Name, "name", "", "name of the repository", cli.RepositoryName)
+	flags.StringVar(&args.Head, "head", "", "the branch to version", cli.Env("GITHUB_REF_NAME"))
+	flags.StringVar(&args.Base, "base", "", "the base branch to open the pull request against", cli.Input("base"))
+	flags.StringVar(&inputs.Prerelease, "prerelease", "", "the prerelease identifier, overrides the configuration file (default \"rc\")", cli.Input("prerelease"))
+	flags.StringVar(&inputs.ReleaseBranch, "release-branch", "", "the primary release branch if it is not the default repository branch", cli.Input("release_branch"))
+	flags.StringVar(&args.Trigger, "trigger", "", "the action trigger, e.g. promote or sync", cli.Input("trigger"))
+	flags.StringVar(&commitFiles, "commit-files", "", "space separated paths of additional files to include in the release commit", cli.Input("commitFiles"))
+	flags.StringVar(&inputs.Backend, "backend", "", "how the repository is read, api or local (default \"api\")", cli.Input("backend"))
+	flags.StringVar(&inputs.PreMajor, "pre-major", "", "below 1.0.0 increment the minor version for breaking changes, true or false, overrides the configuration file", cli.Input("pre_major"))
+	flags.BoolVar(&args.Graduate, "graduate", "propose 1.0.0 if the latest version is below 1.0.0", cli.Input("graduate"))
+	flags.StringVar(&releaseAs, "release-as", "", "propose the version, e.g. 3.0.0, instead of the version computed from the commits", cli.Input("release_as"))
+	flags.BoolVar(&args.DryRun, "dry-run", "compute the release and print the plan of its writes without making them", cli.Input("dry_run"))
+	flags.StringVar(&args.PlanFormat, "plan-format", "text", "format of the dry run plan, text or json", cli.Input("plan_format"))
+	flags.Require("owner", "name", "head", "base")
+	if err = flags.Parse(input); err != nil {
+		return nil, args, err
 	}
-
-	args = Args{
-		Action:               input[0],
-		Token:                input[1],
-		Owner:                input[2],
-		Name:                 input[3],
-		Head:                 input[4],
-		Base:                 input[5],
-		PrereleaseIdentifier: input[6],
-		ReleaseBranch:        input[7],
-		Trigger:              input[8],
-		CommitFiles:          input[9:],
+	if !slices.Contains(composite.PlanFormats, args.PlanFormat) {
+		return nil, args, cli.UsageError{Err: fmt.Errorf("unknown plan format %q, expected one of %s", args.PlanFormat, strings.Join(composite.PlanFormats, ", "))}
 	}
-
-	client = NewClient(context.Background(), args.Token, args.Owner, args.Name)
-
-	if args.ReleaseBranch == "." { // . is the default value for the release branch
-		var branch *github.Branch
-		branch, err = client.Repository().DefaultBranch()
-		if err != nil {
-			return nil, args, fmt.Errorf("failed to get default branch: %w", err)
+	inputs.CommitFiles = append(strings.Fields(commitFiles), flags.Args()...)
+	if releaseAs != "" {
+		if args.ReleaseAs, err = conventional.ParseReleaseAs(releaseAs); err != nil {
+			return nil, args, cli.UsageError{Err: fmt.Errorf("invalid value for release-as: %w", err)}
 		}
-		args.ReleaseBranch = branch.Name
 	}
 
+	if args.Settings, err = config.Resolve(inputs); err != nil {
+		return nil, args, err
+	}
+	if args.ReleaseAs != nil && len(args.Components) > 0 {
+		return nil, args, cli.UsageError{Err: errors.New("release-as can not be used with components, add a Release-As footer to a commit of the component instead")}
+	}
+
+	client, err = local.Select(NewClient(context.Background(), args.Token, args.Owner, args.Name), args.Backend)
+	if err != nil {
+		return nil, args, err
+	}
+	if err = args.ResolveReleaseBranch(client); err != nil {
+		return nil, args, err
+	}
 	return
 }
 
-func version() {
-	client, args, err := setup()
+func version(input []string) error {
+	client, args, err := setup(input)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	defer args.Config.Apply()()
 
 	h := &composite.Handler{
 		Client:               client,
@@ -76,19 +114,80 @@ func version() {
 		ReleaseBranch:        args.ReleaseBranch,
 		Trigger:              args.Trigger,
 		CommitFiles:          args.CommitFiles,
+		PreMajor:             args.PreMajor,
+		Graduate:             args.Graduate,
+		ReleaseAs:            args.ReleaseAs,
+		ReleaseBranchPrefix:  args.ReleaseBranchPrefix,
+		DryRun:               args.DryRun,
+	}
+	if len(args.Components) > 0 {
+		return versionComponents(h, args)
 	}
+
 	err = h.PullRequest()
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if args.DryRun {
+		if err = composite.WritePlans(os.Stdout, args.PlanFormat, h.Plan); err != nil {
+			return fmt.Errorf("failed to write the plan: %w", err)
+		}
 	}
 
-	tools.OpenOutput(func(out tools.Output) {
+	return tools.OpenOutput(func(out *tools.Output) {
 		log.Debug().Msgf("Setting version to v%s", h.NextVersion().String())
-		out.Set("version", github.String("v"+h.NextVersion().String()))
+		out.SetString("version", "v"+h.NextVersion().String())
+		if h.ReleaseNotes != nil {
+			out.SetString("release_notes", h.ReleaseNotes.String())
+		}
 	})
 }
 
-func Execute() {
+// versionComponents versions each component independently. The version output is a JSON object of the next version
+// keyed by component name, and the components output a JSON array of the components with a proposed version.
+func versionComponents(handler *composite.Handler, args Args) error {
+	versions := make(map[string]string)
+	proposed := make([]ComponentVersion, 0)
+	var plans []*composite.Plan
+	for _, component := range args.Components {
+		log.Info().Msgf("Versioning component %s", component.Name)
+		h := handler.ForComponent(component)
+		if err := h.PullRequest(); err != nil {
+			return fmt.Errorf("failed to version component %s: %w", component.Name, err)
+		}
+
+		plans = append(plans, h.Plan)
+		version := h.NextVersion()
+		versions[component.Name] = "v" + version.String()
+		if h.Proposed() {
+			proposed = append(proposed, ComponentVersion{
+				Name:         component.Name,
+				Path:         component.Path,
+				Version:      "v" + version.String(),
+				Tag:          h.Tag(version),
+				ReleaseNotes: h.ReleaseNotes.String(),
+			})
+		}
+	}
+
+	if args.DryRun {
+		if err := composite.WritePlans(os.Stdout, args.PlanFormat, plans...); err != nil {
+			return fmt.Errorf("failed to write the plan: %w", err)
+		}
+	}
+
+	return tools.OpenOutput(func(out *tools.Output) {
+		out.SetJSON("version", versions)
+		out.SetJSON("components", proposed)
+	})
+}
+
+// Execute runs the version action with the command line arguments that follow the subcommand.
+func Execute(input []string) error {
 	log.Logger = logger.Base()
-	version()
+	err := version(input)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
 }
@@ -2,18 +2,35 @@ package version
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/config"
 	"github.com/jakbytes/version_actions/internal/mocks"
+	"github.com/jakbytes/version_actions/tools/changelog"
 	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/jakbytes/version_actions/tools/github/composite"
+	"github.com/jakbytes/version_actions/tools/github/local"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
+func TestMain(m *testing.M) {
+	// the token is read from the environment, it is not accepted as a flag
+	_ = os.Setenv("INPUT_TOKEN", "token")
+	os.Exit(m.Run())
+}
+
 func TestSetup(t *testing.T) {
-	os.Args = []string{"program", "version", "token", "owner", "name", "head", "base", "prereleaseIdentifier", "releaseBranch", "none"}
-	client, args, err := setup()
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "prereleaseIdentifier", "--release-branch", "releaseBranch", "--trigger", "none"}
+	client, args, err := setup(input)
 	require.Nil(t, err)
 
 	require.Equal(t, "token", args.Token)
@@ -26,11 +43,60 @@ func TestSetup(t *testing.T) {
 	require.NotNil(t, client)
 }
 
-func TestSetup_Panic(t *testing.T) {
-	os.Args = []string{"program"}
-	require.Panics(t, func() {
-		_, _, _ = setup()
-	})
+func TestSetup_MissingInput(t *testing.T) {
+	t.Setenv("INPUT_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	_, _, err := setup([]string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base"})
+	require.Equal(t, cli.MissingInputError{Name: "token", Sources: []string{"--token-file", "--token-fd", "INPUT_TOKEN", "GITHUB_TOKEN"}}, err)
+	require.Equal(t, "missing required input token, set --token-file or --token-fd or INPUT_TOKEN or GITHUB_TOKEN", err.Error())
+}
+
+func TestSetup_Environment(t *testing.T) {
+	t.Setenv("INPUT_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "token")
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "owner")
+	t.Setenv("GITHUB_REPOSITORY", "owner/name")
+	t.Setenv("GITHUB_REF_NAME", "head")
+	t.Setenv("INPUT_BASE", "base")
+	t.Setenv("INPUT_COMMITFILES", "package.json version.txt")
+
+	_, args, err := setup([]string{"--head", "feature", "--release-branch", "main"})
+	require.Nil(t, err)
+	require.Equal(t, Args{
+		Token:      "token",
+		Owner:      "owner",
+		Name:       "name",
+		Head:       "feature",
+		Base:       "base",
+		PlanFormat: "text",
+		Settings: config.Settings{
+			PrereleaseIdentifier: "rc",
+			ReleaseBranch:        "main",
+			CommitFiles:          []string{"package.json", "version.txt"},
+			ReleaseBranchPrefix:  "release--branch--",
+			TitleMaxLength:       70,
+			Config:               &config.Config{},
+		},
+	}, args)
+}
+
+func TestSetup_Backend(t *testing.T) {
+	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{Repositories: &mocks.RepositoryService{}}
+	}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--release-branch", "main"}
+
+	client, _, err := setup(append(input, "--backend", "local"))
+	require.Nil(t, err)
+	require.IsType(t, &local.Repositories{}, client.Repositories)
+
+	_, _, err = setup(append(input, "--backend", "svn"))
+	require.EqualError(t, err, `unknown backend "svn", expected one of api, local`)
+}
+
+func TestSetup_Help(t *testing.T) {
+	_, _, err := setup([]string{"--help"})
+	require.ErrorIs(t, err, flag.ErrHelp)
 }
 
 func TestSetup_DefaultReleaseBranch(t *testing.T) {
@@ -40,8 +106,8 @@ func TestSetup_DefaultReleaseBranch(t *testing.T) {
 		}
 	}
 
-	os.Args = []string{"program", "version", "token", "owner", "name", "head", "base", "prereleaseIdentifier", ".", "none"}
-	client, args, err := setup()
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "prereleaseIdentifier", "--release-branch", ".", "--trigger", "none"}
+	client, args, err := setup(input)
 	require.Nil(t, err)
 
 	require.Equal(t, "token", args.Token)
@@ -63,16 +129,418 @@ func TestSetup_DefaultReleaseBranch_Error(t *testing.T) {
 		}
 	}
 
-	os.Args = []string{"program", "version", "token", "owner", "name", "head", "base", "prereleaseIdentifier", ".", "none"}
-	_, _, err := setup()
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "prereleaseIdentifier", "--release-branch", ".", "--trigger", "none"}
+	_, _, err := setup(input)
 	require.NotNil(t, err)
 	require.Equal(t, fmt.Errorf("failed to get default branch: %w", assert.AnError), err)
 }
 
+func TestSetup_Config(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".version_actions.yml")
+	require.Nil(t, os.WriteFile(path, []byte("version: 1\nprerelease: beta\nrelease_branch: trunk\ncommit_files: [package.json]\n"), 0644))
+	original := config.Paths
+	config.Paths = []string{path}
+	defer func() { config.Paths = original }()
+
+	// inputs that are not provided fall back to the configuration file
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--release-branch", ".", "--trigger", "none"}
+	_, args, err := setup(input)
+	require.Nil(t, err)
+	require.Equal(t, "beta", args.PrereleaseIdentifier)
+	require.Equal(t, "trunk", args.ReleaseBranch)
+	require.Equal(t, []string{"package.json"}, args.CommitFiles)
+
+	// inputs that are provided take precedence over the configuration file
+	input = []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "rc", "--release-branch", "main", "--trigger", "none", "version.txt"}
+	_, args, err = setup(input)
+	require.Nil(t, err)
+	require.Equal(t, "rc", args.PrereleaseIdentifier)
+	require.Equal(t, "main", args.ReleaseBranch)
+	require.Equal(t, []string{"version.txt"}, args.CommitFiles)
+}
+
+func TestSetup_InvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".version_actions.yml")
+	require.Nil(t, os.WriteFile(path, []byte("version: 1\nprerelase: beta\n"), 0644))
+	original := config.Paths
+	config.Paths = []string{path}
+	defer func() { config.Paths = original }()
+
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "rc", "--release-branch", "main", "--trigger", "none"}
+	_, _, err := setup(input)
+	require.ErrorAs(t, err, &config.Error{})
+}
+
+func commit(sha string, message string, day int) *github.RepositoryCommit {
+	return &github.RepositoryCommit{
+		SHA: github.String(sha),
+		Commit: &github.Commit{
+			Message:   github.String(message),
+			Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}},
+			Tree:      &github.Tree{SHA: github.String("tree-" + sha)},
+		},
+	}
+}
+
+func newClient(repositories *mocks.RepositoryService, git *mocks.GitService, prs *mocks.PullRequestsService) func(ctx context.Context, token string, owner string, name string) *github.Client {
+	return func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories:       repositories,
+			Git:                git,
+			PullRequests:       prs,
+			RepositoryMetadata: github.RepositoryMetadata{Owner: owner, Name: name},
+		}
+	}
+}
+
+func TestVersion_Components(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".version_actions.yml")
+	require.Nil(t, os.WriteFile(path, []byte(fmt.Sprintf(`version: 1
+components:
+  - name: api
+    path: services/api
+    changelog: %s
+  - name: web
+    path: web
+    changelog: %s
+  - name: docs
+    path: docs
+    changelog: %s
+`, filepath.Join(dir, "API.md"), filepath.Join(dir, "WEB.md"), filepath.Join(dir, "DOCS.md"))), 0644))
+	original := config.Paths
+	config.Paths = []string{path}
+	defer func() { config.Paths = original }()
+	output := filepath.Join(dir, "output")
+	t.Setenv("GITHUB_OUTPUT", output)
+	defer os.Remove("release-api.txt")
+	defer os.Remove("release-web.txt")
+
+	repositories := &mocks.RepositoryService{
+		Commits: []*github.RepositoryCommit{
+			commit("sha4-sha4", "fix: web bug", 4),
+			commit("sha3-sha3", "feat: api feature", 3),
+			commit("sha2-sha2", "fix: docs typo", 2),
+			commit("sha1-sha1", "chore: init", 1),
+		},
+		Tags: []*github.RepositoryTag{
+			{Name: github.String("api/v1.0.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}},
+			{Name: github.String("docs/v0.1.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}},
+			{Name: github.String("v9.0.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}},
+		},
+		Files: map[string][]string{
+			"sha4-sha4": {"web/index.html"},
+			"sha3-sha3": {"services/api/main.go", "README.md"},
+			"sha2-sha2": {"README.md"},
+			"sha1-sha1": {"services/api/go.mod", "web/package.json"},
+		},
+	}
+	var refs []*github.Reference
+	prs := &mocks.PullRequestsService{PullRequests: []*github.PullRequest{}, FilterHead: true}
+	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories:       repositories,
+			Git:                &mocks.GitService{Refs: &refs},
+			PullRequests:       prs,
+			RepositoryMetadata: github.RepositoryMetadata{Owner: owner, Name: name},
+		}
+	}
+
+	err := version([]string{"--owner", "owner", "--name", "name", "--head", "main", "--base", "main", "--release-branch", "main"})
+	require.Nil(t, err)
+
+	// docs has no changes since docs/v0.1.0, api and web get their own release pull request
+	require.Len(t, prs.PullRequests, 2)
+	assert.Equal(t, "release(main): api/v1.1.0", prs.PullRequests[0].GetTitle())
+	assert.Equal(t, "release--branch--api--main", prs.PullRequests[0].GetHead().GetRef())
+	assert.Equal(t, "release(main): web/v0.0.0", prs.PullRequests[1].GetTitle())
+	assert.Equal(t, "release--branch--web--main", prs.PullRequests[1].GetHead().GetRef())
+
+	api, err := os.ReadFile(filepath.Join(dir, "API.md"))
+	require.Nil(t, err)
+	assert.Contains(t, string(api), "## [api/v1.1.0](https://github.com/owner/name/compare/api/v1.0.0...api/v1.1.0)")
+	assert.Contains(t, string(api), "api feature")
+	assert.NotContains(t, string(api), "web bug")
+	assert.NoFileExists(t, filepath.Join(dir, "DOCS.md"))
+
+	outputs, err := os.ReadFile(output)
+	require.Nil(t, err)
+	lines := strings.SplitN(string(outputs), "\n", 2)
+	assert.Equal(t, `version={"api":"v1.1.0","docs":"v0.1.0","web":"v0.0.0"}`, lines[0])
+	var components []ComponentVersion
+	require.Nil(t, json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(lines[1], "components="))), &components))
+	require.Len(t, components, 2)
+	assert.Equal(t, ComponentVersion{Name: "api", Path: "services/api", Version: "v1.1.0", Tag: "api/v1.1.0", ReleaseNotes: components[0].ReleaseNotes}, components[0])
+	assert.Contains(t, components[0].ReleaseNotes, "api feature")
+	assert.Equal(t, ComponentVersion{Name: "web", Path: "web", Version: "v0.0.0", Tag: "web/v0.0.0", ReleaseNotes: components[1].ReleaseNotes}, components[1])
+	assert.Contains(t, components[1].ReleaseNotes, "web bug")
+}
+
+func TestVersion_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".version_actions.yml")
+	changelogPath := filepath.Join(dir, "CHANGELOG.md")
+	require.Nil(t, os.WriteFile(path, []byte(fmt.Sprintf("version: 1\nchangelog:\n  path: %s\n", changelogPath)), 0644))
+	original := config.Paths
+	config.Paths = []string{path}
+	defer func() { config.Paths = original }()
+	defer func() { changelog.Path = "CHANGELOG.md" }()
+
+	repositories := &mocks.RepositoryService{
+		Commits: []*github.RepositoryCommit{
+			commit("sha3-sha3", "feat: api feature", 3),
+			commit("sha2-sha2", "fix: typo\n\nin the docs", 2),
+			commit("sha1-sha1", "chore: init", 1),
+		},
+		Tags: []*github.RepositoryTag{
+			{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}},
+		},
+	}
+	// any write fails the test
+	writeErr := errors.New("unexpected write")
+	prs := &mocks.PullRequestsService{PullRequests: []*github.PullRequest{}}
+	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories:       repositories,
+			Git:                &mocks.GitService{CreateRefError: writeErr, UpdateRefError: writeErr},
+			PullRequests:       prs,
+			RepositoryMetadata: github.RepositoryMetadata{Owner: owner, Name: name},
+		}
+	}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	os.Stdout = w
+	err = version([]string{"--owner", "owner", "--name", "name", "--head", "main", "--base", "main", "--release-branch", "main", "--dry-run", "--plan-format", "json"})
+	os.Stdout = stdout
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+
+	var plan composite.Plan
+	require.Nil(t, json.NewDecoder(r).Decode(&plan))
+	assert.Empty(t, prs.PullRequests)
+	assert.NoFileExists(t, changelogPath)
+	assert.NoFileExists(t, "release.txt")
+
+	assert.Equal(t, "v1.0.0", plan.LatestVersion)
+	assert.Equal(t, "v1.1.0", plan.NextVersion)
+	assert.True(t, plan.Proposed)
+	assert.Equal(t, []composite.PlanSection{
+		{Type: "feat", Commits: []string{"sha3-sh feat: api feature"}},
+		{Type: "fix", Commits: []string{"sha2-sh fix: typo"}},
+	}, plan.Commits)
+	assert.Equal(t, &composite.PlanBranch{Name: "release--branch--main", From: "main", SHA: "hash"}, plan.Branch)
+	require.NotNil(t, plan.Commit)
+	assert.Equal(t, "release(main): v1.1.0", plan.Commit.Message)
+	require.Len(t, plan.Commit.Files, 1)
+	assert.Equal(t, changelogPath, plan.Commit.Files[0].Path)
+	assert.Contains(t, plan.Commit.Files[0].Content, "api feature")
+	require.NotNil(t, plan.PullRequest)
+	assert.Equal(t, "release--branch--main", plan.PullRequest.Head)
+	assert.Equal(t, "main", plan.PullRequest.Base)
+	assert.Equal(t, "release(main): v1.1.0", plan.PullRequest.Title)
+	assert.Contains(t, plan.PullRequest.Body, "api feature")
+	require.Len(t, plan.Files, 2)
+	assert.Equal(t, changelogPath, plan.Files[0].Path)
+	assert.Equal(t, "release.txt", plan.Files[1].Path)
+}
+
+func TestVersion_DryRun_JSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".version_actions.yml")
+	changelogPath := filepath.Join(dir, "CHANGELOG.md")
+	require.Nil(t, os.WriteFile(path, []byte(fmt.Sprintf("version: 1\nchangelog:\n  path: %s\n  json: true\n", changelogPath)), 0644))
+	original := config.Paths
+	config.Paths = []string{path}
+	defer func() { config.Paths = original }()
+	defer func() { changelog.Path, changelog.JSON = "CHANGELOG.md", false }()
+
+	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories: &mocks.RepositoryService{
+				Commits: []*github.RepositoryCommit{commit("sha2-sha2", "feat: api feature", 2), commit("sha1-sha1", "chore: init", 1)},
+				Tags:    []*github.RepositoryTag{{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}}},
+			},
+			Git:                &mocks.GitService{},
+			PullRequests:       &mocks.PullRequestsService{PullRequests: []*github.PullRequest{}},
+			RepositoryMetadata: github.RepositoryMetadata{Owner: owner, Name: name},
+		}
+	}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	os.Stdout = w
+	err = version([]string{"--owner", "owner", "--name", "name", "--head", "main", "--base", "main", "--release-branch", "main", "--dry-run", "--plan-format", "json"})
+	os.Stdout = stdout
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+
+	var plan composite.Plan
+	require.Nil(t, json.NewDecoder(r).Decode(&plan))
+	releasesPath := filepath.Join(dir, changelog.ReleasesFile)
+	assert.NoFileExists(t, releasesPath)
+	require.NotNil(t, plan.Commit)
+	require.Len(t, plan.Commit.Files, 2)
+	assert.Equal(t, releasesPath, plan.Commit.Files[1].Path)
+
+	var releases changelog.JSONReleases
+	require.Nil(t, json.Unmarshal([]byte(plan.Commit.Files[1].Content), &releases))
+	require.Len(t, releases.Releases, 1)
+	assert.Equal(t, "v1.1.0", releases.Releases[0].Tag)
+	assert.Equal(t, "1.0.0", releases.Releases[0].PreviousVersion)
+	assert.Equal(t, "minor", releases.Releases[0].Increment)
+	assert.Equal(t, "api feature", releases.Releases[0].Commits["feat"][0].Description)
+
+	require.Len(t, plan.Files, 4)
+	assert.Equal(t, []string{changelogPath, releasesPath, "release.json", "release.txt"},
+		[]string{plan.Files[0].Path, plan.Files[1].Path, plan.Files[2].Path, plan.Files[3].Path})
+	var release changelog.JSONRelease
+	require.Nil(t, json.Unmarshal([]byte(plan.Files[2].Content), &release))
+	assert.Equal(t, releases.Releases[0], release)
+}
+
+func TestVersion_DryRun_Promotion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".version_actions.yml")
+	changelogPath := filepath.Join(dir, "CHANGELOG.md")
+	require.Nil(t, os.WriteFile(path, []byte(fmt.Sprintf("version: 1\nchangelog:\n  path: %s\n", changelogPath)), 0644))
+	require.Nil(t, os.WriteFile(changelogPath, []byte("# Changelog\n\n"+
+		"## [v1.1.0-rc.1](https://github.com/owner/name/compare/v1.0.0...v1.1.0-rc.1) (2024-02-02)\n- fix: typo\n\n"+
+		"## [v1.1.0-rc.0](https://github.com/owner/name/compare/v1.0.0...v1.1.0-rc.0) (2024-02-01)\n- feat: api feature\n\n"+
+		"## [v1.0.0] (2024-01-01)\n- chore: init\n"), 0644))
+	original := config.Paths
+	config.Paths = []string{path}
+	defer func() { config.Paths = original }()
+	defer func() { changelog.Path = "CHANGELOG.md" }()
+
+	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
+		return &github.Client{
+			Repositories: &mocks.RepositoryService{
+				Commits: []*github.RepositoryCommit{
+					commit("sha3-sha3", "feat: api feature", 3),
+					commit("sha2-sha2", "fix: typo", 2),
+					commit("sha1-sha1", "chore: init", 1),
+				},
+				Tags: []*github.RepositoryTag{
+					{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}},
+					{Name: github.String("v1.1.0-rc.0"), Commit: &github.Commit{SHA: github.String("sha2-sha2")}},
+					{Name: github.String("v1.1.0-rc.1"), Commit: &github.Commit{SHA: github.String("sha3-sha3")}},
+				},
+			},
+			Git:                &mocks.GitService{},
+			PullRequests:       &mocks.PullRequestsService{PullRequests: []*github.PullRequest{}},
+			RepositoryMetadata: github.RepositoryMetadata{Owner: owner, Name: name},
+		}
+	}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	os.Stdout = w
+	err = version([]string{"--owner", "owner", "--name", "name", "--head", "develop", "--base", "main", "--release-branch", "main", "--prerelease", "rc", "--dry-run", "--plan-format", "json"})
+	os.Stdout = stdout
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+
+	// the promotion is one release of every change since v1.0.0, it replaces the changelogs of the release candidates
+	var plan composite.Plan
+	require.Nil(t, json.NewDecoder(r).Decode(&plan))
+	assert.Equal(t, "v1.1.0", plan.NextVersion)
+	assert.Equal(t, []composite.PlanSection{
+		{Type: "feat", Commits: []string{"sha3-sh feat: api feature"}},
+		{Type: "fix", Commits: []string{"sha2-sh fix: typo"}},
+	}, plan.Commits)
+	require.NotNil(t, plan.Commit)
+	content := plan.Commit.Files[0].Content
+	assert.Contains(t, content, "## [v1.1.0](https://github.com/owner/name/compare/v1.0.0...v1.1.0)")
+	assert.NotContains(t, content, "rc.")
+	assert.Contains(t, content, "## [v1.0.0] (2024-01-01)")
+}
+
+func TestVersion_PlanFormat(t *testing.T) {
+	_, _, err := setup([]string{"--owner", "owner", "--name", "name", "--head", "main", "--base", "main", "--plan-format", "yaml"})
+	require.ErrorAs(t, err, &cli.UsageError{})
+	require.EqualError(t, err, `unknown plan format "yaml", expected one of text, json`)
+}
+
+func TestVersion_ReleaseAs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		commit  string
+		input   []string
+		want    string
+		message string
+		note    string
+	}{
+		{"input", "feat: api feature", []string{"--release-as", "v3.0.0"}, "v3.0.0", "release(main): v3.0.0\n\nRelease-As: 3.0.0",
+			"> The version was set by the release-as input."},
+		{"footer", "feat: api feature\n\nRelease-As: 2.0.0", nil, "v2.0.0", "release(main): v2.0.0",
+			"> The version was set by the Release-As footer of commit sha3-sh."},
+		{"input over footer", "feat: api feature\n\nRelease-As: 2.0.0", []string{"--release-as", "4.0.0"}, "v4.0.0", "release(main): v4.0.0\n\nRelease-As: 4.0.0",
+			"> The version was set by the release-as input."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := releaseAsPlan(t, tc.commit, tc.input...)
+			assert.Equal(t, tc.want, plan.NextVersion)
+			assert.True(t, plan.Proposed)
+			require.NotNil(t, plan.Commit)
+			assert.Equal(t, tc.message, plan.Commit.Message)
+			require.NotNil(t, plan.PullRequest)
+			assert.Equal(t, "release(main): "+tc.want, plan.PullRequest.Title)
+			assert.Contains(t, plan.PullRequest.Body, tc.note)
+			assert.Contains(t, plan.Commit.Files[0].Content, tc.note)
+		})
+	}
+}
+
+func TestVersion_ReleaseAs_NotGreater(t *testing.T) {
+	repositories := &mocks.RepositoryService{
+		Commits: []*github.RepositoryCommit{commit("sha2-sha2", "fix: typo\n\nRelease-As: 1.0.0", 2), commit("sha1-sha1", "chore: init", 1)},
+		Tags:    []*github.RepositoryTag{{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}}},
+	}
+	NewClient = newClient(repositories, &mocks.GitService{}, &mocks.PullRequestsService{})
+
+	err := version([]string{"--owner", "owner", "--name", "name", "--head", "main", "--base", "main", "--release-branch", "main", "--dry-run"})
+	require.ErrorAs(t, err, &composite.ReleaseAsError{})
+	require.EqualError(t, err, "v1.0.0 set by the Release-As footer of commit sha2-sh is not greater than the latest version v1.0.0")
+}
+
+func TestSetup_ReleaseAs(t *testing.T) {
+	for _, value := range []string{"three", "3.0.0-rc.1"} {
+		_, _, err := setup([]string{"--owner", "owner", "--name", "name", "--head", "main", "--base", "main", "--release-as", value})
+		require.ErrorAs(t, err, &cli.UsageError{}, value)
+	}
+}
+
+// releaseAsPlan runs the version command in dry run mode for a repository with the commit on top of v1.0.0 and returns
+// the plan.
+func releaseAsPlan(t *testing.T, message string, input ...string) (plan composite.Plan) {
+	repositories := &mocks.RepositoryService{
+		Commits: []*github.RepositoryCommit{commit("sha3-sha3", message, 3), commit("sha1-sha1", "chore: init", 1)},
+		Tags:    []*github.RepositoryTag{{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("sha1-sha1")}}},
+	}
+	NewClient = newClient(repositories, &mocks.GitService{}, &mocks.PullRequestsService{PullRequests: []*github.PullRequest{}})
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	os.Stdout = w
+	err = version(append([]string{"--owner", "owner", "--name", "name", "--head", "main", "--base", "main", "--release-branch", "main", "--dry-run", "--plan-format", "json"}, input...))
+	os.Stdout = stdout
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+	require.Nil(t, json.NewDecoder(r).Decode(&plan))
+	return plan
+}
+
 /*
 func TestSetReleaseBranch_Create(t *testing.T) {
 	count := 0
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base", "rc", "main"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "rc", "--release-branch", "main"}
 	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
 		return &github.Client{
 			Git: &mocks.GitService{},
@@ -87,7 +555,7 @@ func TestSetReleaseBranch_Create(t *testing.T) {
 			},
 		}
 	}
-	client, args, err := setup()
+	client, args, err := setup(input)
 	require.Nil(t, err)
 
 	head, err := client.Repository().Branch(args.Head)
@@ -100,14 +568,14 @@ func TestSetReleaseBranch_Create(t *testing.T) {
 }
 
 func TestSetReleaseBranch_Reset(t *testing.T) {
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base", "rc", "main"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "rc", "--release-branch", "main"}
 	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
 		return &github.Client{
 			Git:          &mocks.GitService{},
 			Repositories: &mocks.RepositoryService{},
 		}
 	}
-	client, args, err := setup()
+	client, args, err := setup(input)
 	require.Nil(t, err)
 
 	head, err := client.Repository().Branch(args.Head)
@@ -120,7 +588,7 @@ func TestSetReleaseBranch_Reset(t *testing.T) {
 }
 
 func TestSetReleaseBranch_Error(t *testing.T) {
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base", "rc", "main"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "rc", "--release-branch", "main"}
 	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
 		return &github.Client{
 			Git: &mocks.GitService{},
@@ -134,7 +602,7 @@ func TestSetReleaseBranch_Error(t *testing.T) {
 			},
 		}
 	}
-	client, args, err := setup()
+	client, args, err := setup(input)
 	require.Nil(t, err)
 
 	head, err := client.Repository().Branch(args.Head)
@@ -146,7 +614,7 @@ func TestSetReleaseBranch_Error(t *testing.T) {
 }
 
 func TestWriteChangelog(t *testing.T) {
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base", "rc", "head"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "rc", "--release-branch", "head"}
 	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
 		return &github.Client{
 			Repositories: &mocks.RepositoryService{},
@@ -174,7 +642,7 @@ func TestWriteChangelog(t *testing.T) {
 	err := changelog.WriteToFile(changelog.Path, exampleExisting)
 	require.Nil(t, err)
 
-	client, args, err := setup()
+	client, args, err := setup(input)
 	require.Nil(t, err)
 
 	head, err := client.Repository().Branch(args.Head)
@@ -242,7 +710,7 @@ func TestWriteChangelog(t *testing.T) {
 }
 
 func TestWriteChangelog_NoIncrement(t *testing.T) {
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "head", "rc", "head"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "head", "--prerelease", "rc", "--release-branch", "head"}
 	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
 		return &github.Client{
 			Repositories: &mocks.RepositoryService{
@@ -344,7 +812,7 @@ func TestWriteChangelog_NoIncrement(t *testing.T) {
 	err := changelog.WriteToFile(changelog.Path, exampleExisting)
 	require.Nil(t, err)
 
-	client, args, err := setup()
+	client, args, err := setup(input)
 	require.Nil(t, err)
 
 	head, err := client.Repository().Branch(args.Head)
@@ -407,7 +875,7 @@ func TestWriteChangelog_NoIncrement(t *testing.T) {
 }
 
 func TestWriteChangelog_NoIncrement_RC(t *testing.T) {
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base", "rc", "base"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "rc", "--release-branch", "base"}
 	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
 		return &github.Client{
 			Repositories: &mocks.RepositoryService{
@@ -568,7 +1036,7 @@ func TestWriteChangelog_NoIncrement_RC(t *testing.T) {
 	err := changelog.WriteToFile(changelog.Path, exampleExisting)
 	require.Nil(t, err)
 
-	client, args, err := setup()
+	client, args, err := setup(input)
 	require.Nil(t, err)
 
 	head, err := client.Repository().Branch(args.Head)
@@ -640,7 +1108,7 @@ func TestWriteChangelog_NoIncrement_RC(t *testing.T) {
 }
 
 func TestComposePullRequest(t *testing.T) {
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base", "rc", "head"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "rc", "--release-branch", "head"}
 	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
 		return &github.Client{
 			Repositories: &mocks.RepositoryService{},
@@ -668,7 +1136,7 @@ func TestComposePullRequest(t *testing.T) {
 	err := changelog.WriteToFile(changelog.Path, exampleExisting)
 	require.Nil(t, err)
 
-	client, args, err := setup()
+	client, args, err := setup(input)
 	require.Nil(t, err)
 
 	head, err := client.Repository().Branch(args.Head)
@@ -770,7 +1238,7 @@ func TestVersion(t *testing.T) {
 
 	defer os.Remove(changelog.Path)
 
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base", "rc", "head"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "rc", "--release-branch", "head"}
 	var client *github.Client
 
 	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
@@ -786,7 +1254,7 @@ func TestVersion(t *testing.T) {
 		return client
 	}
 
-	err := version()
+	err := version(input)
 	require.Nil(t, err)
 
 	assert.Equal(t, 1, len(client.PullRequests.(*mocks.PullRequestsService).PullRequests))
@@ -797,7 +1265,7 @@ func TestVersion_NoTags(t *testing.T) {
 
 	defer os.Remove(changelog.Path)
 
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "base", "rc", "base"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "base", "--prerelease", "rc", "--release-branch", "base"}
 	var client *github.Client
 	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
 		client = &github.Client{
@@ -814,7 +1282,7 @@ func TestVersion_NoTags(t *testing.T) {
 		return client
 	}
 
-	err := version()
+	err := version(input)
 	require.Nil(t, err)
 
 	assert.Equal(t, 1, len(client.PullRequests.(*mocks.PullRequestsService).PullRequests))
@@ -828,7 +1296,7 @@ func TestVersion_ReleaseBranch(t *testing.T) {
 
 	defer os.Remove(changelog.Path)
 
-	os.Args = []string{"program", "action", "token", "owner", "name", "head", "head", "rc", "head"}
+	input := []string{"--owner", "owner", "--name", "name", "--head", "head", "--base", "head", "--prerelease", "rc", "--release-branch", "head"}
 	var client *github.Client
 	NewClient = func(ctx context.Context, token string, owner string, name string) *github.Client {
 		client = &github.Client{
@@ -843,7 +1311,7 @@ func TestVersion_ReleaseBranch(t *testing.T) {
 		return client
 	}
 
-	err := version()
+	err := version(input)
 	require.Nil(t, err)
 
 	assert.Equal(t, 1, len(client.PullRequests.(*mocks.PullRequestsService).PullRequests))
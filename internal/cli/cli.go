@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jakbytes/version_actions/internal/logger"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Source is a fallback for an input that is not provided as a flag, e.g. an environment variable set by GitHub Actions.
+type Source struct {
+	Name   string                // name of the source shown in usage and error messages
+	Lookup func() (string, bool) // returns the value of the source and whether it is set
+}
+
+// Env returns a Source that reads the environment variable with the given name.
+func Env(name string) Source {
+	return Source{Name: name, Lookup: func() (string, bool) {
+		value, ok := os.LookupEnv(name)
+		return value, ok && value != ""
+	}}
+}
+
+// Input returns a Source for the GitHub Actions input with the given name, Actions exposes inputs as INPUT_{NAME}
+// environment variables.
+func Input(name string) Source {
+	return Env("INPUT_" + strings.ToUpper(strings.ReplaceAll(name, " ", "_")))
+}
+
+// RepositoryName is a Source for the repository name in the GITHUB_REPOSITORY (owner/name) environment variable.
+var RepositoryName = Source{Name: "GITHUB_REPOSITORY", Lookup: func() (string, bool) {
+	_, name, ok := strings.Cut(os.Getenv("GITHUB_REPOSITORY"), "/")
+	return name, ok && name != ""
+}}
+
+// MissingInputError is returned when a required input is provided neither as a flag nor by any of its sources.
+type MissingInputError struct {
+	Name    string
+	Sources []string // flags and sources the input may be provided by
+}
+
+func (e MissingInputError) Error() string {
+	return fmt.Sprintf("missing required input %s, set %s", e.Name, strings.Join(e.Sources, " or "))
+}
+
+// UsageError is returned when the command line arguments cannot be parsed, e.g. for an unknown flag.
+type UsageError struct {
+	Err error
+}
+
+func (e UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e UsageError) Unwrap() error {
+	return e.Err
+}
+
+type input struct {
+	name     string
+	value    *string
+	set      func(value string) error // sets the input from the value of a source, nil for string inputs
+	sources  []Source
+	required bool
+}
+
+type token struct {
+	value *string
+	file  string
+	fd    int
+}
+
+// FlagSet is a set of named inputs for a subcommand. Inputs are read from flags first, then from their sources in
+// order, and finally fall back to their default value.
+type FlagSet struct {
+	*flag.FlagSet
+	inputs []*input
+	token  *token
+}
+
+// NewFlagSet returns a FlagSet for the subcommand with the given name, description is printed with --help.
+func NewFlagSet(name string, description string) *FlagSet {
+	s := &FlagSet{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError)}
+	s.Usage = func() {
+		out := s.Output()
+		_, _ = fmt.Fprintf(out, "Usage: version_action %s [flags]\n\n%s\n\nFlags:\n", name, description)
+		s.PrintDefaults()
+	}
+	return s
+}
+
+// StringVar defines a string input stored in p with the given name, default value and usage, falling back to the
+// sources when the flag is not provided.
+func (s *FlagSet) StringVar(p *string, name string, value string, usage string, sources ...Source) {
+	s.FlagSet.StringVar(p, name, value, withSources(usage, sources))
+	s.inputs = append(s.inputs, &input{name: name, value: p, sources: sources})
+}
+
+// BoolVar defines a bool input stored in p with the given name and usage, falling back to the sources when the flag is
+// not provided. Sources are parsed with strconv.ParseBool, e.g. "true" or "false".
+func (s *FlagSet) BoolVar(p *bool, name string, usage string, sources ...Source) {
+	s.FlagSet.BoolVar(p, name, false, withSources(usage, sources))
+	s.inputs = append(s.inputs, &input{name: name, value: new(string), sources: sources, set: func(value string) (err error) {
+		*p, err = strconv.ParseBool(value)
+		return
+	}})
+}
+
+// withSources appends the names of the sources to the usage.
+func withSources(usage string, sources []Source) string {
+	if len(sources) == 0 {
+		return usage
+	}
+	var names []string
+	for _, source := range sources {
+		names = append(names, source.Name)
+	}
+	return fmt.Sprintf("%s (env: %s)", usage, strings.Join(names, ", "))
+}
+
+// TokenVar defines the required GitHub token input stored in p. The token is never accepted as a flag value, which would
+// expose it in the process list, it is read from the file given by --token-file, the file descriptor given by
+// --token-fd, or the INPUT_TOKEN and GITHUB_TOKEN environment variables. The token is masked in the log output.
+func (s *FlagSet) TokenVar(p *string) {
+	s.token = &token{value: p}
+	s.FlagSet.StringVar(&s.token.file, "token-file", "", "read the GitHub token from the file (env: INPUT_TOKEN, GITHUB_TOKEN)")
+	s.FlagSet.IntVar(&s.token.fd, "token-fd", -1, "read the GitHub token from the open file descriptor")
+}
+
+func (t *token) read() (err error) {
+	var content []byte
+	switch {
+	case t.file != "":
+		content, err = os.ReadFile(t.file)
+	case t.fd >= 0:
+		file := os.NewFile(uintptr(t.fd), "token")
+		if file == nil {
+			return fmt.Errorf("invalid token file descriptor %d", t.fd)
+		}
+		defer file.Close()
+		content, err = io.ReadAll(file)
+	default:
+		for _, source := range []Source{Input("token"), Env("GITHUB_TOKEN")} {
+			if value, ok := source.Lookup(); ok {
+				content = []byte(value)
+				break
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read token: %w", err)
+	}
+
+	*t.value = strings.TrimSpace(string(content))
+	if *t.value == "" {
+		return MissingInputError{Name: "token", Sources: []string{"--token-file", "--token-fd", "INPUT_TOKEN", "GITHUB_TOKEN"}}
+	}
+	logger.Mask(*t.value)
+	return nil
+}
+
+// Require marks the inputs with the given names as required.
+func (s *FlagSet) Require(names ...string) {
+	for _, name := range names {
+		for _, in := range s.inputs {
+			if in.name == name {
+				in.required = true
+			}
+		}
+	}
+}
+
+// Parse parses the flags from args, reads the token, fills inputs that were not provided as flags from their sources,
+// and returns a MissingInputError for the first required input without a value. flag.ErrHelp is returned if --help
+// was requested, and a UsageError if the arguments cannot be parsed.
+func (s *FlagSet) Parse(args []string) error {
+	err := s.FlagSet.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	} else if err != nil {
+		return UsageError{Err: err}
+	}
+
+	if s.token != nil {
+		if err = s.token.read(); err != nil {
+			return err
+		}
+	}
+
+	provided := make(map[string]bool)
+	s.Visit(func(f *flag.Flag) {
+		provided[f.Name] = true
+	})
+
+	for _, in := range s.inputs {
+		if !provided[in.name] {
+			for _, source := range in.sources {
+				if value, ok := source.Lookup(); ok {
+					*in.value = value
+					if in.set != nil {
+						if err = in.set(value); err != nil {
+							return UsageError{Err: fmt.Errorf("invalid value %q for %s: %w", value, source.Name, err)}
+						}
+					}
+					break
+				}
+			}
+		}
+		if in.required && *in.value == "" {
+			sources := []string{"--" + in.name}
+			for _, source := range in.sources {
+				sources = append(sources, source.Name)
+			}
+			return MissingInputError{Name: in.name, Sources: sources}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"flag"
+	"github.com/jakbytes/version_actions/internal/logger"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func newFlagSet(owner *string, name *string) *FlagSet {
+	flags := NewFlagSet("test", "A test command.")
+	flags.SetOutput(io.Discard)
+	flags.StringVar(owner, "owner", "", "owner of the repository", Input("owner"), Env("GITHUB_REPOSITORY_OWNER"))
+	flags.StringVar(name, "name", "default", "name of the repository", RepositoryName)
+	return flags
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		input []string
+		owner string
+		repo  string
+	}{
+		{"flags", map[string]string{"INPUT_OWNER": "input", "GITHUB_REPOSITORY": "env/env"}, []string{"--owner", "flag", "--name", "flag"}, "flag", "flag"},
+		{"sources in order", map[string]string{"INPUT_OWNER": "input", "GITHUB_REPOSITORY_OWNER": "env"}, nil, "input", "default"},
+		{"empty sources are skipped", map[string]string{"INPUT_OWNER": "", "GITHUB_REPOSITORY_OWNER": "env", "GITHUB_REPOSITORY": "env/name"}, nil, "env", "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"INPUT_OWNER", "GITHUB_REPOSITORY_OWNER", "GITHUB_REPOSITORY"} {
+				t.Setenv(key, tt.env[key])
+			}
+			var owner, name string
+			require.Nil(t, newFlagSet(&owner, &name).Parse(tt.input))
+			require.Equal(t, tt.owner, owner)
+			require.Equal(t, tt.repo, name)
+		})
+	}
+}
+
+func TestParse_MissingInput(t *testing.T) {
+	t.Setenv("INPUT_OWNER", "")
+	t.Setenv("GITHUB_REPOSITORY_OWNER", "")
+
+	var owner, name string
+	flags := newFlagSet(&owner, &name)
+	flags.Require("owner", "name")
+	err := flags.Parse([]string{"--name", "name"})
+	require.Equal(t, MissingInputError{Name: "owner", Sources: []string{"--owner", "INPUT_OWNER", "GITHUB_REPOSITORY_OWNER"}}, err)
+	require.EqualError(t, err, "missing required input owner, set --owner or INPUT_OWNER or GITHUB_REPOSITORY_OWNER")
+}
+
+func TestParse_Help(t *testing.T) {
+	var owner, name string
+	require.ErrorIs(t, newFlagSet(&owner, &name).Parse([]string{"--help"}), flag.ErrHelp)
+}
+
+func TestParse_Token(t *testing.T) {
+	t.Setenv("INPUT_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "env-token")
+	t.Setenv("GITHUB_ACTIONS", "")
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.Nil(t, os.WriteFile(path, []byte("file-token\n"), 0600))
+
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	_, err = w.WriteString("fd-token")
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+
+	tests := []struct {
+		name     string
+		input    []string
+		expected string
+	}{
+		{"environment", nil, "env-token"},
+		{"file", []string{"--token-file", path}, "file-token"},
+		{"file descriptor", []string{"--token-fd", strconv.Itoa(int(r.Fd()))}, "fd-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var token string
+			flags := NewFlagSet("test", "A test command.")
+			flags.TokenVar(&token)
+			require.Nil(t, flags.Parse(tt.input))
+			require.Equal(t, tt.expected, token)
+			require.Equal(t, logger.Redacted, logger.Redact(tt.expected))
+		})
+	}
+}
+
+func TestParse_TokenFlag(t *testing.T) {
+	var token string
+	flags := NewFlagSet("test", "A test command.")
+	flags.SetOutput(io.Discard)
+	flags.TokenVar(&token)
+	err := flags.Parse([]string{"--token", "secret"})
+	require.ErrorAs(t, err, &UsageError{})
+	require.EqualError(t, err, "flag provided but not defined: -token")
+}
+
+func TestParse_Bool(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		input    []string
+		expected bool
+		err      string
+	}{
+		{"default", "", nil, false, ""},
+		{"flag", "false", []string{"--dry-run"}, true, ""},
+		{"flag value", "true", []string{"--dry-run=false"}, false, ""},
+		{"source", "true", nil, true, ""},
+		{"invalid source", "yes please", nil, false, `invalid value "yes please" for INPUT_DRY_RUN: strconv.ParseBool: parsing "yes please": invalid syntax`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("INPUT_DRY_RUN", tt.env)
+			var dryRun bool
+			flags := NewFlagSet("test", "A test command.")
+			flags.SetOutput(io.Discard)
+			flags.BoolVar(&dryRun, "dry-run", "print the plan", Input("dry_run"))
+			err := flags.Parse(tt.input)
+			if tt.err != "" {
+				require.ErrorAs(t, err, &UsageError{})
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, dryRun)
+		})
+	}
+}
@@ -0,0 +1,614 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jakbytes/version_actions/internal/utility"
+	"github.com/jakbytes/version_actions/tools/changelog"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/jakbytes/version_actions/tools/github/composite"
+	"github.com/jakbytes/version_actions/tools/github/local"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Version is the version of the configuration file format understood by this release of version_actions.
+const Version = 1
+
+// Paths are the locations, relative to the repository root, searched for the configuration file. The first file found
+// is used.
+var Paths = []string{".version_actions.yml", ".version_actions.yaml", ".version_actions.json"}
+
+// Config is the repository configuration read from the configuration file. Settings that are not present in the file
+// are left as their zero value and the defaults of the respective package are used.
+type Config struct {
+	Version       int          `yaml:"version" json:"version"`
+	Prerelease    string       `yaml:"prerelease" json:"prerelease"`
+	PreMajor      bool         `yaml:"pre_major" json:"pre_major"` // below 1.0.0 breaking changes bump the minor and features the patch version
+	ReleaseBranch string       `yaml:"release_branch" json:"release_branch"`
+	CommitFiles   []string     `yaml:"commit_files" json:"commit_files"`
+	Backend       string       `yaml:"backend" json:"backend"` // api or local, how the repository is read
+	Branch        Branch       `yaml:"branch" json:"branch"`
+	Changelog     Changelog    `yaml:"changelog" json:"changelog"`
+	PullRequest   PullRequest  `yaml:"pull_request" json:"pull_request"`
+	Components    []Component  `yaml:"components" json:"components"`
+	Types         []Type       `yaml:"types" json:"types"`
+	Dependencies  Dependencies `yaml:"dependencies" json:"dependencies"`
+	Templates     Templates    `yaml:"templates" json:"templates"`
+}
+
+// Templates contains the paths of the text/template files replacing the built-in templates, see changelog.Data for the
+// data the templates are executed with.
+type Templates struct {
+	Changelog          string `yaml:"changelog" json:"changelog"`                       // the changelog of a version
+	PullRequest        string `yaml:"pull_request" json:"pull_request"`                 // the body of pull requests opened by the pull_request action
+	ReleasePullRequest string `yaml:"release_pull_request" json:"release_pull_request"` // the body of release pull requests
+	ReleaseNotes       string `yaml:"release_notes" json:"release_notes"`               // the notes of releases
+}
+
+// paths returns the configured template paths keyed by template name.
+func (t Templates) paths() map[string]string {
+	paths := make(map[string]string)
+	for name, path := range map[string]string{
+		changelog.ChangelogTemplate:          t.Changelog,
+		changelog.PullRequestTemplate:        t.PullRequest,
+		changelog.ReleasePullRequestTemplate: t.ReleasePullRequest,
+		changelog.ReleaseNotesTemplate:       t.ReleaseNotes,
+	} {
+		if path != "" {
+			paths[name] = path
+		}
+	}
+	return paths
+}
+
+// Type configures a commit type. A type named like one of the default types, or breaking for the breaking changes
+// section, changes the settings that are set and keeps the others, any other name adds a type.
+type Type struct {
+	Name   string `yaml:"name" json:"name"`     // the commit type, e.g. security
+	Title  string `yaml:"title" json:"title"`   // title of the changelog section, required for added types
+	Order  *int   `yaml:"order" json:"order"`   // changelog sections are ordered by ascending order, after the other types by default
+	Hidden *bool  `yaml:"hidden" json:"hidden"` // leave the commits of the type out of the changelog
+	Bump   string `yaml:"bump" json:"bump"`     // major, minor, patch or none (default)
+}
+
+// TypeList returns the default commit types with the configured types and changelog section titles applied.
+func (c *Config) TypeList() ([]conventional.Type, error) {
+	types := conventional.DefaultTypes()
+	for i, configured := range c.Types {
+		if configured.Name == "" {
+			return nil, fmt.Errorf("types[%d].name is required", i)
+		}
+		if slices.ContainsFunc(c.Types[:i], func(t Type) bool { return t.Name == configured.Name }) {
+			return nil, fmt.Errorf("types[%d].name %q is not unique", i, configured.Name)
+		}
+		index := slices.IndexFunc(types, func(t conventional.Type) bool { return t.Name == configured.Name })
+		if index == -1 {
+			if configured.Title == "" {
+				return nil, fmt.Errorf("types[%d].title is required for the type %s", i, configured.Name)
+			}
+			last := slices.MaxFunc(types, func(a, b conventional.Type) int { return a.Order - b.Order })
+			types = append(types, conventional.Type{Name: configured.Name, Order: last.Order + 10, Bump: conventional.None})
+			index = len(types) - 1
+		}
+
+		t := &types[index]
+		if configured.Title != "" {
+			t.Title = configured.Title
+		}
+		if configured.Order != nil {
+			t.Order = *configured.Order
+		}
+		if configured.Hidden != nil {
+			t.Hidden = *configured.Hidden
+		}
+		if configured.Bump != "" {
+			bump, err := conventional.ParseIncrement(configured.Bump)
+			if err != nil {
+				return nil, fmt.Errorf("types[%d].bump: %w", i, err)
+			}
+			if t.Name == conventional.Breaking && bump != conventional.Major {
+				return nil, fmt.Errorf("types[%d].bump of breaking changes must be major", i)
+			}
+			if t.Name == conventional.Dependencies {
+				return nil, fmt.Errorf("types[%d].bump of dependency updates is set by dependencies", i)
+			}
+			t.Bump = bump
+		}
+	}
+
+	var unknown []string
+	for section, title := range c.Changelog.Sections {
+		index := slices.IndexFunc(types, func(t conventional.Type) bool { return t.Name == section })
+		if index == -1 {
+			unknown = append(unknown, section)
+			continue
+		}
+		types[index].Title = title
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown changelog.sections %s, expected one of %s", strings.Join(unknown, ", "), strings.Join(names(types), ", "))
+	}
+	return types, nil
+}
+
+// Dependencies is the policy for dependency updates, the bump required by an update of each type of dependency: major,
+// minor, patch, none or follow to bump like the dependency was updated, e.g. minor for an update from 1.2.0 to 1.3.0.
+// Updates require a patch bump by default.
+type Dependencies struct {
+	Production  string `yaml:"production" json:"production"`   // direct production dependencies
+	Development string `yaml:"development" json:"development"` // development dependencies, e.g. devDependencies or deps-dev
+	Indirect    string `yaml:"indirect" json:"indirect"`       // indirect (transitive) dependencies
+	Unknown     string `yaml:"unknown" json:"unknown"`         // updates of an unknown dependency type, e.g. with the deps scope only
+}
+
+// Policy returns the default dependency policy with the configured bumps applied.
+func (d Dependencies) Policy() (conventional.DependencyPolicy, error) {
+	policy := conventional.DefaultPolicy()
+	for _, bump := range []struct {
+		name       string
+		configured string
+		policy     *conventional.DependencyBump
+	}{
+		{"production", d.Production, &policy.Production},
+		{"development", d.Development, &policy.Development},
+		{"indirect", d.Indirect, &policy.Indirect},
+		{"unknown", d.Unknown, &policy.Unknown},
+	} {
+		if bump.configured == "" {
+			continue
+		}
+		parsed, err := conventional.ParseDependencyBump(bump.configured)
+		if err != nil {
+			return policy, fmt.Errorf("dependencies.%s: %w", bump.name, err)
+		}
+		*bump.policy = parsed
+	}
+	return policy, nil
+}
+
+// Component is a part of the repository, e.g. a module or service of a monorepo, that is versioned independently. When
+// components are configured, each component gets its own version, changelog and release pull request.
+type Component struct {
+	Name      string `yaml:"name" json:"name"`             // name of the component, used in release branch names
+	Path      string `yaml:"path" json:"path"`             // directory of the component, commits changing files within it belong to the component
+	TagPrefix string `yaml:"tag_prefix" json:"tag_prefix"` // prefix of the version tags, {name}/ by default
+	Changelog string `yaml:"changelog" json:"changelog"`   // path of the changelog file, CHANGELOG.md within path by default
+}
+
+// componentName matches the component names that are valid within branch names.
+var componentName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Composite returns the component with the defaults applied.
+func (c Component) Composite() composite.Component {
+	component := composite.Component{Name: c.Name, Path: c.Path, TagPrefix: c.TagPrefix, Changelog: c.Changelog}
+	if component.TagPrefix == "" {
+		component.TagPrefix = c.Name + "/"
+	}
+	if component.Changelog == "" {
+		component.Changelog = path.Join(c.Path, "CHANGELOG.md")
+	}
+	return component
+}
+
+// ComponentList returns the configured components with the defaults applied.
+func (c *Config) ComponentList() (components []composite.Component) {
+	for _, component := range c.Components {
+		components = append(components, component.Composite())
+	}
+	return
+}
+
+// Branch contains the settings for the branches created by version_actions.
+type Branch struct {
+	ReleasePrefix string `yaml:"release_prefix" json:"release_prefix"` // prefix of the release branch, release--branch-- by default
+}
+
+// Changelog contains the settings for the generated changelog.
+type Changelog struct {
+	Path     string            `yaml:"path" json:"path"`         // path of the changelog file, CHANGELOG.md by default
+	Sections map[string]string `yaml:"sections" json:"sections"` // section titles keyed by section, e.g. feat: "Features"
+	Scopes   Scopes            `yaml:"scopes" json:"scopes"`
+	Trailers []string          `yaml:"trailers" json:"trailers"` // trailers left out of the commit bodies, an empty list keeps all of them
+	Style    string            `yaml:"style" json:"style"`       // keepachangelog for the Keep a Changelog layout, the default layout otherwise
+	// Categories are the Keep a Changelog categories of the keepachangelog style keyed by commit type, e.g.
+	// perf: Changed, an empty category leaves the commits of the type out
+	Categories map[string]string `yaml:"categories" json:"categories"`
+	// JSON exports the releases as JSON, to releases.json next to the changelog file and release.json per release
+	JSON bool `yaml:"json" json:"json"`
+	// Prereleases is collapse to replace the prerelease changelogs with the changelog of the release they are promoted
+	// to, the default, or keep to keep them below it
+	Prereleases string `yaml:"prereleases" json:"prereleases"`
+	// Unreleased is summary to write the Unreleased section of the keepachangelog style to the step summary of the
+	// pull_request action, the default, or commit to commit it to the changelog file on the head branch
+	Unreleased string `yaml:"unreleased" json:"unreleased"`
+	Links      Links  `yaml:"links" json:"links"`
+}
+
+// TypeCategories returns the categories of the commit types of the keepachangelog style, the default categories
+// changed by the configured ones.
+func (c Changelog) TypeCategories() map[string]string {
+	categories := changelog.DefaultTypeCategories()
+	for t, category := range c.Categories {
+		if category == "" {
+			delete(categories, t)
+		} else {
+			categories[t] = category
+		}
+	}
+	return categories
+}
+
+// Scopes contains the settings for the scopes of the commits in the changelog.
+type Scopes struct {
+	Mode    string            `yaml:"mode" json:"mode"`       // prefix or group, the scopes are not rendered by default
+	Labels  map[string]string `yaml:"labels" json:"labels"`   // labels keyed by scope, e.g. api: "API"
+	Include []string          `yaml:"include" json:"include"` // only the commits with these scopes, or without a scope
+	Exclude []string          `yaml:"exclude" json:"exclude"` // leave the commits with these scopes out
+}
+
+// Options returns the scope options of the changelog.
+func (s Scopes) Options() changelog.ScopeOptions {
+	return changelog.ScopeOptions{Mode: s.Mode, Labels: s.Labels, Include: s.Include, Exclude: s.Exclude}
+}
+
+// Links contains the settings for the references linked in the changelog.
+type Links struct {
+	Issues   *bool     `yaml:"issues" json:"issues"` // link #123 and owner/repo#123 to GitHub issues and pull requests, true by default
+	Trackers []Tracker `yaml:"trackers" json:"trackers"`
+}
+
+// Tracker is an external issue tracker whose references are linked in the changelog.
+type Tracker struct {
+	Pattern string `yaml:"pattern" json:"pattern"` // regular expression matching the references, e.g. \bPROJ-\d+\b
+	URL     string `yaml:"url" json:"url"`         // the link of a reference, $0 is the reference and $1 or ${name} its submatches
+}
+
+// Options returns the link options of the changelog.
+func (l Links) Options() (changelog.LinkOptions, error) {
+	options := changelog.LinkOptions{Issues: l.Issues == nil || *l.Issues}
+	for i, t := range l.Trackers {
+		if t.Pattern == "" {
+			return options, fmt.Errorf("changelog.links.trackers[%d].pattern is required", i)
+		}
+		pattern, err := regexp.Compile(t.Pattern)
+		if err != nil {
+			return options, fmt.Errorf("changelog.links.trackers[%d].pattern: %w", i, err)
+		}
+		if t.URL == "" {
+			return options, fmt.Errorf("changelog.links.trackers[%d].url is required", i)
+		}
+		options.Trackers = append(options.Trackers, changelog.Tracker{Pattern: pattern, URL: t.URL})
+	}
+	return options, nil
+}
+
+// PullRequest contains the settings for the pull requests opened by the pull_request action.
+type PullRequest struct {
+	TitleMaxLength int `yaml:"title_max_length" json:"title_max_length"` // titles longer than this are truncated, 70 by default
+}
+
+// Error is returned when the configuration file cannot be read or is invalid.
+type Error struct {
+	Path string
+	Err  error
+}
+
+func (e Error) Error() string {
+	return fmt.Errorf("invalid configuration file %s: %w", e.Path, e.Err).Error()
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// Load reads the first configuration file found in Paths. If no configuration file exists, an empty Config is returned
+// so that the defaults are used. Unknown keys and invalid values are reported as an Error.
+func Load() (*Config, error) {
+	for _, path := range Paths {
+		_, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		config := &Config{}
+		err = utility.Open(path, func(file *os.File) error {
+			content, err := io.ReadAll(file)
+			if err != nil {
+				return err
+			}
+			return decode(path, content, config)
+		})
+		if err == nil {
+			err = config.validate()
+		}
+		if err != nil {
+			return nil, Error{Path: path, Err: err}
+		}
+		log.Info().Msgf("Loaded configuration from %s", path)
+		return config, nil
+	}
+	return &Config{}, nil
+}
+
+// decode decodes the content as JSON or YAML depending on the file extension, rejecting unknown keys.
+func decode(path string, content []byte, config *Config) error {
+	if filepath.Ext(path) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(config)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	err := decoder.Decode(config)
+	if errors.Is(err, io.EOF) { // empty file
+		return errors.New("version is required")
+	}
+	return err
+}
+
+func (c *Config) validate() error {
+	if c.Version == 0 {
+		return errors.New("version is required")
+	}
+	if c.Version != Version {
+		return fmt.Errorf("unsupported version %d, expected %d", c.Version, Version)
+	}
+	if c.Backend != "" && !slices.Contains(local.Backends, c.Backend) {
+		return fmt.Errorf("unknown backend %q, expected one of %s", c.Backend, strings.Join(local.Backends, ", "))
+	}
+	if c.PullRequest.TitleMaxLength < 0 {
+		return fmt.Errorf("pull_request.title_max_length must not be negative, got %d", c.PullRequest.TitleMaxLength)
+	}
+	if !slices.Contains(changelog.ScopeModes, c.Changelog.Scopes.Mode) {
+		return fmt.Errorf("unknown changelog.scopes.mode %q, expected one of prefix, group", c.Changelog.Scopes.Mode)
+	}
+	if !slices.Contains(changelog.Styles, c.Changelog.Style) {
+		return fmt.Errorf("unknown changelog.style %q, expected %s", c.Changelog.Style, changelog.StyleKeepAChangelog)
+	}
+	if c.Changelog.Prereleases != "" && !slices.Contains(changelog.PrereleaseModes, c.Changelog.Prereleases) {
+		return fmt.Errorf("unknown changelog.prereleases %q, expected one of collapse, keep", c.Changelog.Prereleases)
+	}
+	if c.Changelog.Unreleased != "" && !slices.Contains(changelog.UnreleasedModes, c.Changelog.Unreleased) {
+		return fmt.Errorf("unknown changelog.unreleased %q, expected one of summary, commit", c.Changelog.Unreleased)
+	}
+	if _, err := c.Changelog.Links.Options(); err != nil {
+		return err
+	}
+	if err := c.validateCategories(); err != nil {
+		return err
+	}
+	for _, scope := range c.Changelog.Scopes.Include {
+		if slices.Contains(c.Changelog.Scopes.Exclude, scope) {
+			return fmt.Errorf("changelog.scopes: %s is both included and excluded", scope)
+		}
+	}
+	if err := c.validateComponents(); err != nil {
+		return err
+	}
+	if _, err := c.TypeList(); err != nil {
+		return err
+	}
+	if _, err := c.Dependencies.Policy(); err != nil {
+		return err
+	}
+	for name, path := range c.Templates.paths() {
+		if _, err := changelog.ParseTemplate(name, path); err != nil {
+			return fmt.Errorf("templates.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateCategories validates that the categories are of known commit types and are Keep a Changelog categories.
+func (c *Config) validateCategories() error {
+	types, err := c.TypeList()
+	if err != nil {
+		return err
+	}
+	var keys []string
+	for t := range c.Changelog.Categories {
+		keys = append(keys, t)
+	}
+	sort.Strings(keys)
+	for _, t := range keys {
+		if !slices.ContainsFunc(types, func(known conventional.Type) bool { return known.Name == t }) {
+			return fmt.Errorf("unknown changelog.categories %s, expected one of %s", t, strings.Join(names(types), ", "))
+		}
+		if category := c.Changelog.Categories[t]; category != "" && !changelog.IsCategory(category) {
+			return fmt.Errorf("changelog.categories.%s: unknown category %q, expected one of %s", t, category, strings.Join(changelog.Categories, ", "))
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateComponents() error {
+	names := make(map[string]bool)
+	prefixes := make(map[string]string)
+	for i, component := range c.ComponentList() {
+		switch {
+		case component.Name == "":
+			return fmt.Errorf("components[%d].name is required", i)
+		case !componentName.MatchString(component.Name):
+			return fmt.Errorf("components[%d].name %q may only contain letters, digits, '.', '_' and '-'", i, component.Name)
+		case names[component.Name]:
+			return fmt.Errorf("components[%d].name %q is not unique", i, component.Name)
+		case c.Components[i].Path == "":
+			return fmt.Errorf("components[%d].path is required", i)
+		}
+		if other, ok := prefixes[component.TagPrefix]; ok {
+			return fmt.Errorf("components[%d].tag_prefix %q is already used by component %s", i, component.TagPrefix, other)
+		}
+		names[component.Name] = true
+		prefixes[component.TagPrefix] = component.Name
+	}
+	return nil
+}
+
+// names returns the sorted names of the types.
+func names(types []conventional.Type) (names []string) {
+	for _, t := range types {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// Apply sets the configured settings of the changelog and of the commit types on the packages they belong to, until
+// restore is called. Settings that are not configured are left unchanged.
+func (c *Config) Apply() (restore func()) {
+	path, scopes, links, style, categories, trailers := changelog.Path, changelog.Scopes, changelog.Links, changelog.Style, changelog.TypeCategories, changelog.Trailers
+	export, prereleases, types, policy := changelog.JSON, changelog.Prereleases, conventional.Types, conventional.Policy
+	templates := make(map[string]*template.Template, len(changelog.Templates))
+	for name, tmpl := range changelog.Templates {
+		templates[name] = tmpl
+	}
+	restore = func() {
+		changelog.Path, changelog.Scopes, changelog.Links, changelog.Style, changelog.TypeCategories, changelog.Trailers = path, scopes, links, style, categories, trailers
+		changelog.JSON, changelog.Prereleases, conventional.Types, conventional.Policy = export, prereleases, types, policy
+		changelog.Templates = templates
+	}
+
+	if c.Changelog.Path != "" {
+		changelog.Path = c.Changelog.Path
+	}
+	changelog.Scopes = c.Changelog.Scopes.Options()
+	if links, err := c.Changelog.Links.Options(); err == nil { // validated when loaded
+		changelog.Links = links
+	}
+	changelog.Templates = make(map[string]*template.Template, len(templates))
+	for name, tmpl := range templates {
+		changelog.Templates[name] = tmpl
+	}
+	if c.Changelog.Style != "" {
+		changelog.Style = c.Changelog.Style
+		changelog.Templates[changelog.ChangelogTemplate] = changelog.StyleTemplate(c.Changelog.Style)
+	}
+	if len(c.Changelog.Categories) > 0 {
+		changelog.TypeCategories = c.Changelog.TypeCategories()
+	}
+	if c.Changelog.Trailers != nil {
+		changelog.Trailers = c.Changelog.Trailers
+	}
+	if c.Changelog.JSON {
+		changelog.JSON = true
+	}
+	if c.Changelog.Prereleases != "" {
+		changelog.Prereleases = c.Changelog.Prereleases
+	}
+	if types, err := c.TypeList(); err == nil && (len(c.Types) > 0 || len(c.Changelog.Sections) > 0) {
+		conventional.Types = types
+	}
+	if policy, err := c.Dependencies.Policy(); err == nil { // validated when loaded
+		conventional.Policy = policy
+	}
+	for name, path := range c.Templates.paths() {
+		if tmpl, err := changelog.ParseTemplate(name, path); err == nil { // validated when loaded
+			changelog.Templates[name] = tmpl
+		}
+	}
+	return restore
+}
+
+// DefaultPrerelease is the prerelease identifier used when none is given or configured.
+const DefaultPrerelease = "rc"
+
+// DefaultTitleMaxLength is the maximum length of the pull request titles when none is configured.
+const DefaultTitleMaxLength = 70
+
+// Inputs are the settings given to a command as flags or action inputs. Inputs take precedence over the configuration
+// file, empty inputs are read from the configuration file.
+type Inputs struct {
+	Prerelease    string   // the prerelease identifier
+	ReleaseBranch string   // the primary release branch, "." for the default branch of the repository
+	Backend       string   // api or local
+	CommitFiles   []string // additional files to include in the release commit
+	PreMajor      string   // "true" or "false"
+}
+
+// Settings are the settings of a command resolved from its Inputs, the configuration file and the defaults.
+type Settings struct {
+	PrereleaseIdentifier string
+	ReleaseBranch        string // empty for the default branch of the repository, see ResolveReleaseBranch
+	Backend              string
+	CommitFiles          []string
+	PreMajor             bool
+	Components           []composite.Component
+	ReleaseBranchPrefix  string
+	TitleMaxLength       int
+	Config               *Config // the configuration file, for the settings applied with Config.Apply
+}
+
+// Resolve loads the configuration file and resolves the Settings of a command: inputs take precedence over the
+// configuration file, and the configuration file over the defaults.
+func Resolve(inputs Inputs) (settings Settings, err error) {
+	cfg, err := Load()
+	if err != nil {
+		return settings, err
+	}
+	settings = Settings{
+		PrereleaseIdentifier: first(inputs.Prerelease, cfg.Prerelease, DefaultPrerelease),
+		Backend:              first(inputs.Backend, cfg.Backend),
+		CommitFiles:          inputs.CommitFiles,
+		PreMajor:             cfg.PreMajor,
+		Components:           cfg.ComponentList(),
+		ReleaseBranchPrefix:  first(cfg.Branch.ReleasePrefix, composite.ReleaseBranchPrefix),
+		TitleMaxLength:       cfg.PullRequest.TitleMaxLength,
+		Config:               cfg,
+	}
+	if inputs.ReleaseBranch != "." { // . is the default value for the release branch
+		settings.ReleaseBranch = inputs.ReleaseBranch
+	}
+	settings.ReleaseBranch = first(settings.ReleaseBranch, cfg.ReleaseBranch)
+	if len(settings.CommitFiles) == 0 {
+		settings.CommitFiles = cfg.CommitFiles
+	}
+	if inputs.PreMajor != "" {
+		if settings.PreMajor, err = strconv.ParseBool(inputs.PreMajor); err != nil {
+			return settings, fmt.Errorf("invalid value %q for pre-major, expected true or false", inputs.PreMajor)
+		}
+	}
+	if settings.TitleMaxLength == 0 {
+		settings.TitleMaxLength = DefaultTitleMaxLength
+	}
+	return settings, nil
+}
+
+// ResolveReleaseBranch sets the release branch to the default branch of the repository if none is given or configured.
+func (s *Settings) ResolveReleaseBranch(client *github.Client) error {
+	if s.ReleaseBranch != "" {
+		return nil
+	}
+	branch, err := client.Repository().DefaultBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get default branch: %w", err)
+	}
+	s.ReleaseBranch = branch.Name
+	return nil
+}
+
+// first returns the first value that is not empty.
+func first(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
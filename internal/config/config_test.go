@@ -0,0 +1,328 @@
+package config
+
+import (
+	"github.com/jakbytes/version_actions/tools/changelog"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"github.com/jakbytes/version_actions/tools/github/composite"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withConfig writes the content to a configuration file with the given name and points Paths at it for the duration
+// of the test.
+func withConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.Nil(t, os.WriteFile(path, []byte(content), 0644))
+
+	original := Paths
+	Paths = []string{path}
+	t.Cleanup(func() { Paths = original })
+	return path
+}
+
+func TestLoad_YAML(t *testing.T) {
+	withConfig(t, ".version_actions.yml", `version: 1
+prerelease: beta
+release_branch: trunk
+pre_major: true
+commit_files:
+  - package.json
+backend: local
+branch:
+  release_prefix: "release/"
+changelog:
+  path: docs/CHANGELOG.md
+  sections:
+    feat: "New Features"
+  trailers: []
+  json: true
+pull_request:
+  title_max_length: 50
+`)
+
+	config, err := Load()
+	require.Nil(t, err)
+	require.Equal(t, &Config{
+		Version:       1,
+		Prerelease:    "beta",
+		ReleaseBranch: "trunk",
+		PreMajor:      true,
+		CommitFiles:   []string{"package.json"},
+		Backend:       "local",
+		Branch:        Branch{ReleasePrefix: "release/"},
+		Changelog: Changelog{
+			Path:     "docs/CHANGELOG.md",
+			Sections: map[string]string{"feat": "New Features"},
+			Trailers: []string{},
+			JSON:     true,
+		},
+		PullRequest: PullRequest{TitleMaxLength: 50},
+	}, config)
+}
+
+func TestLoad_JSON(t *testing.T) {
+	withConfig(t, ".version_actions.json", `{"version": 1, "prerelease": "beta", "changelog": {"path": "HISTORY.md"}}`)
+
+	config, err := Load()
+	require.Nil(t, err)
+	require.Equal(t, "beta", config.Prerelease)
+	require.Equal(t, "HISTORY.md", config.Changelog.Path)
+}
+
+func TestLoad_Components(t *testing.T) {
+	withConfig(t, ".version_actions.yml", `version: 1
+components:
+  - name: api
+    path: services/api
+  - name: web
+    path: web/
+    tag_prefix: web-
+    changelog: docs/WEB_CHANGELOG.md
+`)
+
+	config, err := Load()
+	require.Nil(t, err)
+	require.Equal(t, []composite.Component{
+		{Name: "api", Path: "services/api", TagPrefix: "api/", Changelog: "services/api/CHANGELOG.md"},
+		{Name: "web", Path: "web/", TagPrefix: "web-", Changelog: "docs/WEB_CHANGELOG.md"},
+	}, config.ComponentList())
+}
+
+func TestLoad_NoFile(t *testing.T) {
+	original := Paths
+	Paths = []string{filepath.Join(t.TempDir(), ".version_actions.yml")}
+	defer func() { Paths = original }()
+
+	config, err := Load()
+	require.Nil(t, err)
+	require.Equal(t, &Config{}, config)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		file    string
+		content string
+		err     string
+	}{
+		{"unknown key", ".version_actions.yml", "version: 1\nchangelog:\n  file: CHANGELOG.md\n", "field file not found in type config.Changelog"},
+		{"unknown json key", ".version_actions.json", `{"version": 1, "prerelase": "beta"}`, `json: unknown field "prerelase"`},
+		{"missing version", ".version_actions.yml", "prerelease: beta\n", "version is required"},
+		{"empty file", ".version_actions.yml", "", "version is required"},
+		{"unsupported version", ".version_actions.yml", "version: 2\n", "unsupported version 2, expected 1"},
+		{"invalid value", ".version_actions.yml", "version: 1\npull_request:\n  title_max_length: long\n", "cannot unmarshal !!str `long` into int"},
+		{"negative title length", ".version_actions.yml", "version: 1\npull_request:\n  title_max_length: -1\n", "pull_request.title_max_length must not be negative, got -1"},
+		{"unknown backend", ".version_actions.yml", "version: 1\nbackend: svn\n", `unknown backend "svn", expected one of api, local`},
+		{"component without name", ".version_actions.yml", "version: 1\ncomponents:\n  - path: api\n", "components[0].name is required"},
+		{"component without path", ".version_actions.yml", "version: 1\ncomponents:\n  - name: api\n", "components[0].path is required"},
+		{"invalid component name", ".version_actions.yml", "version: 1\ncomponents:\n  - name: my api\n    path: api\n", `components[0].name "my api" may only contain`},
+		{"duplicate component", ".version_actions.yml", "version: 1\ncomponents:\n  - name: api\n    path: api\n  - name: api\n    path: other\n", `components[1].name "api" is not unique`},
+		{"duplicate tag prefix", ".version_actions.yml", "version: 1\ncomponents:\n  - name: api\n    path: api\n  - name: web\n    path: web\n    tag_prefix: api/\n", `components[1].tag_prefix "api/" is already used by component api`},
+		{"type without name", ".version_actions.yml", "version: 1\ntypes:\n  - title: Security\n", "types[0].name is required"},
+		{"added type without title", ".version_actions.yml", "version: 1\ntypes:\n  - name: security\n", "types[0].title is required for the type security"},
+		{"duplicate type", ".version_actions.yml", "version: 1\ntypes:\n  - name: feat\n  - name: feat\n", `types[1].name "feat" is not unique`},
+		{"unknown bump", ".version_actions.yml", "version: 1\ntypes:\n  - name: feat\n    bump: micro\n", `types[0].bump: unknown increment "micro", expected one of major, minor, patch, none`},
+		{"breaking bump", ".version_actions.yml", "version: 1\ntypes:\n  - name: breaking\n    bump: minor\n", "types[0].bump of breaking changes must be major"},
+		{"dependencies bump", ".version_actions.yml", "version: 1\ntypes:\n  - name: dependencies\n    bump: minor\n", "types[0].bump of dependency updates is set by dependencies"},
+		{"unknown dependency bump", ".version_actions.yml", "version: 1\ndependencies:\n  development: skip\n", `dependencies.development: unknown bump "skip", expected one of major, minor, patch, none, follow`},
+		{"unknown scope mode", ".version_actions.yml", "version: 1\nchangelog:\n  scopes:\n    mode: table\n", `unknown changelog.scopes.mode "table", expected one of prefix, group`},
+		{"included and excluded scope", ".version_actions.yml", "version: 1\nchangelog:\n  scopes:\n    include: [api]\n    exclude: [api]\n", "changelog.scopes: api is both included and excluded"},
+		{"unknown style", ".version_actions.yml", "version: 1\nchangelog:\n  style: gitmoji\n", `unknown changelog.style "gitmoji", expected keepachangelog`},
+		{"unknown prereleases", ".version_actions.yml", "version: 1\nchangelog:\n  prereleases: squash\n", `unknown changelog.prereleases "squash", expected one of collapse, keep`},
+		{"unknown unreleased", ".version_actions.yml", "version: 1\nchangelog:\n  unreleased: push\n", `unknown changelog.unreleased "push", expected one of summary, commit`},
+		{"invalid tracker pattern", ".version_actions.yml", "version: 1\nchangelog:\n  links:\n    trackers:\n      - pattern: 'PROJ-(\\d+'\n        url: https://jira.example.com/browse/$0\n", "changelog.links.trackers[0].pattern: error parsing regexp: missing closing ): `PROJ-(\\d+`"},
+		{"missing tracker url", ".version_actions.yml", "version: 1\nchangelog:\n  links:\n    trackers:\n      - pattern: 'PROJ-\\d+'\n", "changelog.links.trackers[0].url is required"},
+		{"unknown category type", ".version_actions.yml", "version: 1\nchangelog:\n  categories:\n    feature: Added\n", "unknown changelog.categories feature, expected one of breaking, build, chore, ci, debug, dependencies, docs, feat, fix, perf, refactor, revert, style, test"},
+		{"unknown category", ".version_actions.yml", "version: 1\nchangelog:\n  categories:\n    perf: Improved\n", `changelog.categories.perf: unknown category "Improved", expected one of Added, Changed, Deprecated, Removed, Fixed, Security`},
+		{"missing template", ".version_actions.yml", "version: 1\ntemplates:\n  changelog: missing.md.tmpl\n", "templates.changelog: open missing.md.tmpl: no such file or directory"},
+		{"unknown section", ".version_actions.yml", "version: 1\nchangelog:\n  sections:\n    feature: Features\n", "unknown changelog.sections feature, expected one of breaking, build, chore, ci, debug, dependencies, docs, feat, fix, perf, refactor, revert, style, test"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := withConfig(t, tc.file, tc.content)
+
+			_, err := Load()
+			require.NotNil(t, err)
+			require.ErrorAs(t, err, &Error{})
+			require.Contains(t, err.Error(), "invalid configuration file "+path)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestLoad_Types(t *testing.T) {
+	withConfig(t, ".version_actions.yml", `version: 1
+types:
+  - name: security
+    title: Security
+    order: 15
+    bump: patch
+  - name: deps
+    title: Dependencies
+    hidden: true
+  - name: chore
+    hidden: true
+  - name: docs
+    bump: patch
+changelog:
+  sections:
+    security: Security Fixes
+`)
+
+	config, err := Load()
+	require.Nil(t, err)
+	types, err := config.TypeList()
+	require.Nil(t, err)
+	require.Len(t, types, len(conventional.DefaultTypes())+2)
+	require.Contains(t, types, conventional.Type{Name: "security", Title: "Security Fixes", Order: 15, Bump: conventional.Patch})
+	require.Contains(t, types, conventional.Type{Name: "deps", Title: "Dependencies", Order: 120, Hidden: true, Bump: conventional.None})
+	require.Contains(t, types, conventional.Type{Name: "chore", Title: "Chores", Order: 110, Hidden: true, Bump: conventional.None})
+	require.Contains(t, types, conventional.Type{Name: "docs", Title: "Documentation", Order: 30, Bump: conventional.Patch})
+}
+
+func TestApply(t *testing.T) {
+	path, types, policy, scopes, trailers := changelog.Path, conventional.Types, conventional.Policy, changelog.Scopes, changelog.Trailers
+	style, categories, tmpl, export, prereleases := changelog.Style, changelog.TypeCategories, changelog.Templates[changelog.ChangelogTemplate], changelog.JSON, changelog.Prereleases
+	links := changelog.Links
+
+	restore := (&Config{}).Apply()
+	defer restore()
+	require.Equal(t, "CHANGELOG.md", changelog.Path)
+	require.Equal(t, conventional.DefaultTypes(), conventional.Types)
+	require.Equal(t, conventional.DefaultPolicy(), conventional.Policy)
+	require.Equal(t, changelog.DefaultTrailers(), changelog.Trailers)
+	require.Equal(t, changelog.StyleDefault, changelog.Style)
+	require.Equal(t, changelog.DefaultTypeCategories(), changelog.TypeCategories)
+	require.False(t, changelog.JSON)
+	require.Equal(t, changelog.PrereleasesCollapse, changelog.Prereleases)
+	require.Equal(t, changelog.LinkOptions{Issues: true}, changelog.Links)
+
+	restore = (&Config{
+		Changelog: Changelog{
+			Path:        "HISTORY.md",
+			Sections:    map[string]string{"feat": "New Features"},
+			Scopes:      Scopes{Mode: "group", Labels: map[string]string{"api": "API"}, Exclude: []string{"internal"}},
+			Trailers:    []string{"Signed-off-by", "Change-Id"},
+			Style:       changelog.StyleKeepAChangelog,
+			Categories:  map[string]string{"security": "Security", "perf": ""},
+			JSON:        true,
+			Prereleases: changelog.PrereleasesKeep,
+			Links:       Links{Issues: new(bool), Trackers: []Tracker{{Pattern: `\bPROJ-\d+\b`, URL: "https://jira.example.com/browse/$0"}}},
+		},
+		Types:        []Type{{Name: "security", Title: "Security", Bump: "patch"}},
+		Dependencies: Dependencies{Production: "follow", Development: "none"},
+	}).Apply()
+	defer restore()
+	require.Equal(t, "HISTORY.md", changelog.Path)
+	require.Equal(t, changelog.ScopeOptions{Mode: "group", Labels: map[string]string{"api": "API"}, Exclude: []string{"internal"}}, changelog.Scopes)
+	require.Equal(t, []string{"Signed-off-by", "Change-Id"}, changelog.Trailers)
+	require.Equal(t, changelog.StyleKeepAChangelog, changelog.Style)
+	require.Equal(t, "Security", changelog.TypeCategories["security"])
+	require.NotContains(t, changelog.TypeCategories, "perf")
+	require.Equal(t, "Added", changelog.TypeCategories["feat"])
+	require.True(t, changelog.JSON)
+	require.Equal(t, changelog.PrereleasesKeep, changelog.Prereleases)
+	require.False(t, changelog.Links.Issues)
+	require.Len(t, changelog.Links.Trackers, 1)
+	require.Equal(t, `\bPROJ-\d+\b`, changelog.Links.Trackers[0].Pattern.String())
+	require.Equal(t, "https://jira.example.com/browse/$0", changelog.Links.Trackers[0].URL)
+	rendered, err := changelog.Render(changelog.ChangelogTemplate, changelog.Data{Unreleased: true})
+	require.Nil(t, err)
+	require.Equal(t, changelog.Markdown{"## [Unreleased]", ""}, rendered)
+	feat, _ := conventional.LookupType("feat")
+	require.Equal(t, "New Features", feat.Title)
+	fix, _ := conventional.LookupType("fix")
+	require.Equal(t, "Fixes", fix.Title)
+	security, ok := conventional.LookupType("security")
+	require.True(t, ok)
+	require.Equal(t, conventional.Patch, security.Bump)
+	require.Equal(t, conventional.DependencyPolicy{
+		Production:  conventional.DependencyBump{Increment: conventional.None, Follow: true},
+		Development: conventional.DependencyBump{Increment: conventional.None},
+		Indirect:    conventional.DependencyBump{Increment: conventional.Patch},
+		Unknown:     conventional.DependencyBump{Increment: conventional.Patch},
+	}, conventional.Policy)
+
+	// the settings are restored after the call
+	restore()
+	require.Equal(t, path, changelog.Path)
+	require.Equal(t, types, conventional.Types)
+	require.Equal(t, policy, conventional.Policy)
+	require.Equal(t, scopes, changelog.Scopes)
+	require.Equal(t, trailers, changelog.Trailers)
+	require.Equal(t, style, changelog.Style)
+	require.Equal(t, categories, changelog.TypeCategories)
+	require.Same(t, tmpl, changelog.Templates[changelog.ChangelogTemplate])
+	require.Equal(t, export, changelog.JSON)
+	require.Equal(t, prereleases, changelog.Prereleases)
+	require.Equal(t, links, changelog.Links)
+}
+
+func TestResolve(t *testing.T) {
+	withConfig(t, ".version_actions.yml", "version: 1\nprerelease: beta\nrelease_branch: main\nbackend: local\npre_major: true\n"+
+		"commit_files: [package.json]\nbranch:\n  release_prefix: release/\npull_request:\n  title_max_length: 50\n")
+
+	settings, err := Resolve(Inputs{})
+	require.Nil(t, err)
+	require.Equal(t, "beta", settings.PrereleaseIdentifier)
+	require.Equal(t, "main", settings.ReleaseBranch)
+	require.Equal(t, "local", settings.Backend)
+	require.True(t, settings.PreMajor)
+	require.Equal(t, []string{"package.json"}, settings.CommitFiles)
+	require.Equal(t, "release/", settings.ReleaseBranchPrefix)
+	require.Equal(t, 50, settings.TitleMaxLength)
+
+	// the inputs take precedence over the configuration file, . is the default branch of the repository
+	settings, err = Resolve(Inputs{Prerelease: "rc", ReleaseBranch: ".", Backend: "api", CommitFiles: []string{"version.txt"}, PreMajor: "false"})
+	require.Nil(t, err)
+	require.Equal(t, "rc", settings.PrereleaseIdentifier)
+	require.Equal(t, "main", settings.ReleaseBranch)
+	require.Equal(t, "api", settings.Backend)
+	require.False(t, settings.PreMajor)
+	require.Equal(t, []string{"version.txt"}, settings.CommitFiles)
+
+	settings, err = Resolve(Inputs{ReleaseBranch: "develop"})
+	require.Nil(t, err)
+	require.Equal(t, "develop", settings.ReleaseBranch)
+
+	_, err = Resolve(Inputs{PreMajor: "maybe"})
+	require.EqualError(t, err, `invalid value "maybe" for pre-major, expected true or false`)
+}
+
+func TestResolve_Defaults(t *testing.T) {
+	original := Paths
+	Paths = []string{filepath.Join(t.TempDir(), ".version_actions.yml")}
+	t.Cleanup(func() { Paths = original })
+
+	settings, err := Resolve(Inputs{ReleaseBranch: "."})
+	require.Nil(t, err)
+	require.Equal(t, Settings{
+		PrereleaseIdentifier: DefaultPrerelease,
+		ReleaseBranchPrefix:  composite.ReleaseBranchPrefix,
+		TitleMaxLength:       DefaultTitleMaxLength,
+		Config:               &Config{},
+	}, settings)
+}
+
+func TestApply_Templates(t *testing.T) {
+	original := changelog.Templates[changelog.ReleaseNotesTemplate]
+	defer func() { changelog.Templates[changelog.ReleaseNotesTemplate] = original }()
+	path := filepath.Join(t.TempDir(), "release_notes.md.tmpl")
+	require.Nil(t, os.WriteFile(path, []byte("Release {{ .Version }}\n"), 0644))
+	withConfig(t, ".version_actions.yml", "version: 1\ntemplates:\n  release_notes: "+path+"\n")
+
+	settings, err := Resolve(Inputs{})
+	require.Nil(t, err)
+	defer settings.Config.Apply()()
+	notes, err := changelog.Render(changelog.ReleaseNotesTemplate, changelog.Data{Version: "v1.0.0"})
+	require.Nil(t, err)
+	require.Equal(t, changelog.Markdown{"Release v1.0.0"}, notes)
+
+	withConfig(t, ".version_actions.yml", "version: 1\ntemplates:\n  release_notes: "+path+"\n")
+	require.Nil(t, os.WriteFile(path, []byte("{{ if }}"), 0644))
+	_, err = Load()
+	require.ErrorContains(t, err, "templates.release_notes: template: release_notes:1: missing value for if")
+}
@@ -1,12 +1,87 @@
 package logger
 
 import (
+	"fmt"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"io"
 	"os"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Redacted replaces secrets in the log output.
+const Redacted = "***"
+
+// tokenPattern matches GitHub token-shaped strings: classic personal access, OAuth, user-to-server, server-to-server
+// and refresh tokens, as well as fine-grained personal access tokens.
+var tokenPattern = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})\b`)
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
 func Base() zerolog.Logger {
-	return log.Logger.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339Nano})
+	return log.Logger.Output(zerolog.ConsoleWriter{Out: RedactWriter{Out: os.Stderr}, TimeFormat: time.RFC3339Nano})
+}
+
+// Mask registers a secret so that it is redacted from the log output. Inside GitHub Actions the runner is also told to
+// mask the secret in the workflow logs.
+func Mask(secret string) {
+	if secret == "" {
+		return
+	}
+	mu.Lock()
+	secrets = append(secrets, secret)
+	mu.Unlock()
+
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		_, _ = fmt.Fprintf(os.Stdout, "::add-mask::%s\n", secret)
+	}
+}
+
+// Redact replaces the registered secrets and anything shaped like a GitHub token in s.
+func Redact(s string) string {
+	mu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	mu.RUnlock()
+	return tokenPattern.ReplaceAllString(s, Redacted)
+}
+
+// RedactWriter is an io.Writer that redacts secrets before writing to Out.
+type RedactWriter struct {
+	Out io.Writer
+}
+
+func (w RedactWriter) Write(p []byte) (int, error) {
+	if _, err := w.Out.Write([]byte(Redact(string(p)))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Error reports the error with the title. Inside GitHub Actions it is written as an error annotation, which is shown
+// in the workflow run summary, otherwise it is printed to stderr. Secrets are redacted from the title and the message.
+func Error(title string, err error) {
+	message := Redact(err.Error())
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		_, _ = fmt.Fprintf(os.Stdout, "::error title=%s::%s\n", escapeProperty(Redact(title)), escapeData(message))
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "%s: %s\n", Redact(title), message)
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
 }
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"classic token", "token ghp_" + strings.Repeat("a", 36) + " used", "token *** used"},
+		{"installation token", "ghs_" + strings.Repeat("B1", 18), "***"},
+		{"fine-grained token", "github_pat_" + strings.Repeat("x_", 20) + "end", "***"},
+		{"short prefix is kept", "ghp_short", "ghp_short"},
+		{"plain text", "nothing to see", "nothing to see"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, Redact(tt.input))
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	original := secrets
+	defer func() { secrets = original }()
+	t.Setenv("GITHUB_ACTIONS", "")
+
+	Mask("s3cr3t")
+	Mask("")
+	require.Equal(t, "token=***", Redact("token=s3cr3t"))
+
+	var buffer bytes.Buffer
+	logger := zerolog.New(RedactWriter{Out: &buffer})
+	logger.Info().Str("token", "s3cr3t").Msg("authenticated")
+	require.NotContains(t, buffer.String(), "s3cr3t")
+	require.Contains(t, buffer.String(), Redacted)
+}
+
+func TestMask_Actions(t *testing.T) {
+	original := secrets
+	defer func() { secrets = original }()
+	t.Setenv("GITHUB_ACTIONS", "true")
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	os.Stdout = w
+	Mask("s3cr3t")
+	os.Stdout = stdout
+	require.Nil(t, w.Close())
+
+	var buffer bytes.Buffer
+	_, err = buffer.ReadFrom(r)
+	require.Nil(t, err)
+	require.Equal(t, "::add-mask::s3cr3t\n", buffer.String())
+}
+
+// capture returns what f writes to stdout and stderr.
+func capture(t *testing.T, f func()) (string, string) {
+	stdout, stderr := os.Stdout, os.Stderr
+	outR, outW, err := os.Pipe()
+	require.Nil(t, err)
+	errR, errW, err := os.Pipe()
+	require.Nil(t, err)
+
+	os.Stdout, os.Stderr = outW, errW
+	f()
+	os.Stdout, os.Stderr = stdout, stderr
+	require.Nil(t, outW.Close())
+	require.Nil(t, errW.Close())
+
+	var outBuffer, errBuffer bytes.Buffer
+	_, err = outBuffer.ReadFrom(outR)
+	require.Nil(t, err)
+	_, err = errBuffer.ReadFrom(errR)
+	require.Nil(t, err)
+	return outBuffer.String(), errBuffer.String()
+}
+
+func TestError(t *testing.T) {
+	original := secrets
+	defer func() { secrets = original }()
+	secrets = []string{"s3cr3t"}
+
+	tests := []struct {
+		name    string
+		actions string
+		title   string
+		err     error
+		stdout  string
+		stderr  string
+	}{
+		{"annotation", "true", "Version conflict", errors.New("v1.0.0 exists"), "::error title=Version conflict::v1.0.0 exists\n", ""},
+		{"escaped", "true", "a: b, c%", errors.New("50%\nof s3cr3t\r"), "::error title=a%3A b%2C c%25::50%25%0Aof ***%0D\n", ""},
+		{"outside actions", "", "Version conflict", errors.New("s3cr3t exists"), "", "Version conflict: *** exists\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_ACTIONS", tt.actions)
+			stdout, stderr := capture(t, func() { Error(tt.title, tt.err) })
+			require.Equal(t, tt.stdout, stdout)
+			require.Equal(t, tt.stderr, stderr)
+		})
+	}
+}
@@ -8,9 +8,15 @@ import (
 type GitService struct {
 	CreateRefError error
 	UpdateRefError error
+	Refs           *[]*github.Reference
+	Blobs          *[]*github.Blob   // the created blobs are recorded if set
+	Commits        *[]*github.Commit // the created commits are recorded if set
 }
 
 func (g GitService) CreateBlob(ctx context.Context, owner string, repo string, blob *github.Blob) (*github.Blob, *github.Response, error) {
+	if g.Blobs != nil {
+		*g.Blobs = append(*g.Blobs, blob)
+	}
 	return &github.Blob{
 		SHA: github.String("hash4-hash4"),
 	}, nil, nil
@@ -23,6 +29,9 @@ func (g GitService) CreateTree(ctx context.Context, owner string, repo string, b
 }
 
 func (g GitService) CreateCommit(ctx context.Context, owner string, repo string, commit *github.Commit, opts *github.CreateCommitOptions) (*github.Commit, *github.Response, error) {
+	if g.Commits != nil {
+		*g.Commits = append(*g.Commits, commit)
+	}
 	return &github.Commit{
 		SHA: github.String("hash4-hash4"),
 	}, nil, nil
@@ -40,5 +49,8 @@ func (g GitService) CreateRef(ctx context.Context, owner string, repo string, re
 	if g.CreateRefError != nil {
 		return nil, nil, g.CreateRefError
 	}
-	return nil, nil, nil
+	if g.Refs != nil {
+		*g.Refs = append(*g.Refs, ref)
+	}
+	return ref, nil, nil
 }
@@ -10,6 +10,9 @@ type PullRequestsService struct {
 	Inner        error
 	InnerEdit    error
 	PullRequests []*github.PullRequest
+	Closed       []*github.PullRequest
+	// FilterHead only lists the pull requests whose head label matches the requested head
+	FilterHead bool
 }
 
 var prs = []*github.PullRequest{
@@ -73,8 +76,18 @@ func (m *PullRequestsService) List(ctx context.Context, owner string, repo strin
 		return nil, nil, m.Inner
 	}
 
+	if opts.State == "closed" {
+		return m.Closed, &github.Response{}, nil
+	}
+
 	if m.PullRequests != nil {
-		return m.PullRequests, &github.Response{}, nil
+		var matching []*github.PullRequest
+		for _, pr := range m.PullRequests {
+			if !m.FilterHead || pr.GetHead().GetLabel() == opts.Head {
+				matching = append(matching, pr)
+			}
+		}
+		return matching, &github.Response{}, nil
 	}
 	// Mock response - you should tailor this to match what you expect
 	mockPR := &github.PullRequest{
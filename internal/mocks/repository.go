@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"github.com/google/go-github/v58/github"
+	"net/http"
 	"time"
 )
 
@@ -14,6 +15,9 @@ type RepositoryService struct {
 	Commits        []*github.RepositoryCommit
 	Tags           []*github.RepositoryTag
 	Comparison     *github.CommitsComparison
+	Releases       []*github.RepositoryRelease
+	Files          map[string][]string // files changed by a commit, keyed by SHA
+	Contents       map[string]string   // contents of the files of every branch, keyed by path
 }
 
 func (r *RepositoryService) GetBranch(ctx context.Context, owner string, repo string, branch string, maxRedirects int) (*github.Branch, *github.Response, error) {
@@ -143,12 +147,38 @@ func (r *RepositoryService) ListCommits(ctx context.Context, owner string, repo
 	}, nil, nil
 }
 
+func (r *RepositoryService) GetCommit(ctx context.Context, owner string, repo string, sha string, opts *github.ListOptions) (*github.RepositoryCommit, *github.Response, error) {
+	if r.Inner != nil {
+		return nil, nil, r.Inner
+	}
+	commit := &github.RepositoryCommit{SHA: github.String(sha)}
+	for _, file := range r.Files[sha] {
+		commit.Files = append(commit.Files, &github.CommitFile{Filename: github.String(file)})
+	}
+	return commit, nil, nil
+}
+
 func (r *RepositoryService) ListTags(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
 	if r.Inner != nil {
 		return nil, nil, r.Inner
 	}
 	if r.Tags != nil {
-		return r.Tags, nil, nil
+		if opts == nil || opts.PerPage == 0 {
+			return r.Tags, nil, nil
+		}
+		var tags []*github.RepositoryTag
+		for i := opts.Page; i < opts.Page+opts.PerPage; i++ {
+			// If we've reached the end of the Tags, return what we have
+			if i >= len(r.Tags) {
+				return tags, &github.Response{
+					NextPage: 0,
+				}, nil
+			}
+			tags = append(tags, r.Tags[i])
+		}
+		return tags, &github.Response{
+			NextPage: opts.Page + opts.PerPage,
+		}, nil
 	}
 	return []*github.RepositoryTag{
 		{
@@ -171,3 +201,24 @@ func (r *RepositoryService) ListTags(ctx context.Context, owner string, repo str
 		},
 	}, nil, nil
 }
+
+func (r *RepositoryService) CreateRelease(ctx context.Context, owner string, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	if r.Inner != nil {
+		return nil, nil, r.Inner
+	}
+	release.HTMLURL = github.String("https://github.com/" + owner + "/" + repo + "/releases/tag/" + release.GetTagName())
+	r.Releases = append(r.Releases, release)
+	return release, &github.Response{}, nil
+}
+
+func (r *RepositoryService) GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+	if r.Inner != nil {
+		return nil, nil, nil, r.Inner
+	}
+	content, ok := r.Contents[path]
+	if !ok {
+		response := &http.Response{StatusCode: http.StatusNotFound}
+		return nil, nil, &github.Response{Response: response}, &github.ErrorResponse{Response: response, Message: "Not Found"}
+	}
+	return &github.RepositoryContent{Type: github.String("file"), Path: github.String(path), Content: github.String(content)}, nil, &github.Response{}, nil
+}
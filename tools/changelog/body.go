@@ -0,0 +1,78 @@
+package changelog
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DependabotMetadata is the name of the updated-dependencies metadata block of Dependabot commit messages, which is
+// filtered like a trailer.
+const DependabotMetadata = "updated-dependencies"
+
+// Trailers are the keys of the trailers left out of the rendered commit bodies, matched case-insensitively. The
+// Dependabot metadata is left out if DependabotMetadata is one of them.
+var Trailers = DefaultTrailers()
+
+// DefaultTrailers returns the trailers left out of the rendered commit bodies when no trailers are configured.
+func DefaultTrailers() []string {
+	return []string{"Signed-off-by", "Reviewed-by", "Co-authored-by", DependabotMetadata}
+}
+
+// trailer matches a trailer or footer line of a commit message, e.g. Signed-off-by: Alice <alice@example.com>
+var trailer = regexp.MustCompile(`^(BREAKING CHANGE|[A-Za-z][A-Za-z0-9-]*): (.*)$`)
+
+// splitBody splits the lines of a commit message after the description into the body, without the trailers that are
+// left out, and the migration note of a BREAKING CHANGE footer. The note spans the lines up to the next BREAKING CHANGE
+// footer, trailer that is left out or trailer of the final paragraph, so that it may contain lines like Before: foo().
+func splitBody(lines []string) (body []string, breaking []string) {
+	dropped := func(key string) bool {
+		return slices.ContainsFunc(Trailers, func(t string) bool { return strings.EqualFold(t, key) })
+	}
+	final := len(lines) // the first line of the final paragraph
+	for i := len(trimTrailingBlank(lines)) - 1; i >= 0 && strings.TrimSpace(lines[i]) != ""; i-- {
+		final = i
+	}
+	note, metadata := false, false
+	for i, line := range lines {
+		if metadata { // the metadata block ends with a ... line
+			metadata = line != "..."
+			continue
+		}
+		if line == "---" && i+1 < len(lines) && strings.HasPrefix(lines[i+1], DependabotMetadata+":") && dropped(DependabotMetadata) {
+			metadata = true
+			continue
+		}
+
+		match := trailer.FindStringSubmatch(line)
+		breakingChange := match != nil && (match[1] == "BREAKING CHANGE" || match[1] == "BREAKING-CHANGE")
+		if note && match != nil && !breakingChange && i < final && !dropped(match[1]) {
+			match = nil // a line of the migration note
+		}
+		if match != nil {
+			note = false
+		}
+		switch {
+		case breakingChange:
+			note = true
+			breaking = append(breaking, match[2])
+		case note:
+			breaking = append(breaking, line)
+		case match != nil && dropped(match[1]):
+		default:
+			body = append(body, line)
+		}
+	}
+	return trimTrailingBlank(body), trimTrailingBlank(breaking)
+}
+
+// trimTrailingBlank removes the blank lines at the end of the lines, nil is returned if all lines are blank.
+func trimTrailingBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return lines
+}
@@ -0,0 +1,44 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitBody(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		body     []string
+		breaking []string
+	}{
+		{
+			"note ends at a trailer",
+			"\nThe v1 endpoints were deprecated.\n\nBREAKING CHANGE: the v1 endpoints are removed,\ncall the v2 endpoints instead.\nRefs: #12",
+			[]string{"", "The v1 endpoints were deprecated.", "", "Refs: #12"},
+			[]string{"the v1 endpoints are removed,", "call the v2 endpoints instead."},
+		},
+		{
+			"multi-line note",
+			"\nBREAKING CHANGE: the client is renamed, replace\n\nBefore: client.Foo()\nAfter: client.Bar()\n\nRefs: #12\nSigned-off-by: Alice <alice@example.com>",
+			[]string{"", "Refs: #12"},
+			[]string{"the client is renamed, replace", "", "Before: client.Foo()", "After: client.Bar()"},
+		},
+		{
+			"note ends at a trailer that is left out",
+			"\nBREAKING CHANGE: the flag is removed\nBefore: --flag\nSigned-off-by: Alice <alice@example.com>\n\nThe flag was unused.",
+			[]string{"", "", "The flag was unused."},
+			[]string{"the flag is removed", "Before: --flag"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, breaking := splitBody(strings.Split(tc.message, "\n"))
+			assert.Equal(t, tc.body, body)
+			assert.Equal(t, tc.breaking, breaking)
+		})
+	}
+}
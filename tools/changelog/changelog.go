@@ -1,17 +1,11 @@
 package changelog
 
 import (
-	"bufio"
-	"errors"
-	"fmt"
-	"github.com/google/go-github/v58/github"
 	"github.com/jakbytes/version_actions/internal/utility"
 	"github.com/jakbytes/version_actions/tools/conventional"
 	"github.com/jakbytes/version_actions/tools/semver"
-	"io/fs"
 	"os"
 	"strings"
-	"time"
 )
 
 type Markdown []string
@@ -30,140 +24,115 @@ func (b Markdown) String() (s string) {
 
 var Path = "CHANGELOG.md"
 
-type Section struct {
-	Title   string
-	Commits []*github.RepositoryCommit
+// File is a changelog file, of the repository or of a component of the repository whose version tags start with
+// TagPrefix.
+type File struct {
+	Path      string
+	TagPrefix string
 }
 
-// GenerateNewChangelog generates a Markdown formatted changelog from the provided GitHub commits. It is intended to
-// aggregate the changes from just the commits since the previous version.
-func GenerateNewChangelog(org, repo string, previousVersion, version *semver.Version, commits conventional.Commits, disableVersionHeader bool) (body Markdown) {
-	body = append(body, generateVersionHeader(org, repo, previousVersion, version, disableVersionHeader))
-
-	sections := []Section{
-		{"⚠ BREAKING CHANGES", commits.Breaking},
-		{"Features", commits.Feat},
-		{"Fixes", commits.Fix},
-		{"Documentation", commits.Docs},
-		{"Styles", commits.Style},
-		{"Refactors", commits.Refactor},
-		{"Performance", commits.Perf},
-		{"Test", commits.Test},
-		{"Build", commits.Build},
-		{"CI/CD", commits.CI},
-		{"Debugging", commits.Debug},
-		{"Chores", commits.Chore},
-	}
-
-	for _, section := range sections {
-		if len(section.Commits) > 0 {
-			body = append(body, fmt.Sprintf("### %s", section.Title), "")
-			for _, commit := range section.Commits {
-				body = append(body, formatCommit(org, repo, commit)...)
-			}
-			body = append(body, "")
-		}
-	}
+// Default returns the changelog file of the repository.
+func Default() File {
+	return File{Path: Path}
+}
 
-	return
+// Tag returns the name of the tag for the version.
+func (f File) Tag(version *semver.Version) string {
+	return f.TagPrefix + "v" + version.String()
 }
 
-func generateVersionHeader(org, repo string, previousVersion, version *semver.Version, disableVersionHeader bool) string {
-	currentDate := time.Now().UTC().Format("2006-01-02")
+// GenerateNewChangelog generates a Markdown formatted changelog from the provided GitHub commits. It is intended to
+// aggregate the changes from just the commits since the previous version.
+func GenerateNewChangelog(org, repo string, previousVersion, version *semver.Version, commits conventional.Commits, disableVersionHeader bool) (Markdown, error) {
+	return Default().Generate(org, repo, previousVersion, version, commits, disableVersionHeader)
+}
 
-	if disableVersionHeader {
-		return "## Changelog"
-	} else if previousVersion != nil {
-		// Header for the version with GitHub compare link
-		return fmt.Sprintf("## [v%s](https://github.com/%s/%s/compare/v%s...v%s) (%s)", version, org, repo, previousVersion, version, currentDate)
-	} else {
-		return fmt.Sprintf("## [v%s] Initial Version (%s)", version, currentDate)
-	}
+// Generate generates the Markdown formatted changelog of the version for the file with the changelog template, see
+// GenerateNewChangelog.
+func (f File) Generate(org, repo string, previousVersion, version *semver.Version, commits conventional.Commits, disableVersionHeader bool) (Markdown, error) {
+	return Render(ChangelogTemplate, f.Data(org, repo, previousVersion, version, commits, disableVersionHeader))
 }
 
-func formatCommit(org, repo string, commit *github.RepositoryCommit) Markdown {
-	// Extracting the first line of the commit message
-	message := strings.TrimSpace(strings.SplitN(strings.TrimSpace(*commit.Commit.Message), ":", 2)[1])
-	messageParts := strings.Split(message, "\n")
-	// Extracting a short commit hash
-	shortSHA := (*commit.SHA)[:7]
+func WriteChangelog(org, repo string, previousVersion, version *semver.Version, commits conventional.Commits, disableVersionHeader bool) (Markdown, Markdown, error) {
+	return Default().Write(org, repo, previousVersion, version, commits, disableVersionHeader)
+}
 
-	m := Markdown{
-		fmt.Sprintf("- ([`%s`](https://github.com/%s/%s/commit/%s)) %s", shortSHA, org, repo, *commit.SHA, messageParts[0]),
+// Write generates the changelog of the version and writes it to the top of the file, replacing the previous changelog
+// of the version if there is one. The changelog of the version and the full changelog are returned. With JSON set the
+// release is added to the releases file as well, see ExportPath.
+func (f File) Write(org, repo string, previousVersion, version *semver.Version, commits conventional.Commits, disableVersionHeader bool) (Markdown, Markdown, error) {
+	changelog, lines, err := f.Compose(org, repo, previousVersion, version, commits, disableVersionHeader)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	for _, line := range messageParts[1:] {
-		m = append(m, fmt.Sprintf("  > %s", line))
+	if err = WriteToFile(f.Path, lines); err == nil && JSON && version != nil && !disableVersionHeader {
+		err = f.WriteRelease(f.Export(org, repo, previousVersion, version, commits))
 	}
-	return m
+	return changelog, lines, err
 }
 
-// Determines whether a line should be skipped.
-func skipLine(line string, currentVersion, skipNextBreak, skipNextSpace *bool, versionHeading string) bool {
-	if strings.HasPrefix(line, "# Changelog") {
-		*skipNextBreak = true
-		*skipNextSpace = true
-		return true
+// WriteRelease adds the release to the releases file, see AddRelease.
+func (f File) WriteRelease(release JSONRelease) error {
+	releases, err := f.AddRelease(release)
+	if err != nil {
+		return err
 	}
-
-	if strings.HasPrefix(line, versionHeading) {
-		*currentVersion = true
-		return true
-	} else if strings.HasPrefix(line, "## [") {
-		*currentVersion = false
+	lines, err := MarshalJSON(releases)
+	if err != nil {
+		return err
 	}
+	return WriteToFile(f.ExportPath(), lines)
+}
 
-	if strings.HasPrefix(line, "---") {
-		if *skipNextBreak {
-			*skipNextBreak = false
-			*skipNextSpace = true
-			return true
-		}
+// Compose generates the changelog of the version and the full changelog Write would write to the file, without writing
+// it.
+func (f File) Compose(org, repo string, previousVersion, version *semver.Version, commits conventional.Commits, disableVersionHeader bool) (Markdown, Markdown, error) {
+	changelog, err := f.Generate(org, repo, previousVersion, version, commits, disableVersionHeader)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	if *skipNextSpace {
-		*skipNextSpace = false
-		return true
+	lines, err := f.Prepend(version, changelog)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	return *currentVersion
+	return changelog, lines, nil
 }
 
-func updateChangelog(version *semver.Version, lines Markdown) (Markdown, error) {
-	err := utility.Open(Path, func(file *os.File) (err error) {
-		versionHeading := "## [v" + strings.Split(version.String(), "-")[0]
-		currentVersion := false
-		skipNextBreak := false
-		skipNextSpace := false
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			skip := skipLine(line, &currentVersion, &skipNextBreak, &skipNextSpace, versionHeading)
-			if skip {
-				continue
-			}
-
-			lines = append(lines, line)
-		}
-		return nil
-	})
-
-	return lines, err
+// Prepend returns the full changelog with the changelog of the version inserted into the file, replacing the previous
+// changelog of the version if there is one, without writing it. The rest of the file is kept as it is, see Document.
+// In the keepachangelog style the changes of the Unreleased section are released with the version, the section is
+// emptied.
+func (f File) Prepend(version *semver.Version, changelog Markdown) (Markdown, error) {
+	document, err := ReadChangelog(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	if document.Preamble == "" {
+		document.Preamble = preamble()
+	}
+	rendered := ParseDocument(changelog.String() + "\n")
+	if len(rendered.Releases) == 0 { // e.g. a changelog without a version header
+		release := NewRelease(changelog)
+		release.Prefix, release.Version = f.TagPrefix, version
+		rendered = &Document{Releases: []Release{release}}
+	}
+	if Style == StyleKeepAChangelog {
+		document.SetUnreleased(Release{Tag: Unreleased, Text: "## [" + Unreleased + "]\n\n"})
+	}
+	document.Merge(rendered)
+	return document.Markdown(), nil
 }
 
-var UpdateChangelog = updateChangelog
-
-func WriteChangelog(org, repo string, previousVersion, version *semver.Version, commits conventional.Commits, disableVersionHeader bool) (Markdown, Markdown, error) {
-	changelog := GenerateNewChangelog(org, repo, previousVersion, version, commits, disableVersionHeader)
-	lines := append(Markdown{"# Changelog", ""}, changelog...) // initialize lines with the header and version changelog
-	_, err := os.Stat(Path)
-	if !errors.Is(err, fs.ErrNotExist) { // CHANGELOG.md exists, update the file with the new version changelog and retain the rest of the file
-		lines, err = UpdateChangelog(version, lines)
-		if err != nil {
-			return nil, nil, err
-		}
+// MergeUnreleased returns the full changelog with the Unreleased section replaced by the rendered changelog of the changes
+// that are not released yet, see UnreleasedData. existing is the content of the changelog file, e.g. as read from the
+// head branch of a pull request, and is empty if there is none.
+func MergeUnreleased(existing string, changelog Markdown) Markdown {
+	document := ParseDocument(existing)
+	if document.Preamble == "" {
+		document.Preamble = preamble()
 	}
-	return changelog, lines, WriteToFile(Path, lines)
+	document.Merge(ParseDocument(changelog.String() + "\n"))
+	return document.Markdown()
 }
 
 func writeString(file *os.File, line string) error {
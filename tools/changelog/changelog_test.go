@@ -17,6 +17,23 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// generateVersionHeader renders the changelog of a version without commits, which is just the version header.
+func generateVersionHeader(t *testing.T, f File, org, repo string, previousVersion, version *semver.Version, disableVersionHeader bool) string {
+	changelog, err := f.Generate(org, repo, previousVersion, version, conventional.Commits{}, disableVersionHeader)
+	require.Nil(t, err)
+	require.Len(t, changelog, 1)
+	return changelog[0]
+}
+
+// formatCommit renders the changelog of a single commit and returns the lines of the commit.
+func formatCommit(t *testing.T, org, repo string, commit *github.RepositoryCommit) Markdown {
+	changelog, err := GenerateNewChangelog(org, repo, nil, nil, conventional.Commits{"fix": {commit}}, true)
+	require.Nil(t, err)
+	require.Equal(t, Markdown{"## Changelog", "### Fixes", ""}, changelog[:3])
+	require.Equal(t, "", changelog[len(changelog)-1])
+	return changelog[3 : len(changelog)-1]
+}
+
 func TestGenerateVersionHeader(t *testing.T) {
 	org := "exampleOrg"
 	repo := "exampleRepo"
@@ -24,16 +41,20 @@ func TestGenerateVersionHeader(t *testing.T) {
 	prevVersion, _ := semver.NewVersion("0.9.0")
 
 	// Test with disableVersionHeader = true
-	result := generateVersionHeader(org, repo, prevVersion, version, true)
+	result := generateVersionHeader(t, Default(), org, repo, prevVersion, version, true)
 	assert.Equal(t, "## Changelog", result)
 
 	// Test with previousVersion = nil
-	result = generateVersionHeader(org, repo, nil, version, false)
+	result = generateVersionHeader(t, Default(), org, repo, nil, version, false)
 	assert.Contains(t, result, "## [v1.0.0] Initial Version", "Header should contain initial version info")
 
 	// Test with previousVersion != nil
-	result = generateVersionHeader(org, repo, prevVersion, version, false)
+	result = generateVersionHeader(t, Default(), org, repo, prevVersion, version, false)
 	assert.Contains(t, result, "https://github.com/exampleOrg/exampleRepo/compare/v0.9.0...v1.0.0", "Header should contain version comparison link")
+
+	// Test with a tag prefix
+	result = generateVersionHeader(t, File{TagPrefix: "api/"}, org, repo, prevVersion, version, false)
+	assert.Contains(t, result, "## [api/v1.0.0](https://github.com/exampleOrg/exampleRepo/compare/api/v0.9.0...api/v1.0.0)")
 }
 
 func TestFormatCommit(t *testing.T) {
@@ -56,7 +77,7 @@ func TestFormatCommit(t *testing.T) {
 	expected := "- ([`1234567`](https://github.com/org/repo/commit/1234567890abcdef)) This is a test"
 
 	// Running the test with assert
-	result := formatCommit("org", "repo", commit)
+	result := formatCommit(t, "org", "repo", commit)
 	assert.Equal(t, Markdown(strings.Split(expected, "\n")), result, "formatCommit should format the commit correctly")
 
 	commit = &github.RepositoryCommit{
@@ -81,26 +102,16 @@ func TestFormatCommit(t *testing.T) {
 		Author: &github.User{Login: github.String("johndoe")},
 	}
 
-	// Expected format
+	// A breaking change is rendered with the note of its BREAKING CHANGE footer as the migration note, in place of the
+	// body that used to be quoted in full
 	e := Markdown{
 		"- ([`1234567`](https://github.com/org/repo/commit/1234567890abcdef)) a solid description",
-		"  > ",
-		"  > a ton more detail to really understand what's going on",
-		"  > ",
-		"  > Introduce a request id and a reference to latest request. Dismiss",
-		"  > incoming responses other than from latest request.",
-		"  > ",
-		"  > Remove timeouts which were used to mitigate the racing issue but are",
-		"  > obsolete now.",
-		"  > ",
-		"  > BREAKING CHANGE: use JavaScript features not available in Node 6.",
+		"  > use JavaScript features not available in Node 6.",
 	}
 
 	// Running the test with assert
-	result = formatCommit("org", "repo", commit)
-	for i, line := range e {
-		assert.Equal(t, line, result[i])
-	}
+	result = formatCommit(t, "org", "repo", commit)
+	assert.Equal(t, e, result)
 }
 
 // Sample mock commit function
@@ -132,59 +143,21 @@ func TestGenerateNewChangelog(t *testing.T) {
 	fix := []*github.RepositoryCommit{mockCommit("fix: bug fix", "Charlie", "charlie", "ghi9012")}
 
 	// Test with non-empty commit lists
-	changelog := GenerateNewChangelog(org, repo, nil, version, conventional.Commits{Breaking: breaking, Feat: feat, Fix: fix}, false)
+	changelog, err := GenerateNewChangelog(org, repo, nil, version, conventional.Commits{conventional.Breaking: breaking, "feat": feat, "fix": fix}, false)
+	require.Nil(t, err)
 
 	require.Equal(t, 13, len(changelog))
 	require.True(t, strings.HasPrefix(changelog[0], "## [v1.0.0]"), "Changelog should contain version header")
 
 	// Test with empty commit lists and disableVersionHeader = true
-	changelog = GenerateNewChangelog(org, repo, nil, version, conventional.Commits{}, true)
+	changelog, err = GenerateNewChangelog(org, repo, nil, version, conventional.Commits{}, true)
+	require.Nil(t, err)
 	assert.NotContains(t, changelog, "## v1.0.0", "Changelog should not contain version header when disabled")
 	assert.NotContains(t, changelog, "Breaking Changes", "Changelog should not contain Breaking Changes section for empty list")
 }
 
-func TestShouldSkipLine_Header(t *testing.T) {
-	line := "# Changelog"
-	currentVersion := false
-	skipNextBreak := false
-	skipNextSpace := false
-	versionHeading := "## [v1.0.0"
-	assert.True(t, skipLine(line, &currentVersion, &skipNextBreak, &skipNextSpace, versionHeading), "skipLine should return true for changelog header")
-	assert.True(t, skipNextBreak, "skipLine should set skipNextBreak to true for changelog header")
-}
-
-func TestShouldSkipLine_CurrentVersion(t *testing.T) {
-	line := "## [v1.0.0]("
-	currentVersion := false
-	skipNextBreak := false
-	skipNextSpace := false
-	versionHeading := "## [v1.0.0"
-	assert.True(t, skipLine(line, &currentVersion, &skipNextBreak, &skipNextSpace, versionHeading), "skipLine should return true for current version header")
-	assert.True(t, currentVersion, "skipLine should set currentVersion to true for current version header")
-
-	line = "## [v0.9.0]("
-	currentVersion = true
-	skipNextBreak = false
-	versionHeading = "## [v1.0.0"
-	// should not skip line on different version
-	assert.False(t, skipLine(line, &currentVersion, &skipNextBreak, &skipNextSpace, versionHeading), "skipLine should return false for different version header")
-	assert.False(t, currentVersion, "skipLine should not change currentVersion for different version header")
-}
-
-func TestShouldSkipLine_SkipNextBreak(t *testing.T) {
-	line := "---"
-	currentVersion := false
-	skipNextBreak := true
-	skipNextSpace := false
-	versionHeading := "## [v1.0.0"
-	assert.True(t, skipLine(line, &currentVersion, &skipNextBreak, &skipNextSpace, versionHeading), "skipLine should return true for skipped break")
-	assert.False(t, skipNextBreak, "skipLine should set skipNextBreak to false for skipped break")
-}
-
 func TestUpdateChangelog(t *testing.T) {
-	var testInput = []string{
-		"# Changelog",
-		"",
+	var testInput = Markdown{
 		"## [v1.1.0-beta.2]",
 		"Feature 2",
 		"",
@@ -215,7 +188,7 @@ func TestUpdateChangelog(t *testing.T) {
 	version, err := semver.NewVersion("1.1.0-beta.2")
 	require.Nil(t, err)
 
-	lines, err := updateChangelog(version, testInput)
+	lines, err := Default().Prepend(version, testInput)
 	require.Nil(t, err)
 
 	assert.Equal(t, 7, len(lines))
@@ -261,7 +234,7 @@ func TestWriteChangelog(t *testing.T) {
 	feat := []*github.RepositoryCommit{mockCommit("feat: new feature", "Bob", "bob", "def5678")}
 	fix := []*github.RepositoryCommit{mockCommit("fix: bug fix", "Charlie", "charlie", "ghi9012")}
 
-	changelog, _, err := WriteChangelog(org, repo, prevVersion, version, conventional.Commits{Breaking: breaking, Feat: feat, Fix: fix}, false)
+	changelog, _, err := WriteChangelog(org, repo, prevVersion, version, conventional.Commits{conventional.Breaking: breaking, "feat": feat, "fix": fix}, false)
 	require.Nil(t, err)
 
 	assert.Equal(t, 13, len(changelog), "WriteChangelog should return 16 lines")
@@ -282,14 +255,14 @@ func TestWriteChangelog(t *testing.T) {
 	require.Nil(t, err)
 }
 
-func TestWriteChangelog_UpdateChangelogError(t *testing.T) {
+func TestWriteChangelog_ReadChangelogError(t *testing.T) {
 	_, _ = os.Create("test_CHANGELOG.md")
 	Path = "test_CHANGELOG.md"
-	UpdateChangelog = func(version *semver.Version, lines Markdown) (Markdown, error) {
+	ReadChangelog = func(path string) (*Document, error) {
 		return nil, assert.AnError
 	}
 	defer func() {
-		UpdateChangelog = updateChangelog
+		ReadChangelog = readChangelog
 	}()
 
 	defer func(name string) {
@@ -299,7 +272,7 @@ func TestWriteChangelog_UpdateChangelogError(t *testing.T) {
 		}
 	}(Path)
 
-	_, err := UpdateChangelog(nil, nil)
+	_, err := ReadChangelog(Path)
 	require.Equal(t, assert.AnError, err)
 
 	org := "exampleOrg"
@@ -312,7 +285,7 @@ func TestWriteChangelog_UpdateChangelogError(t *testing.T) {
 	feat := []*github.RepositoryCommit{mockCommit("feat: new feature", "Bob", "bob", "def5678")}
 	fix := []*github.RepositoryCommit{mockCommit("fix: bug fix", "Charlie", "charlie", "ghi9012")}
 
-	_, _, err = WriteChangelog(org, repo, prevVersion, version, conventional.Commits{Breaking: breaking, Feat: feat, Fix: fix}, false)
+	_, _, err = WriteChangelog(org, repo, prevVersion, version, conventional.Commits{conventional.Breaking: breaking, "feat": feat, "fix": fix}, false)
 	require.NotNil(t, err)
 	require.Equal(t, assert.AnError, err)
 }
@@ -344,23 +317,12 @@ func TestWriteChangelog_WriteToFileError(t *testing.T) {
 	feat := []*github.RepositoryCommit{mockCommit("feat: new feature", "Bob", "bob", "def5678")}
 	fix := []*github.RepositoryCommit{mockCommit("fix: bug fix", "Charlie", "charlie", "ghi9012")}
 
-	_, _, err := WriteChangelog(org, repo, prevVersion, version, conventional.Commits{Breaking: breaking, Feat: feat, Fix: fix}, false)
+	_, _, err := WriteChangelog(org, repo, prevVersion, version, conventional.Commits{conventional.Breaking: breaking, "feat": feat, "fix": fix}, false)
 	require.NotNil(t, err)
 	require.Equal(t, assert.AnError.Error(), err.Error())
 }
 
-func TestShouldSkipLine_SkipNextSpace(t *testing.T) {
-	line := ""
-	currentVersion := false
-	skipNextBreak := false
-	skipNextSpace := true
-	versionHeading := "## [v1.0.0"
-	assert.True(t, skipLine(line, &currentVersion, &skipNextBreak, &skipNextSpace, versionHeading), "skipLine should return true for skipped space")
-	assert.False(t, skipNextSpace, "skipLine should set skipNextSpace to false for skipped space")
-}
-
 func TestUpdateChangelog_Scenario1(t *testing.T) {
-	UpdateChangelog = updateChangelog
 	log.Logger = logger.Base()
 	var testInput = []string{
 		"# Changelog",
@@ -416,7 +378,7 @@ func TestUpdateChangelog_Scenario1(t *testing.T) {
 	}
 
 	short, full, err := WriteChangelog("jakbytes", "version_actions", semver.MustParse("v0.0.0"), semver.MustParse("v0.1.0-src.0"), conventional.Commits{
-		Feat: feat,
+		"feat": feat,
 	}, false)
 	require.Nil(t, err)
 
@@ -0,0 +1,363 @@
+package changelog
+
+import (
+	"errors"
+	"github.com/jakbytes/version_actions/tools/semver"
+	"io/fs"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Document is a parsed changelog file: the preamble before the first release, the releases in the order of the file,
+// newest first, and the trailing link reference definitions after the last release. String returns the file byte for
+// byte, changed only by the releases that were inserted.
+type Document struct {
+	Preamble string // e.g. "# Changelog\n\n"
+	Releases []Release
+	Trailer  string // the link reference definitions at the end of the file, e.g. "[1.0.0]: https://...\n"
+}
+
+// Release is the changelog of a version in a Document, from its heading up to the next release.
+type Release struct {
+	Tag        string          // the tag of the version in the heading, e.g. v1.2.0 or api/v1.2.0
+	Prefix     string          // the tag prefix of the tag, e.g. api/
+	Version    *semver.Version // nil if the heading names no version, e.g. of the Unreleased section
+	Date       string          // the date of the heading, e.g. 2024-02-16, empty if it has none
+	CompareURL string          // the link of the heading, or of the link reference definition of the tag
+	Sections   []ReleaseSection
+	Text       string // the release as written in the file, including the heading
+}
+
+// ReleaseSection is a ### section of a release.
+type ReleaseSection struct {
+	Title string // e.g. Features
+	Text  string // the lines after the heading up to the next section or release
+}
+
+var (
+	// releaseHeading matches the heading of a release, e.g. "## [v1.2.0](https://...) (2024-02-16)",
+	// "## [v1.0.0] Initial Version (2024-02-07)" or "## [1.0.0] - 2024-02-07"
+	releaseHeading = regexp.MustCompile(`^##[ \t]+\[?([^\s\[\]()]+?)\]?(?:\(([^)\s]*)\))?(?:[ \t]+(.*?))?[ \t]*$`)
+	// releaseTag matches the tag of a release heading, the tag prefix and the version
+	releaseTag = regexp.MustCompile(`^(.*?)v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)$`)
+	// releaseDate matches the date of a release heading
+	releaseDate = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+	// linkReference matches a link reference definition, e.g. [1.0.0]: https://github.com/owner/repo/releases/tag/v1.0.0
+	linkReference = regexp.MustCompile(`^\[([^\]]+)\]:[ \t]*(\S+)`)
+)
+
+// ParseDocument parses the content of a changelog file. A release starts at a ## heading naming a version or
+// Unreleased; other headings, e.g. of hand-written notes, are part of the preamble or of the release before them.
+func ParseDocument(content string) *Document {
+	d := &Document{}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var current *Release
+	var text strings.Builder
+	flush := func() {
+		if current == nil {
+			d.Preamble = text.String()
+		} else {
+			current.Text = text.String()
+			d.Releases = append(d.Releases, *current)
+		}
+		text.Reset()
+	}
+	for _, line := range lines {
+		if release, ok := parseHeading(trimLine(line)); ok {
+			flush()
+			current = &release
+		}
+		text.WriteString(line)
+	}
+	flush()
+
+	if len(d.Releases) > 0 {
+		last := &d.Releases[len(d.Releases)-1]
+		last.Text, d.Trailer = splitTrailer(last.Text)
+	}
+	references := make(map[string]string)
+	for _, line := range strings.SplitAfter(d.Trailer, "\n") {
+		if match := linkReference.FindStringSubmatch(trimLine(line)); match != nil {
+			references[match[1]] = match[2]
+		}
+	}
+	for i := range d.Releases {
+		r := &d.Releases[i]
+		r.Sections = parseSections(r.Text)
+		if r.CompareURL == "" {
+			r.CompareURL = references[r.Tag]
+		}
+	}
+	return d
+}
+
+// parseHeading parses the heading of a release, ok is false if the line is not a ## heading naming a version or
+// Unreleased.
+func parseHeading(line string) (release Release, ok bool) {
+	match := releaseHeading.FindStringSubmatch(line)
+	if match == nil {
+		return release, false
+	}
+	if strings.EqualFold(match[1], Unreleased) {
+		return Release{Tag: match[1], CompareURL: match[2]}, true
+	}
+	tag := releaseTag.FindStringSubmatch(match[1])
+	if tag == nil {
+		return release, false
+	}
+	version, err := semver.NewVersion(tag[2])
+	if err != nil {
+		return release, false
+	}
+	return Release{
+		Tag:        match[1],
+		Prefix:     tag[1],
+		Version:    version,
+		Date:       releaseDate.FindString(match[3]),
+		CompareURL: match[2],
+	}, true
+}
+
+// parseSections returns the ### sections of the text of a release.
+func parseSections(text string) (sections []ReleaseSection) {
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if title, ok := strings.CutPrefix(trimLine(line), "### "); ok {
+			sections = append(sections, ReleaseSection{Title: strings.TrimSpace(title)})
+		} else if len(sections) > 0 {
+			sections[len(sections)-1].Text += line
+		}
+	}
+	return
+}
+
+// splitTrailer splits the link reference definitions at the end of the text of the last release from it. The blank
+// lines before the first definition are kept with the release.
+func splitTrailer(text string) (release, trailer string) {
+	lines := strings.SplitAfter(text, "\n")
+	start := len(lines)
+	for i := len(lines) - 1; i > 0; i-- { // the heading is never part of the trailer
+		line := trimLine(lines[i])
+		if line == "" {
+			continue
+		}
+		if !linkReference.MatchString(line) {
+			break
+		}
+		start = i
+	}
+	return strings.Join(lines[:start], ""), strings.Join(lines[start:], "")
+}
+
+// trimLine removes the line ending of the line.
+func trimLine(line string) string {
+	return strings.TrimRight(line, "\r\n")
+}
+
+// String returns the content of the changelog file.
+func (d *Document) String() string {
+	var sb strings.Builder
+	sb.WriteString(d.Preamble)
+	for _, r := range d.Releases {
+		sb.WriteString(r.Text)
+	}
+	sb.WriteString(d.Trailer)
+	return sb.String()
+}
+
+// Markdown returns the lines of the changelog file.
+func (d *Document) Markdown() Markdown {
+	return strings.Split(strings.TrimSuffix(d.String(), "\n"), "\n")
+}
+
+// The ways the changelogs of prereleases are handled when the changelog of a release version is inserted, see
+// Prereleases.
+const (
+	PrereleasesCollapse = "collapse" // the release replaces the prereleases since the previous release version
+	PrereleasesKeep     = "keep"     // the prereleases are kept below the release
+)
+
+// PrereleaseModes are the ways the changelogs of prereleases are handled.
+var PrereleaseModes = []string{PrereleasesCollapse, PrereleasesKeep}
+
+// Prereleases is how the changelogs of prereleases are handled when the changelog of a release version is inserted.
+var Prereleases = PrereleasesCollapse
+
+// Insert adds the release to the document, replacing the releases with the same tag prefix and the same version. With
+// PrereleasesCollapse a prerelease replaces the prereleases of its version, e.g. v1.1.0-rc.1 replaces v1.1.0-rc.0, and
+// a release version replaces every prerelease since the previous release version, e.g. v2.0.0 replaces v1.1.0-rc.0 and
+// v2.0.0-rc.0 after v1.0.0. The link reference definitions of the replaced releases are removed. The release takes the
+// place of the first release it replaces, or else it is added before the newest release, below the Unreleased section.
+// Inserting the same release again leaves the document unchanged.
+func (d *Document) Insert(release Release) {
+	previous := d.previousRelease(release)
+	index := -1
+	var releases []Release
+	var replaced []string
+	for _, r := range d.Releases {
+		if replaces(release, r, previous) {
+			if index < 0 {
+				index = len(releases)
+			}
+			if r.Tag != release.Tag {
+				replaced = append(replaced, r.Tag)
+			}
+			continue
+		}
+		releases = append(releases, r)
+	}
+	if index < 0 { // below the Unreleased section
+		index = slices.IndexFunc(releases, func(r Release) bool { return !r.IsUnreleased() })
+		if index < 0 {
+			index = len(releases)
+		}
+	}
+	d.Releases = append(releases[:index:index], append([]Release{release}, releases[index:]...)...)
+	for _, tag := range replaced {
+		d.removeReference(tag)
+	}
+}
+
+// previousRelease returns the newest release version of the document below the version of the release with the same
+// tag prefix, nil if there is none.
+func (d *Document) previousRelease(release Release) (previous *semver.Version) {
+	for _, r := range d.Releases {
+		if release.Version == nil || r.Version == nil || r.Prefix != release.Prefix || r.Version.IsPrerelease() {
+			continue
+		}
+		if r.Version.LessThan(release.Version) && (previous == nil || previous.LessThan(r.Version)) {
+			previous = r.Version
+		}
+	}
+	return
+}
+
+// replaces reports whether the inserted release replaces the release r of the document, see Insert. Previous is the
+// previous release version of the inserted release.
+func replaces(release, r Release, previous *semver.Version) bool {
+	if release.Version == nil || r.Version == nil || r.Prefix != release.Prefix {
+		return false
+	}
+	if r.Version.Equal(release.Version) {
+		return true
+	}
+	if Prereleases == PrereleasesKeep || !r.Version.IsPrerelease() {
+		return false
+	}
+	if release.Version.IsPrerelease() {
+		return sameRelease(r.Version, release.Version)
+	}
+	return r.Version.LessThan(release.Version) && (previous == nil || previous.LessThan(r.Version))
+}
+
+// IsUnreleased reports whether the release is the Unreleased section.
+func (r Release) IsUnreleased() bool {
+	return r.Version == nil && strings.EqualFold(r.Tag, Unreleased)
+}
+
+// SetUnreleased replaces the Unreleased section of the document with the release, or adds it before the releases.
+func (d *Document) SetUnreleased(release Release) {
+	if index := slices.IndexFunc(d.Releases, Release.IsUnreleased); index >= 0 {
+		d.Releases[index] = release
+		return
+	}
+	d.Releases = append([]Release{release}, d.Releases...)
+}
+
+// SetReference sets the link reference definition of the label in the trailer, e.g. [v1.0.0]: https://..., replacing
+// the definition of the label if there is one. New definitions are added before the others, after the definition of
+// Unreleased, so that they are ordered newest first.
+func (d *Document) SetReference(label, url string) {
+	definition := "[" + label + "]: " + url + "\n"
+	lines := strings.SplitAfter(d.Trailer, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	index := -1
+	for i, line := range lines {
+		match := linkReference.FindStringSubmatch(trimLine(line))
+		if match == nil {
+			continue
+		}
+		if strings.EqualFold(match[1], label) {
+			lines[i] = definition
+			d.Trailer = strings.Join(lines, "")
+			return
+		}
+		if index < 0 {
+			index = i
+			if strings.EqualFold(match[1], Unreleased) && !strings.EqualFold(label, Unreleased) {
+				index++
+			}
+		}
+	}
+	if index < 0 {
+		index = len(lines)
+	}
+	if d.Trailer == "" && !strings.HasSuffix(d.String(), "\n\n") && d.String() != "" { // separate it from the last release
+		lines = append(lines, "\n")
+		index++
+	}
+	d.Trailer = strings.Join(slices.Insert(lines, index, definition), "")
+}
+
+// removeReference removes the link reference definition of the label from the trailer, if there is one.
+func (d *Document) removeReference(label string) {
+	var lines []string
+	for _, line := range strings.SplitAfter(d.Trailer, "\n") {
+		if match := linkReference.FindStringSubmatch(trimLine(line)); match == nil || !strings.EqualFold(match[1], label) {
+			lines = append(lines, line)
+		}
+	}
+	d.Trailer = strings.Join(lines, "")
+}
+
+// Merge inserts the releases of the other document, see Insert and SetUnreleased, and sets its link reference
+// definitions.
+func (d *Document) Merge(other *Document) {
+	for _, release := range other.Releases {
+		if release.IsUnreleased() {
+			d.SetUnreleased(release)
+		} else {
+			d.Insert(release)
+		}
+	}
+	for _, line := range strings.SplitAfter(other.Trailer, "\n") {
+		if match := linkReference.FindStringSubmatch(trimLine(line)); match != nil {
+			d.SetReference(match[1], match[2])
+		}
+	}
+}
+
+// sameRelease reports whether the versions are of the same release version, regardless of their prerelease.
+func sameRelease(a, b *semver.Version) bool {
+	return a.Major() == b.Major() && a.Minor() == b.Minor() && a.Patch() == b.Patch()
+}
+
+// NewRelease returns the release of the rendered changelog of a version. The fields are parsed from the heading, the
+// version is nil if the heading names none.
+func NewRelease(changelog Markdown) Release {
+	release, _ := parseHeading(trimLine(changelog[0]))
+	release.Text = changelog.String() + "\n"
+	release.Sections = parseSections(release.Text)
+	return release
+}
+
+// ReadChangelog reads and parses the changelog file, an empty Document is returned if the file does not exist.
+var ReadChangelog = readChangelog
+
+func readChangelog(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Document{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseDocument(string(content)), nil
+}
@@ -0,0 +1,182 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jakbytes/version_actions/tools/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const document = `# Changelog
+
+All notable changes are documented here.
+
+---
+
+## [v1.1.0-rc.0](https://github.com/owner/repo/compare/v1.0.0...v1.1.0-rc.0) (2024-02-16)
+### Features
+
+- ([` + "`abc1234`" + `](https://github.com/owner/repo/commit/abc1234)) add flag
+
+> A note added by hand.
+
+## v1.0.0 Initial Version (2024-02-07)
+### Features
+
+- initial version
+
+### Fixes
+
+- a fix
+
+## [0.9.0] - 2024-01-01
+Hand-written notes.
+
+[0.9.0]: https://github.com/owner/repo/releases/tag/0.9.0
+`
+
+func TestParseDocument(t *testing.T) {
+	d := ParseDocument(document)
+	assert.Equal(t, "# Changelog\n\nAll notable changes are documented here.\n\n---\n\n", d.Preamble)
+	assert.Equal(t, "[0.9.0]: https://github.com/owner/repo/releases/tag/0.9.0\n", d.Trailer)
+	require.Len(t, d.Releases, 3)
+
+	rc := d.Releases[0]
+	assert.Equal(t, "v1.1.0-rc.0", rc.Tag)
+	assert.Equal(t, "", rc.Prefix)
+	assert.Equal(t, "1.1.0-rc.0", rc.Version.String())
+	assert.Equal(t, "2024-02-16", rc.Date)
+	assert.Equal(t, "https://github.com/owner/repo/compare/v1.0.0...v1.1.0-rc.0", rc.CompareURL)
+	assert.Equal(t, []ReleaseSection{{Title: "Features", Text: "\n- ([`abc1234`](https://github.com/owner/repo/commit/abc1234)) add flag\n\n> A note added by hand.\n\n"}}, rc.Sections)
+
+	initial := d.Releases[1]
+	assert.Equal(t, "v1.0.0", initial.Tag)
+	assert.Equal(t, "2024-02-07", initial.Date)
+	assert.Equal(t, "", initial.CompareURL)
+	require.Len(t, initial.Sections, 2)
+	assert.Equal(t, "Fixes", initial.Sections[1].Title)
+
+	keepAChangelog := d.Releases[2]
+	assert.Equal(t, "0.9.0", keepAChangelog.Tag)
+	assert.Equal(t, "2024-01-01", keepAChangelog.Date)
+	assert.Equal(t, "https://github.com/owner/repo/releases/tag/0.9.0", keepAChangelog.CompareURL)
+	assert.Equal(t, "## [0.9.0] - 2024-01-01\nHand-written notes.\n\n", keepAChangelog.Text)
+
+	prefixed := ParseDocument("## [api/v2.0.0](https://example.com) (2024-03-01)\n").Releases
+	require.Len(t, prefixed, 1)
+	assert.Equal(t, "api/", prefixed[0].Prefix)
+	assert.Equal(t, "2.0.0", prefixed[0].Version.String())
+}
+
+func TestParseDocument_RoundTrip(t *testing.T) {
+	repository, err := os.ReadFile(filepath.Join("..", "..", "CHANGELOG.md"))
+	require.Nil(t, err)
+
+	for name, content := range map[string]string{
+		"empty":               "",
+		"document":            document,
+		"repository":          string(repository),
+		"no trailing newline": "# Changelog\n\n## [v1.0.0]\n- a change",
+		"crlf":                "# Changelog\r\n\r\n## [v1.0.0] (2024-01-01)\r\n### Fixes\r\n\r\n- a fix\r\n",
+		"no releases":         "# Changelog\n\nNothing was released yet.\n",
+		"only references":     "## [1.0.0]\n\n[1.0.0]: https://example.com\n[0.9.0]: https://example.com/0.9.0\n",
+		"unversioned heading": "# Changelog\n\n## Upgrading\n\nRead this first.\n\n## [v1.0.0]\n\n## Notes\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, content, ParseDocument(content).String())
+		})
+	}
+}
+
+func TestDocument_Insert(t *testing.T) {
+	release := NewRelease(Markdown{"## [v1.1.0](https://github.com/owner/repo/compare/v1.0.0...v1.1.0) (2024-02-20)", "### Features", "", "- add flag", ""})
+	require.Equal(t, "1.1.0", release.Version.String())
+
+	d := ParseDocument(document)
+	d.Insert(release)
+	require.Len(t, d.Releases, 3)
+	assert.Equal(t, "v1.1.0", d.Releases[0].Tag)
+	assert.Equal(t, "v1.0.0", d.Releases[1].Tag)
+	inserted := d.String()
+	assert.Equal(t, document[:len(d.Preamble)], inserted[:len(d.Preamble)])
+	assert.Contains(t, inserted, "## [v1.1.0](https://github.com/owner/repo/compare/v1.0.0...v1.1.0) (2024-02-20)\n### Features\n\n- add flag\n\n## v1.0.0 Initial Version")
+	assert.NotContains(t, inserted, "v1.1.0-rc.0")
+
+	// inserting the release again leaves the document unchanged
+	again := ParseDocument(inserted)
+	again.Insert(release)
+	assert.Equal(t, inserted, again.String())
+
+	// a release of another version or tag prefix is added before the newest release
+	d.Insert(NewRelease(Markdown{"## [api/v1.1.0] (2024-02-21)", ""}))
+	d.Insert(NewRelease(Markdown{"## [v1.2.0-rc.0] (2024-02-22)", ""}))
+	assert.Equal(t, []string{"v1.2.0-rc.0", "api/v1.1.0", "v1.1.0", "v1.0.0", "0.9.0"}, tags(d))
+}
+
+func TestDocument_Insert_Prereleases(t *testing.T) {
+	original := Prereleases
+	defer func() { Prereleases = original }()
+	const prereleases = "## [v2.0.0-rc.1] - 2024-03-02\n\n## [v2.0.0-rc.0] - 2024-03-01\n\n## [v1.1.0-rc.0] - 2024-02-01\n\n" +
+		"## [v1.0.0] - 2024-01-01\n\n## [v1.0.0-rc.0] - 2023-12-01\n\n" +
+		"[v2.0.0-rc.1]: https://example.com/v2.0.0-rc.1\n[v1.1.0-rc.0]: https://example.com/v1.1.0-rc.0\n[v1.0.0]: https://example.com/v1.0.0\n"
+	release := NewRelease(Markdown{"## [v2.0.0] - 2024-04-01", ""})
+
+	testCases := []struct {
+		mode    string
+		insert  Release
+		tags    []string
+		trailer string
+	}{
+		{PrereleasesCollapse, release, []string{"v2.0.0", "v1.0.0", "v1.0.0-rc.0"}, "[v1.0.0]: https://example.com/v1.0.0\n"},
+		{PrereleasesKeep, release, []string{"v2.0.0", "v2.0.0-rc.1", "v2.0.0-rc.0", "v1.1.0-rc.0", "v1.0.0", "v1.0.0-rc.0"}, ""},
+		{PrereleasesCollapse, NewRelease(Markdown{"## [v2.0.0-rc.2] - 2024-03-03", ""}), []string{"v2.0.0-rc.2", "v1.1.0-rc.0", "v1.0.0", "v1.0.0-rc.0"}, ""},
+		{PrereleasesKeep, NewRelease(Markdown{"## [v2.0.0-rc.2] - 2024-03-03", ""}), []string{"v2.0.0-rc.2", "v2.0.0-rc.1", "v2.0.0-rc.0", "v1.1.0-rc.0", "v1.0.0", "v1.0.0-rc.0"}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.mode+" "+tc.insert.Tag, func(t *testing.T) {
+			Prereleases = tc.mode
+			d := ParseDocument(prereleases)
+			d.Insert(tc.insert)
+			assert.Equal(t, tc.tags, tags(d))
+			if tc.trailer != "" {
+				assert.Equal(t, tc.trailer, d.Trailer)
+			}
+		})
+	}
+}
+
+func TestPrepend(t *testing.T) {
+	original := Path
+	defer func() { Path = original }()
+	Path = filepath.Join(t.TempDir(), "CHANGELOG.md")
+	version := semver.MustParse("1.1.0")
+	changelog := Markdown{"## [v1.1.0] (2024-02-20)", "- add flag", ""}
+
+	lines, err := Default().Prepend(version, changelog)
+	require.Nil(t, err)
+	assert.Equal(t, Markdown{"# Changelog", "", "## [v1.1.0] (2024-02-20)", "- add flag", ""}, lines)
+
+	require.Nil(t, os.WriteFile(Path, []byte(document), 0644))
+	lines, err = Default().Prepend(version, changelog)
+	require.Nil(t, err)
+	require.Nil(t, WriteToFile(Path, lines))
+	lines, err = Default().Prepend(version, changelog)
+	require.Nil(t, err)
+	require.Nil(t, WriteToFile(Path, lines))
+	content, err := os.ReadFile(Path)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"v1.1.0", "v1.0.0", "0.9.0"}, tags(ParseDocument(string(content))))
+	assert.Contains(t, string(content), "All notable changes are documented here.\n\n---\n\n## [v1.1.0] (2024-02-20)\n- add flag\n\n## v1.0.0")
+}
+
+// tags returns the tags of the releases of the document.
+func tags(d *Document) (tags []string) {
+	for _, r := range d.Releases {
+		tags = append(tags, r.Tag)
+	}
+	return
+}
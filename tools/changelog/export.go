@@ -0,0 +1,205 @@
+package changelog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"github.com/jakbytes/version_actions/tools/semver"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// JSON is whether the releases are exported as JSON alongside the changelog file, see Export and ReleasesFile.
+var JSON = false
+
+// ReleasesFile is the name of the JSON file listing all releases, written next to the changelog file.
+const ReleasesFile = "releases.json"
+
+// SchemaURL is the URL of the published JSON schema of the releases file and of the release documents.
+const SchemaURL = "https://raw.githubusercontent.com/jakbytes/version_actions/main/tools/changelog/releases.schema.json"
+
+// Schema is the JSON schema of the releases file and of the release documents, see SchemaURL.
+//
+//go:embed releases.schema.json
+var Schema string
+
+// JSONReleases is the content of the releases file, the releases newest first.
+type JSONReleases struct {
+	Schema   string        `json:"$schema"`
+	Releases []JSONRelease `json:"releases"`
+}
+
+// JSONRelease is the machine-readable release notes of a version, rendered from the same commits as the changelog.
+type JSONRelease struct {
+	Version         string                  `json:"version"`                    // e.g. 1.2.0
+	Tag             string                  `json:"tag"`                        // e.g. v1.2.0 or api/v1.2.0
+	PreviousVersion string                  `json:"previous_version,omitempty"` // empty for the initial version
+	Date            string                  `json:"date"`                       // e.g. 2024-02-20
+	Increment       string                  `json:"increment"`                  // major, minor, patch or none
+	Prerelease      bool                    `json:"prerelease"`
+	CompareURL      string                  `json:"compare_url,omitempty"`
+	Commits         map[string][]JSONCommit `json:"commits"` // keyed by commit type, or breaking
+}
+
+// JSONCommit is a commit of a JSONRelease.
+type JSONCommit struct {
+	SHA         string       `json:"sha"`
+	Type        string       `json:"type"`
+	Scope       string       `json:"scope,omitempty"`
+	Description string       `json:"description"`
+	Body        string       `json:"body,omitempty"`    // the message after the description, without the footers
+	Footers     []JSONFooter `json:"footers,omitempty"` // e.g. BREAKING CHANGE, Refs or Signed-off-by
+	Breaking    bool         `json:"breaking"`
+	Author      JSONAuthor   `json:"author"`
+}
+
+// JSONFooter is a footer of a commit message, e.g. "Refs: #123" or "Fixes #123".
+type JSONFooter struct {
+	Token string `json:"token"`
+	Value string `json:"value"`
+}
+
+// JSONAuthor is the author of a commit.
+type JSONAuthor struct {
+	Name  string `json:"name"`
+	Login string `json:"login,omitempty"` // GitHub login, empty if the author is not a GitHub user
+}
+
+// footer matches the first line of a footer of a commit message, with the ": " or " #" separator of the conventional
+// commits specification. BREAKING-CHANGE is a synonym of BREAKING CHANGE.
+var footer = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[A-Za-z][A-Za-z0-9-]*)(?:: | #)(.*)$`)
+
+// breaking reports whether the footer is a BREAKING CHANGE or BREAKING-CHANGE footer.
+func (f JSONFooter) breaking() bool {
+	return f.Token == "BREAKING CHANGE" || f.Token == "BREAKING-CHANGE"
+}
+
+// Export returns the machine-readable release notes of the version. Unlike the changelog it lists the commits of all
+// types, including the hidden ones and those whose scope is excluded.
+func (f File) Export(org, repo string, previousVersion, version *semver.Version, commits conventional.Commits) JSONRelease {
+	data := f.Data(org, repo, previousVersion, version, nil, false)
+	release := JSONRelease{
+		Version:    version.String(),
+		Tag:        data.Version,
+		Date:       data.Date.Format("2006-01-02"),
+		Increment:  commits.Increment().String(),
+		Prerelease: data.Prerelease,
+		CompareURL: data.CompareURL,
+		Commits:    make(map[string][]JSONCommit),
+	}
+	if previousVersion != nil {
+		release.PreviousVersion = previousVersion.String()
+	}
+	for name, list := range commits {
+		for _, commit := range list {
+			c := newCommit(data.RepositoryURL, commit)
+			lines := strings.Split(strings.TrimSpace(commit.GetCommit().GetMessage()), "\n")
+			body, footers := splitFooters(lines[1:])
+			release.Commits[name] = append(release.Commits[name], JSONCommit{
+				SHA:         c.SHA,
+				Type:        c.Type,
+				Scope:       c.Scope,
+				Description: c.Description,
+				Body:        strings.TrimSpace(strings.Join(body, "\n")),
+				Footers:     footers,
+				Breaking:    name == conventional.Breaking || len(c.Breaking) > 0 || slices.ContainsFunc(footers, JSONFooter.breaking),
+				Author:      JSONAuthor{Name: c.Author, Login: c.Login},
+			})
+		}
+	}
+	return release
+}
+
+// splitFooters splits the lines of a commit message after the description into the body and the footers, the final
+// paragraph if it starts with a footer as in the conventional commits specification. The lines of a footer up to the
+// next one are its value.
+func splitFooters(lines []string) (body []string, footers []JSONFooter) {
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	start := end
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	if start == end || !footer.MatchString(lines[start]) {
+		return lines, nil
+	}
+
+	for _, line := range lines[start:end] {
+		if match := footer.FindStringSubmatch(line); match != nil {
+			footers = append(footers, JSONFooter{Token: match[1], Value: match[2]})
+		} else {
+			footers[len(footers)-1].Value += "\n" + line
+		}
+	}
+	for i := range footers {
+		footers[i].Value = strings.TrimSpace(footers[i].Value)
+	}
+	return lines[:start], footers
+}
+
+// ExportPath returns the path of the releases file of the changelog file.
+func (f File) ExportPath() string {
+	return filepath.Join(filepath.Dir(f.Path), ReleasesFile)
+}
+
+// AddRelease returns the releases of the releases file with the release added, without writing it. Like the changelog
+// of the version, see Document.Insert, the release replaces the release of the same version and, depending on
+// Prereleases, the prereleases it supersedes, or else is added first.
+func (f File) AddRelease(release JSONRelease) (*JSONReleases, error) {
+	releases := &JSONReleases{}
+	content, err := os.ReadFile(f.ExportPath())
+	if err == nil {
+		if err = json.Unmarshal(content, releases); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", f.ExportPath(), err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	releases.Schema = SchemaURL
+
+	version, err := semver.NewVersion(release.Version)
+	if err != nil {
+		return nil, err
+	}
+	inserted := Release{Tag: release.Tag, Version: version}
+	existing := &Document{}
+	for _, r := range releases.Releases {
+		v, _ := semver.NewVersion(r.Version) // releases without a valid version are kept
+		existing.Releases = append(existing.Releases, Release{Tag: r.Tag, Version: v})
+	}
+	previous := existing.previousRelease(inserted)
+
+	index := -1
+	var kept []JSONRelease
+	for i, r := range releases.Releases {
+		if replaces(inserted, existing.Releases[i], previous) {
+			if index < 0 {
+				index = len(kept)
+			}
+			continue
+		}
+		kept = append(kept, r)
+	}
+	if index < 0 {
+		index = 0
+	}
+	releases.Releases = append(kept[:index:index], append([]JSONRelease{release}, kept[index:]...)...)
+	return releases, nil
+}
+
+// MarshalJSON returns the JSON document of v indented by two spaces, as the lines written to a file.
+func MarshalJSON(v any) (Markdown, error) {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(content), "\n"), nil
+}
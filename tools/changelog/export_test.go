@@ -0,0 +1,252 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"github.com/jakbytes/version_actions/tools/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitFooters(t *testing.T) {
+	testCases := []struct {
+		name    string
+		lines   []string
+		body    []string
+		footers []JSONFooter
+	}{
+		{"no footers", []string{"", "a body", "", "Note that: this is the body"}, []string{"", "a body", "", "Note that: this is the body"}, nil},
+		{"footers", []string{"", "a body", "", "Refs: #123", "Fixes #7", "Signed-off-by: Alice <alice@example.com>"}, []string{"", "a body", ""}, []JSONFooter{
+			{Token: "Refs", Value: "#123"},
+			{Token: "Fixes", Value: "7"},
+			{Token: "Signed-off-by", Value: "Alice <alice@example.com>"},
+		}},
+		{"multi-line footer", []string{"", "BREAKING CHANGE: the flag is removed,", "use the option instead", "Reviewed-by: Bob", ""}, []string{""}, []JSONFooter{
+			{Token: "BREAKING CHANGE", Value: "the flag is removed,\nuse the option instead"},
+			{Token: "Reviewed-by", Value: "Bob"},
+		}},
+		{"hyphenated breaking change", []string{"", "BREAKING-CHANGE: the flag is removed", "Refs: #12"}, []string{""}, []JSONFooter{
+			{Token: "BREAKING-CHANGE", Value: "the flag is removed"},
+			{Token: "Refs", Value: "#12"},
+		}},
+		{"final paragraph", []string{"", "Before: foo()", "After: bar()", "", "Reviewed-by: Bob"}, []string{"", "Before: foo()", "After: bar()", ""}, []JSONFooter{
+			{Token: "Reviewed-by", Value: "Bob"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, footers := splitFooters(tc.lines)
+			assert.Equal(t, tc.body, body)
+			assert.Equal(t, tc.footers, footers)
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	commit := func(sha, message string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{
+			SHA:    github.String(sha),
+			Commit: &github.Commit{Message: github.String(message), Author: &github.CommitAuthor{Name: github.String("Alice")}},
+			Author: &github.User{Login: github.String("alice")},
+		}
+	}
+	commits := conventional.Commits{
+		conventional.Breaking: {commit("1111111111", "feat(api)!: drop the v1 api\n\nThe v1 api is gone.\n\nBREAKING CHANGE: use v2\nRefs: #12")},
+		"chore":               {commit("2222222222", "chore: tidy")},
+		"fix":                 {commit("3333333333", "fix: rename the flag\n\nBREAKING-CHANGE: use --name")},
+	}
+
+	release := File{TagPrefix: "api/"}.Export("owner", "repo", semver.MustParse("1.2.0"), semver.MustParse("2.0.0-rc.0"), commits)
+	assert.Equal(t, JSONRelease{
+		Version:         "2.0.0-rc.0",
+		Tag:             "api/v2.0.0-rc.0",
+		PreviousVersion: "1.2.0",
+		Date:            time.Now().UTC().Format("2006-01-02"),
+		Increment:       "major",
+		Prerelease:      true,
+		CompareURL:      "https://github.com/owner/repo/compare/api/v1.2.0...api/v2.0.0-rc.0",
+		Commits: map[string][]JSONCommit{
+			conventional.Breaking: {{
+				SHA:         "1111111111",
+				Type:        "feat",
+				Scope:       "api",
+				Description: "drop the v1 api",
+				Body:        "The v1 api is gone.",
+				Footers:     []JSONFooter{{Token: "BREAKING CHANGE", Value: "use v2"}, {Token: "Refs", Value: "#12"}},
+				Breaking:    true,
+				Author:      JSONAuthor{Name: "Alice", Login: "alice"},
+			}},
+			"chore": {{SHA: "2222222222", Type: "chore", Description: "tidy", Author: JSONAuthor{Name: "Alice", Login: "alice"}}},
+			"fix": {{
+				SHA:         "3333333333",
+				Type:        "fix",
+				Description: "rename the flag",
+				Footers:     []JSONFooter{{Token: "BREAKING-CHANGE", Value: "use --name"}},
+				Breaking:    true,
+				Author:      JSONAuthor{Name: "Alice", Login: "alice"},
+			}},
+		},
+	}, release)
+}
+
+func TestWriteRelease(t *testing.T) {
+	f := File{Path: filepath.Join(t.TempDir(), "CHANGELOG.md")}
+	require.Equal(t, filepath.Join(filepath.Dir(f.Path), ReleasesFile), f.ExportPath())
+	read := func() (releases JSONReleases) {
+		content, err := os.ReadFile(f.ExportPath())
+		require.Nil(t, err)
+		require.Nil(t, json.Unmarshal(content, &releases))
+		return
+	}
+	versions := func(releases JSONReleases) (versions []string) {
+		for _, r := range releases.Releases {
+			versions = append(versions, r.Version)
+		}
+		return
+	}
+
+	require.Nil(t, f.WriteRelease(JSONRelease{Version: "1.0.0", Tag: "v1.0.0"}))
+	require.Nil(t, f.WriteRelease(JSONRelease{Version: "1.1.0-rc.0", Tag: "v1.1.0-rc.0"}))
+	releases := read()
+	assert.Equal(t, SchemaURL, releases.Schema)
+	assert.Equal(t, []string{"1.1.0-rc.0", "1.0.0"}, versions(releases))
+
+	// the release replaces its prereleases, writing it again leaves the file unchanged
+	require.Nil(t, f.WriteRelease(JSONRelease{Version: "1.1.0", Tag: "v1.1.0"}))
+	content, err := os.ReadFile(f.ExportPath())
+	require.Nil(t, err)
+	require.Nil(t, f.WriteRelease(JSONRelease{Version: "1.1.0", Tag: "v1.1.0"}))
+	again, err := os.ReadFile(f.ExportPath())
+	require.Nil(t, err)
+	assert.Equal(t, string(content), string(again))
+	assert.Equal(t, []string{"1.1.0", "1.0.0"}, versions(read()))
+
+	// a release collapses the prereleases since the previous release version, unless they are kept
+	require.Nil(t, f.WriteRelease(JSONRelease{Version: "1.2.0-rc.0", Tag: "v1.2.0-rc.0"}))
+	require.Nil(t, f.WriteRelease(JSONRelease{Version: "2.0.0-rc.0", Tag: "v2.0.0-rc.0"}))
+	original := Prereleases
+	Prereleases = PrereleasesKeep
+	require.Nil(t, f.WriteRelease(JSONRelease{Version: "2.0.0-rc.1", Tag: "v2.0.0-rc.1"}))
+	Prereleases = original
+	assert.Equal(t, []string{"2.0.0-rc.1", "2.0.0-rc.0", "1.2.0-rc.0", "1.1.0", "1.0.0"}, versions(read()))
+	require.Nil(t, f.WriteRelease(JSONRelease{Version: "2.0.0", Tag: "v2.0.0"}))
+	assert.Equal(t, []string{"2.0.0", "1.1.0", "1.0.0"}, versions(read()))
+
+	require.Nil(t, os.WriteFile(f.ExportPath(), []byte("not json"), 0644))
+	require.ErrorContains(t, f.WriteRelease(JSONRelease{Version: "1.2.0"}), "failed to parse")
+}
+
+func TestWrite_JSON(t *testing.T) {
+	original := JSON
+	defer func() { JSON = original }()
+	JSON = true
+	f := File{Path: filepath.Join(t.TempDir(), "CHANGELOG.md")}
+	commits := conventional.Commits{"feat": {mockCommit("feat: add flag", "Bob", "bob", "2222222222")}}
+
+	_, _, err := f.Write("owner", "repo", nil, semver.MustParse("1.0.0"), commits, false)
+	require.Nil(t, err)
+	content, err := os.ReadFile(f.ExportPath())
+	require.Nil(t, err)
+	var releases JSONReleases
+	require.Nil(t, json.Unmarshal(content, &releases))
+	require.Len(t, releases.Releases, 1)
+	assert.Equal(t, "v1.0.0", releases.Releases[0].Tag)
+	assert.Equal(t, "minor", releases.Releases[0].Increment)
+	assert.Equal(t, "add flag", releases.Releases[0].Commits["feat"][0].Description)
+}
+
+func TestSchema(t *testing.T) {
+	var schema map[string]any
+	require.Nil(t, json.Unmarshal([]byte(Schema), &schema))
+	assert.Equal(t, SchemaURL, schema["$id"])
+
+	commits := conventional.Commits{
+		conventional.Breaking: {mockCommit("feat(api)!: drop the v1 api\n\nThe v1 api is gone.\n\nBREAKING CHANGE: use v2\nRefs: #12", "Alice", "alice", "1111111111")},
+		"fix":                 {mockCommit("fix: typo", "Bob", "", "2222222222")},
+	}
+	f := File{Path: filepath.Join(t.TempDir(), "CHANGELOG.md")}
+	release := f.Export("owner", "repo", semver.MustParse("1.2.0"), semver.MustParse("2.0.0"), commits)
+	require.Nil(t, f.WriteRelease(f.Export("owner", "repo", nil, semver.MustParse("1.2.0"), conventional.Commits{})))
+	releases, err := f.AddRelease(release)
+	require.Nil(t, err)
+	require.Len(t, releases.Releases, 2)
+
+	document := func(v any) (document any) {
+		content, err := json.Marshal(v)
+		require.Nil(t, err)
+		require.Nil(t, json.Unmarshal(content, &document))
+		return
+	}
+	assert.Empty(t, validate(schema, schema, document(releases), "$"))
+	assert.Empty(t, validate(schema, map[string]any{"$ref": "#/$defs/release"}, document(release), "$"))
+
+	// the validation fails for a document that does not match the schema
+	invalid := document(release).(map[string]any)
+	delete(invalid, "tag")
+	invalid["prerelease"] = "no"
+	invalid["increment"] = "huge"
+	assert.ElementsMatch(t, []string{
+		"$: tag is required",
+		"$.prerelease: expected a boolean",
+		`$.increment: "huge" is not one of [major minor patch none]`,
+	}, validate(schema, map[string]any{"$ref": "#/$defs/release"}, invalid, "$"))
+}
+
+// validate returns the violations of the value against the node of the schema, for the keywords that the schema of the
+// releases uses: $ref, type, enum, required, properties, additionalProperties and items.
+func validate(schema, node map[string]any, value any, path string) (violations []string) {
+	if ref, ok := node["$ref"].(string); ok {
+		node = schema["$defs"].(map[string]any)[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+	}
+	if enum, ok := node["enum"].([]any); ok && !slices.Contains(enum, value) {
+		violations = append(violations, fmt.Sprintf("%s: %q is not one of %v", path, value, enum))
+	}
+	switch node["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return append(violations, path+": expected an object")
+		}
+		required, _ := node["required"].([]any)
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: %s is required", path, name))
+			}
+		}
+		properties, _ := node["properties"].(map[string]any)
+		for name, v := range object {
+			if property, ok := properties[name].(map[string]any); ok {
+				violations = append(violations, validate(schema, property, v, path+"."+name)...)
+			} else if additional, ok := node["additionalProperties"].(map[string]any); ok {
+				violations = append(violations, validate(schema, additional, v, path+"."+name)...)
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return append(violations, path+": expected an array")
+		}
+		for i, item := range array {
+			violations = append(violations, validate(schema, node["items"].(map[string]any), item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			violations = append(violations, path+": expected a string")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			violations = append(violations, path+": expected a boolean")
+		}
+	}
+	return violations
+}
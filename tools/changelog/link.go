@@ -0,0 +1,102 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tracker links the references to an external issue tracker in the changelog, e.g. the keys of Jira issues.
+type Tracker struct {
+	Pattern *regexp.Regexp // matches the references, e.g. \bPROJ-\d+\b
+	URL     string         // the link of a reference, $0 is replaced by the reference and $1 or ${name} by its submatches
+}
+
+// LinkOptions configures the references that are linked in the descriptions and bodies of the commits.
+type LinkOptions struct {
+	Issues   bool // link #123 and owner/repo#123 to the GitHub issue or pull request
+	Trackers []Tracker
+}
+
+// Links are the link options the changelog is rendered with.
+var Links = LinkOptions{Issues: true}
+
+var (
+	// issueReference matches a reference to a GitHub issue or pull request, e.g. #123 or owner/repo#123
+	issueReference = regexp.MustCompile(`([A-Za-z0-9][A-Za-z0-9-]*/[A-Za-z0-9._-]+)?#(\d+)\b`)
+	// literal matches the text whose references are not linked: code spans, links, autolinks and URLs
+	literal = regexp.MustCompile("`[^`]*`|!?\\[[^\\]]*\\]\\([^)]*\\)|<[^>\\s]+>|https?://\\S+")
+)
+
+// reference is a reference in a text and the URL it is linked to.
+type reference struct {
+	start, end int
+	url        string
+}
+
+// link returns the text with the references linked, the issue references first and then those of the trackers in
+// order. References in code spans, links and URLs, or overlapping a reference that is linked already, are not linked.
+func (o LinkOptions) link(repositoryURL, text string) string {
+	taken := literal.FindAllStringIndex(text, -1)
+	var references []reference
+	add := func(start, end int, url string) {
+		for _, t := range taken {
+			if start < t[1] && t[0] < end {
+				return
+			}
+		}
+		taken = append(taken, []int{start, end})
+		references = append(references, reference{start, end, url})
+	}
+
+	if o.Issues {
+		for _, m := range issueReference.FindAllStringSubmatchIndex(text, -1) {
+			if m[0] > 0 && (isWordByte(text[m[0]-1]) || strings.IndexByte("&/_", text[m[0]-1]) >= 0) {
+				continue // e.g. an HTML entity &#123; or a part of a word
+			}
+			url := fmt.Sprintf("%s/issues/%s", repositoryURL, text[m[4]:m[5]])
+			if m[2] >= 0 {
+				url = fmt.Sprintf("https://github.com/%s/issues/%s", text[m[2]:m[3]], text[m[4]:m[5]])
+			}
+			add(m[0], m[1], url)
+		}
+	}
+	for _, tracker := range o.Trackers {
+		for _, m := range tracker.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			if m[0] < m[1] {
+				add(m[0], m[1], string(tracker.Pattern.ExpandString(nil, tracker.URL, text, m)))
+			}
+		}
+	}
+	if len(references) == 0 {
+		return text
+	}
+
+	sort.Slice(references, func(i, j int) bool { return references[i].start < references[j].start })
+	var sb strings.Builder
+	last := 0
+	for _, r := range references {
+		sb.WriteString(text[last:r.start])
+		fmt.Fprintf(&sb, "[%s](%s)", text[r.start:r.end], r.url)
+		last = r.end
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// linkCommit links the references in the description, the body and the migration note of the commit.
+func (o LinkOptions) linkCommit(repositoryURL string, c *Commit) {
+	c.Description = o.link(repositoryURL, c.Description)
+	for i := range c.Body {
+		c.Body[i] = o.link(repositoryURL, c.Body[i])
+	}
+	for i := range c.Breaking {
+		c.Breaking[i] = o.link(repositoryURL, c.Breaking[i])
+	}
+}
+
+// isWordByte reports whether the byte is an ASCII letter or digit.
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
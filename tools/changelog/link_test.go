@@ -0,0 +1,61 @@
+package changelog
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLink(t *testing.T) {
+	options := LinkOptions{Issues: true, Trackers: []Tracker{
+		{Pattern: regexp.MustCompile(`\bPROJ-(\d+)\b`), URL: "https://jira.example.com/browse/PROJ-$1"},
+		{Pattern: regexp.MustCompile(`\bGH-(?P<number>\d+)\b`), URL: "https://github.com/owner/repo/issues/${number}"},
+	}}
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"squash merge", "add flag (#123)", "add flag ([#123](https://github.com/owner/repo/issues/123))"},
+		{"footer", "Refs: #12, Closes #45", "Refs: [#12](https://github.com/owner/repo/issues/12), Closes [#45](https://github.com/owner/repo/issues/45)"},
+		{"cross-repository", "fixes other/tool#7", "fixes [other/tool#7](https://github.com/other/tool/issues/7)"},
+		{"tracker", "PROJ-123: add flag", "[PROJ-123](https://jira.example.com/browse/PROJ-123): add flag"},
+		{"named submatch", "see GH-9", "see [GH-9](https://github.com/owner/repo/issues/9)"},
+		{"code span", "use `#123` and #4", "use `#123` and [#4](https://github.com/owner/repo/issues/4)"},
+		{"existing link", "see [#123](https://example.com) and https://example.com/PROJ-1#2", "see [#123](https://example.com) and https://example.com/PROJ-1#2"},
+		{"not a reference", "the C# api, &#123; and issue#5", "the C# api, &#123; and issue#5"},
+		{"no references", "add flag", "add flag"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, options.link("https://github.com/owner/repo", tc.text))
+		})
+	}
+
+	assert.Equal(t, "add flag (#123)", LinkOptions{}.link("https://github.com/owner/repo", "add flag (#123)"))
+}
+
+func TestRender_Links(t *testing.T) {
+	original := Links
+	defer func() { Links = original }()
+	Links = LinkOptions{Issues: true, Trackers: []Tracker{{Pattern: regexp.MustCompile(`\bPROJ-\d+\b`), URL: "https://jira.example.com/browse/$0"}}}
+	commits := conventional.Commits{"fix": {mockCommit("fix: handle the empty input (#123)\n\nPROJ-7 was reported by a user.\n\nCloses #45", "Bob", "bob", "3333333333")}}
+
+	changelog, err := GenerateNewChangelog("owner", "repo", nil, nil, commits, true)
+	require.Nil(t, err)
+	assert.Equal(t, Markdown{
+		"## Changelog",
+		"### Fixes",
+		"",
+		"- ([`3333333`](https://github.com/owner/repo/commit/3333333333)) handle the empty input ([#123](https://github.com/owner/repo/issues/123))",
+		"  > ",
+		"  > [PROJ-7](https://jira.example.com/browse/PROJ-7) was reported by a user.",
+		"  > ",
+		"  > Closes [#45](https://github.com/owner/repo/issues/45)",
+		"",
+	}, changelog)
+}
@@ -0,0 +1,100 @@
+package changelog
+
+import (
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// The layouts of the changelog file, see Style.
+const (
+	StyleDefault        = ""               // a section per commit type, the changelog of a version is inserted on release
+	StyleKeepAChangelog = "keepachangelog" // the layout of https://keepachangelog.com with a maintained Unreleased section
+)
+
+// Styles are the layouts of the changelog file.
+var Styles = []string{StyleDefault, StyleKeepAChangelog}
+
+// Style is the layout the changelog file is written in.
+var Style = StyleDefault
+
+// Unreleased is the tag of the release heading of the changes that are not released yet, e.g. "## [Unreleased]".
+const Unreleased = "Unreleased"
+
+// The ways the pull_request action maintains the Unreleased section of the keepachangelog style.
+const (
+	UnreleasedSummary = "summary" // the section is written to the step summary of the workflow run
+	UnreleasedCommit  = "commit"  // the section is committed to the changelog file on the head branch
+)
+
+// UnreleasedModes are the ways the Unreleased section is maintained.
+var UnreleasedModes = []string{UnreleasedSummary, UnreleasedCommit}
+
+// KeepAChangelogTemplate is the name of the built-in changelog template of the keepachangelog style.
+const KeepAChangelogTemplate = "keepachangelog"
+
+// Categories are the Keep a Changelog categories, in the order they are listed in the changelog.
+var Categories = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// TypeCategories are the categories of the commit types in the keepachangelog style, keyed by the commit type or
+// breaking. The commits of types without a category are left out of the changelog, they still increment the version.
+var TypeCategories = DefaultTypeCategories()
+
+// DefaultTypeCategories returns the categories of the commit types when no categories are configured.
+func DefaultTypeCategories() map[string]string {
+	return map[string]string{
+		conventional.Breaking:     "Changed",
+		"feat":                    "Added",
+		"fix":                     "Fixed",
+		"perf":                    "Changed",
+		conventional.Reverts:      "Removed",
+		conventional.Dependencies: "Changed",
+	}
+}
+
+// StyleTemplate returns the built-in changelog template of the style.
+func StyleTemplate(style string) *template.Template {
+	name := ChangelogTemplate
+	if style == StyleKeepAChangelog {
+		name = KeepAChangelogTemplate
+	}
+	return template.Must(template.New(ChangelogTemplate).Parse(mustReadTemplate(name)))
+}
+
+// preamble returns the content of a new changelog file before the first release.
+func preamble() string {
+	if Style == StyleKeepAChangelog {
+		return "# Changelog\n\nAll notable changes to this project will be documented in this file.\n\n" +
+			"The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),\n" +
+			"and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).\n\n"
+	}
+	return "# Changelog\n\n"
+}
+
+// categorize merges the sections of the commit types into the sections of their categories, ordered like Categories.
+// The commits of breaking changes are marked with BreakingChange.
+func categorize(sections []Section) (categorized []Section) {
+	for _, category := range Categories {
+		s := Section{Type: strings.ToLower(category), Title: category}
+		for _, section := range sections {
+			if TypeCategories[section.Type] != category {
+				continue
+			}
+			for _, commit := range section.Commits {
+				commit.BreakingChange = section.Type == conventional.Breaking
+				s.Commits = append(s.Commits, commit)
+			}
+		}
+		if len(s.Commits) > 0 {
+			s.Groups = groups(s.Commits)
+			categorized = append(categorized, s)
+		}
+	}
+	return
+}
+
+// IsCategory reports whether the name is one of the Categories.
+func IsCategory(name string) bool {
+	return slices.Contains(Categories, name)
+}
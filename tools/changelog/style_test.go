@@ -0,0 +1,125 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"github.com/jakbytes/version_actions/tools/semver"
+	"github.com/stretchr/testify/require"
+)
+
+// keepAChangelog sets the keepachangelog style for the test.
+func keepAChangelog(t *testing.T) {
+	style, tmpl := Style, Templates[ChangelogTemplate]
+	t.Cleanup(func() { Style, Templates[ChangelogTemplate] = style, tmpl })
+	Style, Templates[ChangelogTemplate] = StyleKeepAChangelog, StyleTemplate(StyleKeepAChangelog)
+}
+
+func keepAChangelogCommits() conventional.Commits {
+	return conventional.Commits{
+		conventional.Breaking: {mockCommit("feat!: drop the v1 api", "Alice", "alice", "1111111111")},
+		"feat":                {mockCommit("feat: add flag", "Bob", "bob", "2222222222")},
+		"fix":                 {mockCommit("fix: bug", "Charlie", "charlie", "3333333333")},
+		"perf":                {mockCommit("perf: cache", "Alice", "alice", "4444444444")},
+		"docs":                {mockCommit("docs: readme", "Bob", "bob", "5555555555")},
+	}
+}
+
+func TestRender_KeepAChangelog(t *testing.T) {
+	keepAChangelog(t)
+	today := time.Now().UTC().Format("2006-01-02")
+	commits := keepAChangelogCommits()
+	sections := []string{
+		"### Added",
+		"",
+		"- ([`2222222`](https://github.com/owner/repo/commit/2222222222)) add flag",
+		"",
+		"### Changed",
+		"",
+		"- ([`1111111`](https://github.com/owner/repo/commit/1111111111)) **BREAKING:** drop the v1 api",
+		"- ([`4444444`](https://github.com/owner/repo/commit/4444444444)) cache",
+		"",
+		"### Fixed",
+		"",
+		"- ([`3333333`](https://github.com/owner/repo/commit/3333333333)) bug",
+		"",
+	}
+
+	version, err := Render(ChangelogTemplate, Default().Data("owner", "repo", semver.MustParse("1.0.0"), semver.MustParse("2.0.0"), commits, false))
+	require.Nil(t, err)
+	require.Equal(t, append(append(Markdown{"## [v2.0.0] - " + today, ""}, sections...),
+		"[Unreleased]: https://github.com/owner/repo/compare/v2.0.0...HEAD",
+		"[v2.0.0]: https://github.com/owner/repo/compare/v1.0.0...v2.0.0",
+	), version)
+
+	unreleased, err := Render(ChangelogTemplate, Default().UnreleasedData("owner", "repo", semver.MustParse("1.0.0"), commits))
+	require.Nil(t, err)
+	require.Equal(t, append(append(Markdown{"## [Unreleased]", ""}, sections...),
+		"[Unreleased]: https://github.com/owner/repo/compare/v1.0.0...HEAD",
+	), unreleased)
+
+	pullRequest, err := Render(ChangelogTemplate, Default().Data("owner", "repo", nil, nil, commits, true))
+	require.Nil(t, err)
+	require.Equal(t, append(Markdown{"## Changelog", ""}, sections...), pullRequest)
+}
+
+func TestUnreleased_Prepend_KeepAChangelog(t *testing.T) {
+	keepAChangelog(t)
+	original := Path
+	defer func() { Path = original }()
+	Path = filepath.Join(t.TempDir(), "CHANGELOG.md")
+	today := time.Now().UTC().Format("2006-01-02")
+	require.Nil(t, os.WriteFile(Path, []byte("# Changelog\n\nNotes.\n\n## [Unreleased]\n\n## [v1.0.0] - 2024-01-01\n\n### Added\n\n- initial\n\n[Unreleased]: https://github.com/owner/repo/compare/v1.0.0...HEAD\n[v1.0.0]: https://github.com/owner/repo/releases/tag/v1.0.0\n"), 0644))
+	commits := conventional.Commits{"feat": {mockCommit("feat: add flag", "Bob", "bob", "2222222222")}}
+
+	// the pull_request flow keeps the Unreleased section current
+	unreleased, err := Render(ChangelogTemplate, Default().UnreleasedData("owner", "repo", semver.MustParse("1.0.0"), commits))
+	require.Nil(t, err)
+	existing, err := os.ReadFile(Path)
+	require.Nil(t, err)
+	lines := MergeUnreleased(string(existing), unreleased)
+	require.Equal(t, "# Changelog\n\nNotes.\n\n"+
+		"## [Unreleased]\n\n### Added\n\n- ([`2222222`](https://github.com/owner/repo/commit/2222222222)) add flag\n\n"+
+		"## [v1.0.0] - 2024-01-01\n\n### Added\n\n- initial\n\n"+
+		"[Unreleased]: https://github.com/owner/repo/compare/v1.0.0...HEAD\n"+
+		"[v1.0.0]: https://github.com/owner/repo/releases/tag/v1.0.0", lines.String())
+	require.Nil(t, WriteToFile(Path, lines))
+
+	// releasing converts the Unreleased section into the version
+	version, err := Render(ChangelogTemplate, Default().Data("owner", "repo", semver.MustParse("1.0.0"), semver.MustParse("1.1.0"), commits, false))
+	require.Nil(t, err)
+	lines, err = Default().Prepend(semver.MustParse("1.1.0"), version)
+	require.Nil(t, err)
+	require.Equal(t, "# Changelog\n\nNotes.\n\n"+
+		"## [Unreleased]\n\n"+
+		"## [v1.1.0] - "+today+"\n\n### Added\n\n- ([`2222222`](https://github.com/owner/repo/commit/2222222222)) add flag\n\n"+
+		"## [v1.0.0] - 2024-01-01\n\n### Added\n\n- initial\n\n"+
+		"[Unreleased]: https://github.com/owner/repo/compare/v1.1.0...HEAD\n"+
+		"[v1.1.0]: https://github.com/owner/repo/compare/v1.0.0...v1.1.0\n"+
+		"[v1.0.0]: https://github.com/owner/repo/releases/tag/v1.0.0", lines.String())
+}
+
+func TestPrepend_KeepAChangelog_NewFile(t *testing.T) {
+	keepAChangelog(t)
+	original := Path
+	defer func() { Path = original }()
+	Path = filepath.Join(t.TempDir(), "CHANGELOG.md")
+
+	commits := conventional.Commits{"fix": {&github.RepositoryCommit{
+		SHA:    github.String("3333333333"),
+		Commit: &github.Commit{Message: github.String("fix: bug")},
+	}}}
+	version, err := Render(ChangelogTemplate, Default().Data("owner", "repo", nil, semver.MustParse("1.0.0"), commits, false))
+	require.Nil(t, err)
+	lines, err := Default().Prepend(semver.MustParse("1.0.0"), version)
+	require.Nil(t, err)
+	document := ParseDocument(lines.String() + "\n")
+	require.Equal(t, preamble(), document.Preamble)
+	require.Equal(t, []string{"Unreleased", "v1.0.0"}, tags(document))
+	require.Equal(t, "https://github.com/owner/repo/releases/tag/v1.0.0", document.Releases[1].CompareURL)
+	require.Equal(t, "https://github.com/owner/repo/compare/v1.0.0...HEAD", document.Releases[0].CompareURL)
+}
@@ -0,0 +1,264 @@
+package changelog
+
+import (
+	"embed"
+	"fmt"
+	"github.com/google/go-github/v58/github"
+	"github.com/jakbytes/version_actions/internal/utility"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"github.com/jakbytes/version_actions/tools/semver"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// The names of the templates the changelog, pull request bodies and release notes are rendered with.
+const (
+	ChangelogTemplate          = "changelog"            // the changelog of a version, written to the changelog file
+	PullRequestTemplate        = "pull_request"         // the body of a pull request opened by the pull_request action
+	ReleasePullRequestTemplate = "release_pull_request" // the body of a release pull request
+	ReleaseNotesTemplate       = "release_notes"        // the notes of a release, and the release.txt file
+)
+
+//go:embed templates/*.md.tmpl
+var defaults embed.FS
+
+// Templates are the templates keyed by name, the built-in templates unless they are replaced, see ParseTemplate.
+var Templates = DefaultTemplates()
+
+// DefaultTemplates returns the built-in templates keyed by name.
+func DefaultTemplates() map[string]*template.Template {
+	templates := make(map[string]*template.Template)
+	for _, name := range []string{ChangelogTemplate, PullRequestTemplate, ReleasePullRequestTemplate, ReleaseNotesTemplate} {
+		templates[name] = template.Must(template.New(name).Parse(mustReadTemplate(name)))
+	}
+	return templates
+}
+
+// mustReadTemplate returns the content of the built-in template file with the name.
+func mustReadTemplate(name string) string {
+	content, err := defaults.ReadFile("templates/" + name + ".md.tmpl")
+	if err != nil {
+		panic(err)
+	}
+	return string(content)
+}
+
+// ParseTemplate parses the template file at path as the template with the name.
+func ParseTemplate(name string, path string) (tmpl *template.Template, err error) {
+	err = utility.Open(path, func(file *os.File) error {
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		tmpl, err = template.New(name).Parse(string(content))
+		return err
+	})
+	return
+}
+
+// The modes of rendering the scopes of the commits in the changelog, see ScopeOptions.
+const (
+	ScopeNone   = ""       // the scope is not rendered
+	ScopePrefix = "prefix" // the scope label is a bold prefix of the description
+	ScopeGroup  = "group"  // the commits of a section are grouped by scope under the scope label
+)
+
+// ScopeModes are the modes of rendering scopes.
+var ScopeModes = []string{ScopeNone, ScopePrefix, ScopeGroup}
+
+// ScopeOptions configures how the scopes of the commits are rendered in the changelog and which are included. Include
+// and Exclude only select the commits listed in the changelog: the commits left out still increment the version, and
+// breaking changes are listed regardless of their scope.
+type ScopeOptions struct {
+	Mode    string            // one of ScopeModes
+	Labels  map[string]string // human-readable labels keyed by scope, scopes without a label are labeled by the scope
+	Include []string          // only the commits with these scopes, or without a scope, are included if set
+	Exclude []string          // the commits with these scopes are left out
+}
+
+// Scopes are the scope options the changelog is rendered with.
+var Scopes = ScopeOptions{}
+
+// includes reports whether commits with the scope are included in the changelog.
+func (o ScopeOptions) includes(scope string) bool {
+	if scope == "" {
+		return true
+	}
+	return (len(o.Include) == 0 || slices.Contains(o.Include, scope)) && !slices.Contains(o.Exclude, scope)
+}
+
+// label returns the label of the scope.
+func (o ScopeOptions) label(scope string) string {
+	if label, ok := o.Labels[scope]; ok {
+		return label
+	}
+	return scope
+}
+
+// Data is the data the templates are executed with.
+type Data struct {
+	Owner           string    // owner of the repository
+	Repository      string    // name of the repository
+	RepositoryURL   string    // e.g. https://github.com/owner/repository
+	Version         string    // tag of the version, e.g. v1.2.0, empty for the changelog of a pull request
+	PreviousVersion string    // tag of the previous version, empty for the initial version
+	Prerelease      bool      // whether the version is a prerelease version
+	Date            time.Time // the date the changelog is generated, in UTC
+	CompareURL      string    // the URL comparing the previous version to the version, or to HEAD if Unreleased; empty for the initial version
+	ReleaseAs       string    // what set the version when it was not computed from the commits, e.g. a Release-As footer
+	Unreleased      bool      // whether the changelog is of the Unreleased section of the keepachangelog style
+	ScopeMode       string    // how scopes are rendered, see ScopeModes
+	Sections        []Section // the changelog sections, ordered and without the hidden types
+	Changelog       string    // the rendered changelog of the version, set for all templates except the changelog template
+}
+
+// Section is a changelog section of the template data.
+type Section struct {
+	Type    string   // the commit type, or breaking
+	Title   string   // the title of the section
+	Commits []Commit // the commits, newest first
+	Groups  []Group  // the commits grouped by scope, the commits without a scope first and the others by label
+}
+
+// Group is the commits of a section with the same scope.
+type Group struct {
+	Scope   string   // the scope, empty for the commits without a scope
+	Label   string   // the label of the scope
+	Commits []Commit // the commits, newest first
+}
+
+// Commit is a commit of the template data.
+type Commit struct {
+	SHA         string
+	ShortSHA    string   // the first 7 characters of the SHA
+	Type        string   // the conventional commit type, e.g. feat
+	Scope       string   // the conventional commit scope, empty if there is none
+	ScopeLabel  string   // the label of the scope prefixing the description, set if the ScopeMode is prefix
+	Description string   // the first line of the commit message after the type and scope
+	Body        []string // the lines of the commit message after the description, without the Trailers and the BREAKING CHANGE footer
+	Breaking    []string // the lines of the BREAKING CHANGE footer, the migration note of a breaking change
+	Author      string   // name of the author
+	Login       string   // GitHub login of the author, empty if the author is not a GitHub user
+	URL         string   // URL of the commit
+	// BreakingChange is set for the breaking changes in the keepachangelog style, where they are listed by category
+	BreakingChange bool
+	// Dependencies are the dependency updates of a commit of the dependencies section, each with .Name, .From, .To,
+	// .Type and .Update, empty for the commits of other sections and for updates the packages were not parsed of
+	Dependencies []conventional.Dependency
+}
+
+// Data returns the template data for the changelog of the version. The version is omitted if disableVersionHeader is
+// set.
+func (f File) Data(org, repo string, previousVersion, version *semver.Version, commits conventional.Commits, disableVersionHeader bool) Data {
+	data := Data{
+		Owner:         org,
+		Repository:    repo,
+		RepositoryURL: fmt.Sprintf("https://github.com/%s/%s", org, repo),
+		Date:          time.Now().UTC(),
+	}
+	if version != nil && !disableVersionHeader {
+		data.Version = f.Tag(version)
+		data.Prerelease = version.IsPrerelease()
+		if previousVersion != nil {
+			data.PreviousVersion = f.Tag(previousVersion)
+			data.CompareURL = fmt.Sprintf("%s/compare/%s...%s", data.RepositoryURL, data.PreviousVersion, data.Version)
+		}
+	}
+	data.ScopeMode = Scopes.Mode
+	for _, section := range commits.Sections() {
+		s := Section{Type: section.Name, Title: section.Title}
+		for _, commit := range section.Commits {
+			c := newCommit(data.RepositoryURL, commit)
+			if section.Name != conventional.Breaking && !Scopes.includes(c.Scope) {
+				continue
+			}
+			if section.Name == conventional.Dependencies {
+				c.Dependencies = conventional.ParseDependencies(commit.GetCommit().GetMessage())
+			}
+			Links.linkCommit(data.RepositoryURL, &c)
+			if c.Scope != "" && Scopes.Mode == ScopePrefix {
+				c.ScopeLabel = Scopes.label(c.Scope)
+			}
+			s.Commits = append(s.Commits, c)
+		}
+		if len(s.Commits) == 0 { // all commits of the section are excluded by scope
+			continue
+		}
+		s.Groups = groups(s.Commits)
+		data.Sections = append(data.Sections, s)
+	}
+	if Style == StyleKeepAChangelog {
+		data.Sections = categorize(data.Sections)
+	}
+	return data
+}
+
+// UnreleasedData returns the template data for the Unreleased section of the keepachangelog style, the changes since
+// the previous version.
+func (f File) UnreleasedData(org, repo string, previousVersion *semver.Version, commits conventional.Commits) Data {
+	data := f.Data(org, repo, nil, nil, commits, true)
+	data.Unreleased = true
+	if previousVersion != nil {
+		data.PreviousVersion = f.Tag(previousVersion)
+		data.CompareURL = fmt.Sprintf("%s/compare/%s...HEAD", data.RepositoryURL, data.PreviousVersion)
+	}
+	return data
+}
+
+// groups groups the commits by scope, the commits without a scope first and the others ordered by label.
+func groups(commits []Commit) (groups []Group) {
+	for _, commit := range commits {
+		index := slices.IndexFunc(groups, func(g Group) bool { return g.Scope == commit.Scope })
+		if index == -1 {
+			groups = append(groups, Group{Scope: commit.Scope, Label: Scopes.label(commit.Scope)})
+			index = len(groups) - 1
+		}
+		groups[index].Commits = append(groups[index].Commits, commit)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Scope == "" || groups[j].Scope == "" {
+			return groups[i].Scope == ""
+		}
+		return groups[i].Label < groups[j].Label
+	})
+	return groups
+}
+
+func newCommit(repositoryURL string, commit *github.RepositoryCommit) Commit {
+	header, message, _ := strings.Cut(conventional.NormalizeRevert(strings.TrimSpace(commit.GetCommit().GetMessage())), ":")
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	c := Commit{
+		SHA:         commit.GetSHA(),
+		ShortSHA:    commit.GetSHA()[:min(7, len(commit.GetSHA()))],
+		Type:        strings.TrimSuffix(header, "!"),
+		Description: lines[0],
+		Author:      commit.GetCommit().GetAuthor().GetName(),
+		Login:       commit.GetAuthor().GetLogin(),
+		URL:         fmt.Sprintf("%s/commit/%s", repositoryURL, commit.GetSHA()),
+	}
+	if t, scope, ok := strings.Cut(c.Type, "("); ok {
+		c.Type, c.Scope = t, strings.TrimSuffix(scope, ")")
+	}
+	c.Body, c.Breaking = splitBody(lines[1:])
+	return c
+}
+
+// Render executes the template with the name and returns the result as Markdown lines.
+func Render(name string, data Data) (Markdown, error) {
+	var sb strings.Builder
+	if err := Templates[name].Execute(&sb, data); err != nil {
+		return nil, fmt.Errorf("failed to render the %s template: %w", name, err)
+	}
+	return strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n"), nil
+}
+
+// RenderWith renders the template with the name for the rendered changelog of the data.
+func RenderWith(name string, data Data, changelog Markdown) (Markdown, error) {
+	data.Changelog = changelog.String()
+	return Render(name, data)
+}
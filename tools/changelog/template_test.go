@@ -0,0 +1,309 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"github.com/jakbytes/version_actions/tools/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestData(t *testing.T) {
+	commit := mockCommit("feat(api)!: new endpoint\n\nWith details.", "Alice", "alice", "0123456789abcdef")
+	commit.Commit.Author = &github.CommitAuthor{Name: github.String("Alice Author")}
+
+	data := File{TagPrefix: "api/"}.Data("owner", "repo", semver.MustParse("1.0.0"), semver.MustParse("2.0.0-rc.0"), conventional.Commits{conventional.Breaking: {commit}}, false)
+	assert.Equal(t, "https://github.com/owner/repo", data.RepositoryURL)
+	assert.Equal(t, "api/v2.0.0-rc.0", data.Version)
+	assert.Equal(t, "api/v1.0.0", data.PreviousVersion)
+	assert.True(t, data.Prerelease)
+	assert.Equal(t, "https://github.com/owner/repo/compare/api/v1.0.0...api/v2.0.0-rc.0", data.CompareURL)
+	require.Len(t, data.Sections, 1)
+	assert.Equal(t, "breaking", data.Sections[0].Type)
+	assert.Equal(t, "⚠ BREAKING CHANGES", data.Sections[0].Title)
+	assert.Equal(t, []Commit{{
+		SHA:         "0123456789abcdef",
+		ShortSHA:    "0123456",
+		Type:        "feat",
+		Scope:       "api",
+		Description: "new endpoint",
+		Body:        []string{"", "With details."},
+		Author:      "Alice Author",
+		Login:       "alice",
+		URL:         "https://github.com/owner/repo/commit/0123456789abcdef",
+	}}, data.Sections[0].Commits)
+
+	data = Default().Data("owner", "repo", semver.MustParse("1.0.0"), semver.MustParse("2.0.0"), conventional.Commits{}, true)
+	assert.Empty(t, data.Version)
+	assert.Empty(t, data.PreviousVersion)
+	assert.Empty(t, data.CompareURL)
+}
+
+func TestRender_CustomTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changelog.md.tmpl")
+	require.Nil(t, os.WriteFile(path, []byte(`# {{ .Version }}
+{{ range .Sections }}{{ range .Commits }}* {{ with .Scope }}**{{ . }}:** {{ end }}{{ .Description }} (@{{ .Login }})
+{{ end }}{{ end }}`), 0644))
+	tmpl, err := ParseTemplate(ChangelogTemplate, path)
+	require.Nil(t, err)
+	original := Templates[ChangelogTemplate]
+	Templates[ChangelogTemplate] = tmpl
+	defer func() { Templates[ChangelogTemplate] = original }()
+
+	changelog, err := GenerateNewChangelog("owner", "repo", nil, semver.MustParse("1.1.0"), conventional.Commits{
+		"feat": {mockCommit("feat(cli): add flag", "Bob", "bob", "def5678def5678")},
+		"fix":  {mockCommit("fix: bug", "Charlie", "charlie", "abc1234abc1234")},
+	}, false)
+	require.Nil(t, err)
+	require.Equal(t, Markdown{"# v1.1.0", "* **cli:** add flag (@bob)", "* bug (@charlie)"}, changelog)
+}
+
+func TestRender_ReleaseAs(t *testing.T) {
+	data := Default().Data("owner", "repo", nil, semver.MustParse("3.0.0"), conventional.Commits{
+		"fix": {mockCommit("fix: bug", "Charlie", "charlie", "abc1234abc1234")},
+	}, false)
+	data.ReleaseAs = "the release-as input"
+
+	changelog, err := Render(ChangelogTemplate, data)
+	require.Nil(t, err)
+	require.Equal(t, "> The version was set by the release-as input.", changelog[1])
+	require.Equal(t, "", changelog[2])
+	require.Equal(t, "### Fixes", changelog[3])
+}
+
+func TestRender_Dependencies(t *testing.T) {
+	changelog, err := GenerateNewChangelog("owner", "repo", nil, semver.MustParse("1.1.0"), conventional.Commits{
+		"fix": {mockCommit("fix: bug", "Charlie", "charlie", "abc1234abc1234")},
+		conventional.Dependencies: {
+			mockCommit("chore(deps): bump the go group with 2 updates\n\nUpdates `a` from 1.0.0 to 1.1.0\nUpdates `b` from 2.0.0 to 2.0.1\n\n---\nupdated-dependencies:\n- dependency-name: a\n- dependency-name: b\n...", "Bot", "bot", "def5678def5678"),
+			mockCommit("chore(deps): tidy go.mod", "Bob", "bob", "0123456789abcdef"),
+		},
+	}, false)
+	require.Nil(t, err)
+	require.Equal(t, Markdown{
+		"## [v1.1.0] Initial Version (" + time.Now().UTC().Format("2006-01-02") + ")",
+		"### Fixes",
+		"",
+		"- ([`abc1234`](https://github.com/owner/repo/commit/abc1234abc1234)) bug",
+		"",
+		"### Dependencies",
+		"",
+		"- ([`def5678`](https://github.com/owner/repo/commit/def5678def5678)) `a` from 1.0.0 to 1.1.0",
+		"- ([`def5678`](https://github.com/owner/repo/commit/def5678def5678)) `b` from 2.0.0 to 2.0.1",
+		"- ([`0123456`](https://github.com/owner/repo/commit/0123456789abcdef)) tidy go.mod",
+		"",
+	}, changelog)
+}
+
+func TestRender_Scopes(t *testing.T) {
+	commits := conventional.Commits{
+		"feat": {
+			mockCommit("feat(cli): add flag", "Bob", "bob", "def5678def5678"),
+			mockCommit("feat: unscoped", "Bob", "bob", "1111111111"),
+			mockCommit("feat(api): add endpoint", "Alice", "alice", "0123456789abcdef"),
+			mockCommit("feat(internal): refactor cache", "Alice", "alice", "2222222222"),
+		},
+		"fix": {mockCommit("fix(internal): typo", "Charlie", "charlie", "abc1234abc1234")},
+	}
+	heading := "## [v1.1.0] Initial Version (" + time.Now().UTC().Format("2006-01-02") + ")"
+	testCases := []struct {
+		name   string
+		scopes ScopeOptions
+		want   Markdown
+	}{
+		{"none", ScopeOptions{Exclude: []string{"internal"}}, Markdown{
+			heading,
+			"### Features",
+			"",
+			"- ([`def5678`](https://github.com/owner/repo/commit/def5678def5678)) add flag",
+			"- ([`1111111`](https://github.com/owner/repo/commit/1111111111)) unscoped",
+			"- ([`0123456`](https://github.com/owner/repo/commit/0123456789abcdef)) add endpoint",
+			"",
+		}},
+		{"prefix", ScopeOptions{Mode: ScopePrefix, Labels: map[string]string{"cli": "Command Line"}, Include: []string{"api", "cli"}}, Markdown{
+			heading,
+			"### Features",
+			"",
+			"- ([`def5678`](https://github.com/owner/repo/commit/def5678def5678)) **Command Line:** add flag",
+			"- ([`1111111`](https://github.com/owner/repo/commit/1111111111)) unscoped",
+			"- ([`0123456`](https://github.com/owner/repo/commit/0123456789abcdef)) **api:** add endpoint",
+			"",
+		}},
+		{"group", ScopeOptions{Mode: ScopeGroup, Labels: map[string]string{"api": "API", "cli": "Command Line", "internal": "Internals"}}, Markdown{
+			heading,
+			"### Features",
+			"",
+			"- ([`1111111`](https://github.com/owner/repo/commit/1111111111)) unscoped",
+			"",
+			"#### API",
+			"",
+			"- ([`0123456`](https://github.com/owner/repo/commit/0123456789abcdef)) add endpoint",
+			"",
+			"#### Command Line",
+			"",
+			"- ([`def5678`](https://github.com/owner/repo/commit/def5678def5678)) add flag",
+			"",
+			"#### Internals",
+			"",
+			"- ([`2222222`](https://github.com/owner/repo/commit/2222222222)) refactor cache",
+			"",
+			"### Fixes",
+			"",
+			"#### Internals",
+			"",
+			"- ([`abc1234`](https://github.com/owner/repo/commit/abc1234abc1234)) typo",
+			"",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			Scopes = tc.scopes
+			defer func() { Scopes = ScopeOptions{} }()
+
+			changelog, err := GenerateNewChangelog("owner", "repo", nil, semver.MustParse("1.1.0"), commits, false)
+			require.Nil(t, err)
+			require.Equal(t, tc.want, changelog)
+		})
+	}
+}
+
+func TestRender_Scopes_Excluded(t *testing.T) {
+	Scopes = ScopeOptions{Exclude: []string{"internal"}}
+	defer func() { Scopes = ScopeOptions{} }()
+	heading := "## [v2.0.0] Initial Version (" + time.Now().UTC().Format("2006-01-02") + ")"
+
+	// the excluded commits still increment the version, and breaking changes are always listed
+	commits := conventional.Commits{"fix": {mockCommit("fix(internal): typo", "Charlie", "charlie", "abc1234abc1234")}}
+	require.Equal(t, conventional.Patch, commits.Increment())
+	changelog, err := GenerateNewChangelog("owner", "repo", nil, semver.MustParse("1.0.1"), commits, true)
+	require.Nil(t, err)
+	require.Equal(t, Markdown{"## Changelog"}, changelog)
+
+	commits[conventional.Breaking] = []*github.RepositoryCommit{mockCommit("feat(internal)!: drop the cache", "Alice", "alice", "2222222222")}
+	require.Equal(t, conventional.Major, commits.Increment())
+	changelog, err = GenerateNewChangelog("owner", "repo", nil, semver.MustParse("2.0.0"), commits, false)
+	require.Nil(t, err)
+	require.Equal(t, Markdown{
+		heading,
+		"### ⚠ BREAKING CHANGES",
+		"",
+		"- ([`2222222`](https://github.com/owner/repo/commit/2222222222)) drop the cache",
+		"",
+	}, changelog)
+}
+
+func TestRender_Trailers(t *testing.T) {
+	original := Trailers
+	defer func() { Trailers = original }()
+	commits := conventional.Commits{
+		"fix":                 {mockCommit("fix: bug\n\nThe cache was not cleared.\n\nReviewed-by: Bob <bob@example.com>\nSigned-off-by: Alice <alice@example.com>", "Alice", "alice", "abc1234abc1234")},
+		"chore":               {mockCommit("chore(deps): bump a from 1.0.0 to 1.1.0\n\n---\nupdated-dependencies:\n- dependency-name: a\n...\n\nSigned-off-by: dependabot[bot] <support@github.com>", "Bot", "bot", "def5678def5678")},
+		conventional.Breaking: {mockCommit("feat!: drop v1 endpoints\n\nThe v1 endpoints were deprecated.\n\nBREAKING CHANGE: the v1 endpoints are removed,\ncall the v2 endpoints instead.\nCo-authored-by: Bob <bob@example.com>", "Alice", "alice", "0123456789abcdef")},
+	}
+	heading := "## [v2.0.0] Initial Version (" + time.Now().UTC().Format("2006-01-02") + ")"
+	testCases := []struct {
+		name     string
+		trailers []string
+		want     Markdown
+	}{
+		{"default", DefaultTrailers(), Markdown{
+			heading,
+			"### ⚠ BREAKING CHANGES",
+			"",
+			"- ([`0123456`](https://github.com/owner/repo/commit/0123456789abcdef)) drop v1 endpoints",
+			"  > the v1 endpoints are removed,",
+			"  > call the v2 endpoints instead.",
+			"",
+			"### Fixes",
+			"",
+			"- ([`abc1234`](https://github.com/owner/repo/commit/abc1234abc1234)) bug",
+			"  > ",
+			"  > The cache was not cleared.",
+			"",
+			"### Chores",
+			"",
+			"- ([`def5678`](https://github.com/owner/repo/commit/def5678def5678)) bump a from 1.0.0 to 1.1.0",
+			"",
+		}},
+		{"none", []string{}, Markdown{
+			heading,
+			"### ⚠ BREAKING CHANGES",
+			"",
+			"- ([`0123456`](https://github.com/owner/repo/commit/0123456789abcdef)) drop v1 endpoints",
+			"  > the v1 endpoints are removed,",
+			"  > call the v2 endpoints instead.",
+			"",
+			"### Fixes",
+			"",
+			"- ([`abc1234`](https://github.com/owner/repo/commit/abc1234abc1234)) bug",
+			"  > ",
+			"  > The cache was not cleared.",
+			"  > ",
+			"  > Reviewed-by: Bob <bob@example.com>",
+			"  > Signed-off-by: Alice <alice@example.com>",
+			"",
+			"### Chores",
+			"",
+			"- ([`def5678`](https://github.com/owner/repo/commit/def5678def5678)) bump a from 1.0.0 to 1.1.0",
+			"  > ",
+			"  > ---",
+			"  > updated-dependencies:",
+			"  > - dependency-name: a",
+			"  > ...",
+			"  > ",
+			"  > Signed-off-by: dependabot[bot] <support@github.com>",
+			"",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			Trailers = tc.trailers
+			changelog, err := GenerateNewChangelog("owner", "repo", nil, semver.MustParse("2.0.0"), commits, false)
+			require.Nil(t, err)
+			require.Equal(t, tc.want, changelog)
+		})
+	}
+}
+
+func TestRender_Error(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "release_notes.md.tmpl")
+	require.Nil(t, os.WriteFile(path, []byte(`{{ .Missing }}`), 0644))
+	tmpl, err := ParseTemplate(ReleaseNotesTemplate, path)
+	require.Nil(t, err)
+	original := Templates[ReleaseNotesTemplate]
+	Templates[ReleaseNotesTemplate] = tmpl
+	defer func() { Templates[ReleaseNotesTemplate] = original }()
+
+	_, err = Render(ReleaseNotesTemplate, Data{})
+	require.ErrorContains(t, err, "failed to render the release_notes template")
+}
+
+func TestParseTemplate_Error(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changelog.md.tmpl")
+	require.Nil(t, os.WriteFile(path, []byte(`{{ range .Sections }}`), 0644))
+	_, err := ParseTemplate(ChangelogTemplate, path)
+	require.ErrorContains(t, err, "unexpected EOF")
+
+	_, err = ParseTemplate(ChangelogTemplate, filepath.Join(t.TempDir(), "missing.tmpl"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRenderWith(t *testing.T) {
+	changelog := Markdown{"## [v1.0.0] Initial Version (2024-01-01)", "### Fixes", "", "- fix", ""}
+	notes, err := RenderWith(ReleaseNotesTemplate, Data{}, changelog)
+	require.Nil(t, err)
+	require.Equal(t, changelog, notes)
+
+	body, err := RenderWith(ReleasePullRequestTemplate, Data{Prerelease: true}, changelog)
+	require.Nil(t, err)
+	require.Equal(t, "### :robot: I have created a release candidate *beep* *boop*", body[0])
+	require.Equal(t, changelog, body[2:7])
+	require.Equal(t, Markdown{"#", "", "This release was composed by [version_actions](https://github.com/jakbytes/version_actions)"}, body[7:])
+}
@@ -1,8 +1,10 @@
 package conventional
 
 import (
+	"fmt"
 	"github.com/google/go-github/v58/github"
 	"github.com/jakbytes/version_actions/internal/logger"
+	"github.com/jakbytes/version_actions/tools/semver"
 	"github.com/leodido/go-conventionalcommits"
 	"github.com/leodido/go-conventionalcommits/parser"
 	"github.com/rs/zerolog/log"
@@ -17,6 +19,7 @@ const (
 	Major Increment = iota
 	Minor
 	Patch
+	None Increment = -1 // no increment is necessary
 )
 
 // Parser is a struct that contains the parser for conventional commit messages.
@@ -26,58 +29,57 @@ type Parser struct {
 
 // ParseCommit parses the commit message and returns the conventional commit message.
 func (p *Parser) ParseCommit(commit *github.RepositoryCommit) (out *conventionalcommits.ConventionalCommit) {
-	message, err := p.Parse([]byte(*commit.Commit.Message))
+	out, _ = p.parseCommit(commit)
+	return
+}
+
+// parseCommit parses the commit message, the error is returned along with the conventional commit message if the
+// parser found a valid type and description before it errored out.
+func (p *Parser) parseCommit(commit *github.RepositoryCommit) (*conventionalcommits.ConventionalCommit, error) {
+	message, err := p.Parse([]byte(NormalizeRevert(*commit.Commit.Message)))
 	if err != nil {
 		log.Warn().Err(err).Msgf("Failed to parse commit message: %s", *commit.Commit.Message)
 	}
-	out, _ = message.(*conventionalcommits.ConventionalCommit)
-	return
+	out, _ := message.(*conventionalcommits.ConventionalCommit)
+	return out, err
 }
 
-// Commits is a struct that contains the commits for breaking changes, features, and fixes based on the conventional
-// commit types.
-type Commits struct {
-	Breaking []*github.RepositoryCommit
-	Feat     []*github.RepositoryCommit
-	Fix      []*github.RepositoryCommit
-	Docs     []*github.RepositoryCommit
-	Style    []*github.RepositoryCommit
-	Refactor []*github.RepositoryCommit
-	Perf     []*github.RepositoryCommit
-	Test     []*github.RepositoryCommit
-	Build    []*github.RepositoryCommit
-	CI       []*github.RepositoryCommit
-	Debug    []*github.RepositoryCommit
-	Chore    []*github.RepositoryCommit
-}
+// Commits are the parsed commits keyed by the name of their type, breaking changes are keyed by Breaking regardless of
+// their type.
+type Commits map[string][]*github.RepositoryCommit
 
 // Increment returns the increment type based on the collection of commits.
-// If there are any breaking changes, the increment type is Major. If there are any features, the increment type is
-// Minor. If there are any fixes, the increment type is Patch. Otherwise, the increment type is -1, indicating no
-// increment is necessary.
-func (c *Commits) Increment() Increment {
-	if len(c.Breaking) > 0 {
-		return Major
-	}
-	if len(c.Feat) > 0 {
-		return Minor
-	}
-	if len(c.Fix) > 0 {
-		return Patch
-	}
-	if len(c.Chore) > 0 {
-		for _, commit := range c.Chore {
-			if strings.Contains(*commit.Commit.Message, "(deps)") {
-				return Patch
-			}
+// The increment is the greatest increment required by the types of the commits, see Types, where breaking changes
+// require a Major increment, and by the dependency updates, see Policy. Otherwise, the increment type is None,
+// indicating no increment is necessary.
+func (c Commits) Increment() Increment {
+	increment, _ := c.IncrementTrigger()
+	return increment
+}
+
+// IncrementTrigger returns the increment like Increment, and the commit that requires it: the oldest commit of the type
+// requiring the increment, or else the oldest dependency update requiring it. The commit is nil if no increment is
+// necessary.
+func (c Commits) IncrementTrigger() (Increment, *github.RepositoryCommit) {
+	increment, trigger := None, (*github.RepositoryCommit)(nil)
+	for _, t := range Types {
+		if len(c[t.Name]) > 0 && t.Bump != None && (increment == None || t.Bump < increment) {
+			increment, trigger = t.Bump, c[t.Name][len(c[t.Name])-1] // commits are ordered newest first
 		}
 	}
 
-	return -1
+	updates := c[Dependencies]
+	for i := len(updates) - 1; i >= 0; i-- {
+		bump := Policy.Increment(ParseDependencies(updates[i].GetCommit().GetMessage()))
+		if bump != None && (increment == None || bump < increment) {
+			increment, trigger = bump, updates[i]
+		}
+	}
+	return increment, trigger
 }
 
-// ParseCommits parses the commits and returns the version increment and associated commits for breaking changes, features,
-// and fixes.
+// ParseCommits parses the commits and returns them keyed by their type. Commits whose type is not one of Types are not
+// accounted for, neither are reverts and the commits they revert if both are among the commits.
 //
 // The parser is configured to use the best effort mode. The best effort mode will make the parser return what it found
 // until the point it errored out, if it found (at least) a valid type and a valid description. However, if the parser
@@ -91,46 +93,119 @@ func (c *Commits) Increment() Increment {
 // Returns:
 //   - parsed (Commits): The parsed commits.
 func ParseCommits(commits map[string]*github.RepositoryCommit) (parsed Commits) {
+	return CollectCommits(ClassifyCommits(commits))
+}
+
+// CollectCommits returns the classified commits that are accounted for keyed by their type, see ParseCommits.
+func CollectCommits(classified []Classification) (parsed Commits) {
+	parsed = make(Commits)
+	for _, c := range classified {
+		if c.Type != "" {
+			parsed[c.Type] = append(parsed[c.Type], c.Commit)
+		}
+	}
+	return parsed
+}
+
+// Classification is how a commit is accounted for by ParseCommits.
+type Classification struct {
+	Commit    *github.RepositoryCommit
+	Type      string // the key of the commit in Commits, empty if the commit is not accounted for
+	Parsed    string // the type of the conventional commit message, empty if the message could not be parsed
+	Breaking  bool   // whether the message marks a breaking change
+	ReleaseAs string // the value of the Release-As footer, empty if the message has none
+	// Dependencies are the dependency updates of a commit classified as Dependencies, see ParseDependencies
+	Dependencies []Dependency
+	// Reverted is the commit this commit cancels out with, its revert or the commit it reverts, see Reverts
+	Reverted *github.RepositoryCommit
+	Err      error // the error of the parser, the message may still be parsed on a best effort basis
+}
+
+// Increment returns the increment required by the commit: the bump of its type, or the increment the Policy requires
+// for dependency updates.
+func (c Classification) Increment() Increment {
+	if c.Type == Dependencies {
+		return Policy.Increment(c.Dependencies)
+	}
+	if t, ok := LookupType(c.Type); ok {
+		return t.Bump
+	}
+	return None
+}
+
+// ClassifyCommits classifies the commits like ParseCommits, including the commits that are not accounted for because
+// their message could not be parsed, their type is not one of Types or they cancel out with a revert. The
+// classifications are ordered newest first.
+func ClassifyCommits(commits map[string]*github.RepositoryCommit) (classified []Classification) {
 	log.Logger = logger.Base()
 	cparser := Parser{parser.NewMachine(
 		conventionalcommits.WithTypes(conventionalcommits.TypesFreeForm),
 		conventionalcommits.WithBestEffort(),
 	)}
 	for _, commit := range commits {
-		message := Message{
-			cparser.ParseCommit(commit),
-			commit,
+		c := Classification{Commit: commit}
+		parsed, err := cparser.parseCommit(commit)
+		c.Err = err
+		message := Message{parsed, commit}
+		if parsed != nil && len(parsed.Footers[ReleaseAsFooter]) > 0 {
+			values := parsed.Footers[ReleaseAsFooter]
+			c.ReleaseAs = strings.TrimSpace(values[len(values)-1])
 		}
-		if message.ConventionalCommit != nil {
-			if message.IsBreakingChange() {
-				parsed.Breaking = insert(parsed.Breaking, commit, less)
-			} else if message.IsFeat() {
-				parsed.Feat = insert(parsed.Feat, commit, less)
-			} else if message.IsFix() {
-				parsed.Fix = insert(parsed.Fix, commit, less)
-			} else if message.IsDocs() {
-				parsed.Docs = insert(parsed.Docs, commit, less)
-			} else if message.IsStyle() {
-				parsed.Style = insert(parsed.Style, commit, less)
-			} else if message.IsRefactor() {
-				parsed.Refactor = insert(parsed.Refactor, commit, less)
-			} else if message.IsPerf() {
-				parsed.Perf = insert(parsed.Perf, commit, less)
-			} else if message.IsTest() {
-				parsed.Test = insert(parsed.Test, commit, less)
-			} else if message.IsBuild() {
-				parsed.Build = insert(parsed.Build, commit, less)
-			} else if message.IsCI() {
-				parsed.CI = insert(parsed.CI, commit, less)
-			} else if message.IsDebug() {
-				parsed.Debug = insert(parsed.Debug, commit, less)
-			} else if message.IsChore() {
-				parsed.Chore = insert(parsed.Chore, commit, less)
+		switch {
+		case message.ConventionalCommit == nil:
+		case message.IsBreakingChange():
+			c.Parsed, c.Type, c.Breaking = message.Type, Breaking, true
+		case message.isDependencyUpdate():
+			c.Parsed, c.Type, c.Dependencies = message.Type, Dependencies, ParseDependencies(commit.GetCommit().GetMessage())
+		default:
+			c.Parsed = message.Type
+			for _, t := range Types {
+				if t.Name != Breaking && t.Name != Dependencies && message.Is(t.Name) {
+					c.Type = t.Name
+					break
+				}
+			}
+			if c.Type == "" {
+				log.Debug().Msgf("Commit %s has the unrecognized type %s", commit.GetSHA(), message.Type)
 			}
+		}
+		classified = insert(classified, c, func(i, j Classification) bool { return less(i.Commit, j.Commit) })
+	}
+	pairReverts(classified)
+	return classified
+}
+
+// ReleaseAsFooter is the key of the footer that sets the next version, e.g. "Release-As: 3.0.0". Footer keys are
+// lowercased by the parser.
+const ReleaseAsFooter = "release-as"
 
+// ReleaseAs returns the version of the Release-As footer of the newest commit that has one, and the commit. The version
+// is nil if no commit has the footer.
+func ReleaseAs(classified []Classification) (*semver.Version, *github.RepositoryCommit, error) {
+	for _, c := range classified { // classifications are ordered newest first
+		if c.ReleaseAs == "" || c.Reverted != nil {
+			continue
+		}
+		version, err := ParseReleaseAs(c.ReleaseAs)
+		if err != nil {
+			return nil, c.Commit, fmt.Errorf("invalid Release-As footer of commit %s: %w", c.Commit.GetSHA(), err)
 		}
+		return version, c.Commit, nil
 	}
-	return parsed
+	return nil, nil, nil
+}
+
+// ParseReleaseAs parses the version a release is forced to, e.g. 3.0.0 or v3.0.0. Prerelease versions are rejected,
+// the prerelease number is derived like for computed versions.
+func ParseReleaseAs(value string) (*semver.Version, error) {
+	version, err := semver.NewVersion(value)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a semantic version: %w", value, err)
+	}
+	if version.Prerelease() != "" || version.Metadata() != "" {
+		return nil, fmt.Errorf("%q is not a release version, the prerelease number is derived from the branch", value)
+	}
+	return version, nil
 }
 
 // less function returns true if the commit date of i is less than j, false otherwise.
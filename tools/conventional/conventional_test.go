@@ -36,6 +36,7 @@ func TestParseCommits(t *testing.T) {
 		expectBuild    int
 		expectCI       int
 		expectChore    int
+		expectDeps     int
 	}{
 		{
 			name: "Commit with breaking change",
@@ -50,8 +51,8 @@ func TestParseCommits(t *testing.T) {
 			commits: map[string]*github.RepositoryCommit{
 				"1": mockCommit("chore(deps): bump github.com/rs/zerolog from 1.31.0 to 1.32.0\nBumps [github.com/rs/zerolog](https://github.com/rs/zerolog) from 1.31.0 to 1.32.0.\n- [Release notes](https://github.com/rs/zerolog/releases)\n- [Commits](rs/zerolog@v1.31.0...v1.32.0)\n\n---\nupdated-dependencies:\n- dependency-name: github.com/rs/zerolog\n  dependency-type: direct:production\n  update-type: version-update:semver-minor\n...\n\nSigned-off-by: dependabot[bot] <support@github.com>", time.Now()),
 			},
-			expectBump:  Patch,
-			expectChore: 1,
+			expectBump: Patch,
+			expectDeps: 1,
 		},
 		{
 			name: "Commit with ! for breaking change",
@@ -164,20 +165,21 @@ func TestParseCommits(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			parsed := ParseCommits(tc.commits)
 			assert.Equal(t, tc.expectBump, parsed.Increment())
-			assert.Len(t, parsed.Breaking, tc.expectBreaking)
-			assert.Len(t, parsed.Feat, tc.expectFeat)
-			assert.Len(t, parsed.Fix, tc.expectFix)
-			assert.Len(t, parsed.Docs, tc.expectDocs)
-			assert.Len(t, parsed.Style, tc.expectStyle)
-			assert.Len(t, parsed.Refactor, tc.expectRefactor)
-			assert.Len(t, parsed.Perf, tc.expectPerf)
-			assert.Len(t, parsed.Test, tc.expectTest)
-			assert.Len(t, parsed.Build, tc.expectBuild)
-			assert.Len(t, parsed.CI, tc.expectCI)
-			assert.Len(t, parsed.Chore, tc.expectChore)
+			assert.Len(t, parsed[Breaking], tc.expectBreaking)
+			assert.Len(t, parsed["feat"], tc.expectFeat)
+			assert.Len(t, parsed["fix"], tc.expectFix)
+			assert.Len(t, parsed["docs"], tc.expectDocs)
+			assert.Len(t, parsed["style"], tc.expectStyle)
+			assert.Len(t, parsed["refactor"], tc.expectRefactor)
+			assert.Len(t, parsed["perf"], tc.expectPerf)
+			assert.Len(t, parsed["test"], tc.expectTest)
+			assert.Len(t, parsed["build"], tc.expectBuild)
+			assert.Len(t, parsed["ci"], tc.expectCI)
+			assert.Len(t, parsed["chore"], tc.expectChore)
+			assert.Len(t, parsed[Dependencies], tc.expectDeps)
 
 			// Assuming all parsed are merged into a single slice for sorting validation
-			for _, commits := range [][]*github.RepositoryCommit{parsed.Breaking, parsed.Feat, parsed.Fix} {
+			for _, commits := range [][]*github.RepositoryCommit{parsed[Breaking], parsed["feat"], parsed["fix"]} {
 				for i := 0; i < len(commits)-1; i++ {
 					assert.True(t, commits[i].Commit.Committer.Date.After(*commits[i+1].Commit.Committer.Date.GetTime()))
 				}
@@ -0,0 +1,268 @@
+package conventional
+
+import (
+	"fmt"
+	"github.com/jakbytes/version_actions/tools/semver"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strings"
+)
+
+// Dependencies is the name of the section of dependency updates, the commits with the deps or deps-dev scope or with
+// the metadata of Dependabot or Renovate, regardless of their type.
+const Dependencies = "dependencies"
+
+// The types of the dependencies that are updated.
+const (
+	Production  = "production"  // a direct dependency of the released code
+	Development = "development" // a dependency of the development, e.g. of the tests or the build
+	Indirect    = "indirect"    // a transitive dependency
+)
+
+// Dependency is a dependency update described by a commit message.
+type Dependency struct {
+	Name   string // the package, e.g. github.com/rs/zerolog
+	From   string // the version updated from, empty if it is unknown
+	To     string // the version updated to, empty if it is unknown
+	Type   string // Production, Development, Indirect or empty if it is unknown
+	Update string // the semver part that changed, major, minor or patch, empty if it is unknown
+}
+
+// UpdateIncrement returns the semver part that changed, Update if it is known or else the difference between From and
+// To. Patch is returned if neither is known.
+func (d Dependency) UpdateIncrement() Increment {
+	if update, err := ParseIncrement(d.Update); err == nil && update != None {
+		return update
+	}
+	from, err := semver.NewVersion(d.From)
+	if err != nil {
+		return Patch
+	}
+	to, err := semver.NewVersion(d.To)
+	if err != nil {
+		return Patch
+	}
+	switch {
+	case from.Major() != to.Major():
+		return Major
+	case from.Minor() != to.Minor():
+		return Minor
+	}
+	return Patch
+}
+
+// DependencyBump is the increment required by a dependency update. With Follow the update requires the increment of
+// the update itself, e.g. minor for an update from 1.2.0 to 1.3.0.
+type DependencyBump struct {
+	Increment Increment
+	Follow    bool
+}
+
+// ParseDependencyBump parses a dependency bump, follow or the name of an increment.
+func ParseDependencyBump(name string) (DependencyBump, error) {
+	if name == "follow" {
+		return DependencyBump{Increment: None, Follow: true}, nil
+	}
+	increment, err := ParseIncrement(name)
+	if err != nil {
+		return DependencyBump{}, fmt.Errorf("unknown bump %q, expected one of major, minor, patch, none, follow", name)
+	}
+	return DependencyBump{Increment: increment}, nil
+}
+
+// String returns the name of the bump, the inverse of ParseDependencyBump.
+func (b DependencyBump) String() string {
+	if b.Follow {
+		return "follow"
+	}
+	return b.Increment.String()
+}
+
+// DependencyPolicy decides the increment required by dependency updates by the type of the updated dependency.
+type DependencyPolicy struct {
+	Production  DependencyBump
+	Development DependencyBump
+	Indirect    DependencyBump
+	Unknown     DependencyBump // updates whose dependency type is unknown, e.g. commits with the deps scope only
+}
+
+// Policy is the dependency policy dependency updates are released with.
+var Policy = DefaultPolicy()
+
+// DefaultPolicy returns the dependency policy used when no policy is configured, every update requires a Patch
+// increment.
+func DefaultPolicy() DependencyPolicy {
+	patch := DependencyBump{Increment: Patch}
+	return DependencyPolicy{Production: patch, Development: patch, Indirect: patch, Unknown: patch}
+}
+
+// Increment returns the increment required by the dependency updates of a commit, the greatest increment required by
+// any of the dependencies. A commit without parsed dependencies requires the increment of an update of an unknown
+// dependency.
+func (p DependencyPolicy) Increment(dependencies []Dependency) Increment {
+	if len(dependencies) == 0 {
+		dependencies = []Dependency{{}}
+	}
+	increment := None
+	for _, d := range dependencies {
+		bump := p.Unknown
+		switch d.Type {
+		case Production:
+			bump = p.Production
+		case Development:
+			bump = p.Development
+		case Indirect:
+			bump = p.Indirect
+		}
+		i := bump.Increment
+		if bump.Follow {
+			i = d.UpdateIncrement()
+		}
+		if i != None && (increment == None || i < increment) {
+			increment = i
+		}
+	}
+	return increment
+}
+
+var (
+	// dependabotSubject matches the subject of a Dependabot commit after the type and scope,
+	// e.g. bump github.com/rs/zerolog from 1.31.0 to 1.32.0 in /tools
+	dependabotSubject = regexp.MustCompile(`^[Bb]ump (\S+) from (\S+) to (\S+)`)
+	// dependabotUpdate matches the lines of the body of a Dependabot commit describing an update, e.g.
+	// Bumps [github.com/rs/zerolog](https://github.com/rs/zerolog) from 1.31.0 to 1.32.0.
+	// Updates `github.com/rs/zerolog` from 1.31.0 to 1.32.0
+	dependabotUpdate = regexp.MustCompile("(?m)^(?:Bumps|Updates) (?:\\[([^\\]]+)\\]\\([^)]*\\)|`([^`]+)`) from (\\S+) to (\\S+?)\\.?\\s*$")
+	// renovateSubject matches the subject of a Renovate commit after the type and scope, e.g.
+	// update dependency eslint to v8.57.0, update module github.com/rs/zerolog to v1.32.0, update actions/checkout action to v4
+	renovateSubject = regexp.MustCompile(`^(?:[Uu]pdate|[Pp]in) (?:dependency |module |package )?(\S+)(?: (?:action|digest|docker tag|image))? to (\S+)`)
+)
+
+// The footers of a Renovate commit body describing the updates, e.g. configured with the commitBody option of Renovate.
+// Each footer is repeated for every update of a grouped update.
+const (
+	RenovatePackageFooter = "Renovate-Package"
+	RenovateFromFooter    = "Renovate-From"
+	RenovateToFooter      = "Renovate-To"
+	RenovateUpdateFooter  = "Renovate-Update-Type"
+	RenovateTypeFooter    = "Renovate-Dependency-Type"
+)
+
+// devScope matches the header of a commit with the deps-dev scope Dependabot uses for development dependencies.
+var devScope = regexp.MustCompile(`^\w+\(deps-dev\)!?:`)
+
+// ParseDependencies returns the dependency updates described by the commit message: the updated-dependencies metadata
+// of Dependabot, the Renovate footers, or else the package and versions of the subject, e.g. "bump X from 1.0.0 to
+// 1.1.0" or "update dependency X to v2.0.0". The versions of Dependabot updates are read from the subject and body.
+func ParseDependencies(message string) []Dependency {
+	if dependencies := dependabotDependencies(message); len(dependencies) > 0 {
+		return dependencies
+	}
+	if dependencies := renovateDependencies(message); len(dependencies) > 0 {
+		return dependencies
+	}
+	line, _, _ := strings.Cut(message, "\n")
+	var d Dependency
+	_, description, _ := strings.Cut(line, ": ")
+	if match := dependabotSubject.FindStringSubmatch(description); match != nil {
+		d = Dependency{Name: match[1], From: match[2], To: match[3]}
+	} else if match = renovateSubject.FindStringSubmatch(description); match != nil {
+		d = Dependency{Name: match[1], To: match[2]}
+	} else {
+		return nil
+	}
+	if devScope.MatchString(line) {
+		d.Type = Development
+	}
+	return []Dependency{d}
+}
+
+// hasDependencyMetadata reports whether the commit message carries the metadata of Dependabot or Renovate.
+func hasDependencyMetadata(message string) bool {
+	return len(dependabotDependencies(message)) > 0 || len(renovateDependencies(message)) > 0
+}
+
+// dependabotDependencies parses the updated-dependencies YAML block of a Dependabot commit message, which starts with a
+// --- line and ends with a ... line.
+func dependabotDependencies(message string) (dependencies []Dependency) {
+	_, block, found := strings.Cut(message, "\n---\nupdated-dependencies:")
+	if !found {
+		return nil
+	}
+	block, _, _ = strings.Cut(block, "\n...")
+	var metadata struct {
+		Updates []struct {
+			Name    string `yaml:"dependency-name"`
+			Type    string `yaml:"dependency-type"`
+			Update  string `yaml:"update-type"`
+			Version string `yaml:"dependency-version"`
+		} `yaml:"updated-dependencies"`
+	}
+	if err := yaml.Unmarshal([]byte("updated-dependencies:"+block), &metadata); err != nil {
+		return nil
+	}
+
+	versions := make(map[string][2]string)
+	line, _, _ := strings.Cut(message, "\n")
+	_, description, _ := strings.Cut(line, ": ")
+	if match := dependabotSubject.FindStringSubmatch(description); match != nil {
+		versions[match[1]] = [2]string{match[2], match[3]}
+	}
+	for _, match := range dependabotUpdate.FindAllStringSubmatch(message, -1) {
+		versions[match[1]+match[2]] = [2]string{match[3], match[4]}
+	}
+
+	for _, update := range metadata.Updates {
+		d := Dependency{Name: update.Name, From: versions[update.Name][0], To: versions[update.Name][1]}
+		if d.To == "" {
+			d.To = update.Version
+		}
+		switch update.Type {
+		case "direct:production":
+			d.Type = Production
+		case "direct:development":
+			d.Type = Development
+		case "indirect":
+			d.Type = Indirect
+		}
+		switch update.Update {
+		case "version-update:semver-major", "version-update:semver-minor", "version-update:semver-patch":
+			d.Update = strings.TrimPrefix(update.Update, "version-update:semver-")
+		}
+		dependencies = append(dependencies, d)
+	}
+	return dependencies
+}
+
+// renovateDependencies returns the updates described by the Renovate footers of the commit message.
+func renovateDependencies(message string) (dependencies []Dependency) {
+	footers := make(map[string][]string)
+	for _, line := range strings.Split(message, "\n") {
+		key, value, found := strings.Cut(line, ": ")
+		if found {
+			footers[strings.ToLower(key)] = append(footers[strings.ToLower(key)], strings.TrimSpace(value))
+		}
+	}
+	at := func(key string, i int) string {
+		if values := footers[strings.ToLower(key)]; i < len(values) {
+			return values[i]
+		}
+		return ""
+	}
+	for i := range footers[strings.ToLower(RenovatePackageFooter)] {
+		d := Dependency{Name: at(RenovatePackageFooter, i), From: at(RenovateFromFooter, i), To: at(RenovateToFooter, i)}
+		switch t := at(RenovateTypeFooter, i); {
+		case strings.Contains(strings.ToLower(t), "dev"), t == "test":
+			d.Type = Development
+		case t == "indirect":
+			d.Type = Indirect
+		case t != "":
+			d.Type = Production
+		}
+		if update := at(RenovateUpdateFooter, i); update == "major" || update == "minor" || update == "patch" {
+			d.Update = update
+		}
+		dependencies = append(dependencies, d)
+	}
+	return dependencies
+}
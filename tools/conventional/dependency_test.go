@@ -0,0 +1,170 @@
+package conventional
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dependabotGroup = "chore(deps): bump the go group with 2 updates\n\n" +
+	"Bumps the go group with 2 updates: [github.com/rs/zerolog](https://github.com/rs/zerolog) and [gopkg.in/yaml.v3](https://github.com/go-yaml/yaml).\n\n" +
+	"Updates `github.com/rs/zerolog` from 1.31.0 to 1.32.0\n- [Release notes](https://github.com/rs/zerolog/releases)\n\n" +
+	"Updates `gopkg.in/yaml.v3` from 3.0.0 to 3.0.1\n- [Commits](https://github.com/go-yaml/yaml/compare/v3.0.0...v3.0.1)\n\n" +
+	"---\nupdated-dependencies:\n" +
+	"- dependency-name: github.com/rs/zerolog\n  dependency-type: direct:production\n  update-type: version-update:semver-minor\n  dependency-group: go\n" +
+	"- dependency-name: gopkg.in/yaml.v3\n  dependency-type: direct:development\n  update-type: version-update:semver-patch\n  dependency-group: go\n" +
+	"...\n\nSigned-off-by: dependabot[bot] <support@github.com>"
+
+func TestParseDependencies(t *testing.T) {
+	testCases := []struct {
+		name    string
+		message string
+		want    []Dependency
+	}{
+		{
+			name:    "dependabot",
+			message: "chore(deps): bump github.com/rs/zerolog from 1.31.0 to 1.32.0\n\nBumps [github.com/rs/zerolog](https://github.com/rs/zerolog) from 1.31.0 to 1.32.0.\n\n---\nupdated-dependencies:\n- dependency-name: github.com/rs/zerolog\n  dependency-type: direct:production\n  update-type: version-update:semver-minor\n...\n\nSigned-off-by: dependabot[bot] <support@github.com>",
+			want:    []Dependency{{Name: "github.com/rs/zerolog", From: "1.31.0", To: "1.32.0", Type: Production, Update: "minor"}},
+		},
+		{
+			name:    "dependabot group",
+			message: dependabotGroup,
+			want: []Dependency{
+				{Name: "github.com/rs/zerolog", From: "1.31.0", To: "1.32.0", Type: Production, Update: "minor"},
+				{Name: "gopkg.in/yaml.v3", From: "3.0.0", To: "3.0.1", Type: Development, Update: "patch"},
+			},
+		},
+		{
+			name:    "renovate footers",
+			message: "chore(deps): update dependency eslint to v8.57.0\n\nRenovate-Package: eslint\nRenovate-From: 8.56.0\nRenovate-To: 8.57.0\nRenovate-Update-Type: minor\nRenovate-Dependency-Type: devDependencies",
+			want:    []Dependency{{Name: "eslint", From: "8.56.0", To: "8.57.0", Type: Development, Update: "minor"}},
+		},
+		{
+			name:    "renovate subject",
+			message: "fix(deps): update module github.com/rs/zerolog to v1.32.0",
+			want:    []Dependency{{Name: "github.com/rs/zerolog", To: "v1.32.0"}},
+		},
+		{
+			name:    "renovate action",
+			message: "chore(deps): update actions/checkout action to v4",
+			want:    []Dependency{{Name: "actions/checkout", To: "v4"}},
+		},
+		{
+			name:    "development subject",
+			message: "chore(deps-dev): bump eslint from 8.56.0 to 9.0.0 in /web",
+			want:    []Dependency{{Name: "eslint", From: "8.56.0", To: "9.0.0", Type: Development}},
+		},
+		{
+			name:    "no dependency",
+			message: "chore(deps): tidy go.mod",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ParseDependencies(tc.message))
+		})
+	}
+}
+
+func TestClassifyCommits_Dependencies(t *testing.T) {
+	commit := func(message string, day int) *github.RepositoryCommit {
+		return &github.RepositoryCommit{Commit: &github.Commit{
+			Message:   github.String(message),
+			Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}},
+		}}
+	}
+	commits := map[string]*github.RepositoryCommit{
+		"1": commit(dependabotGroup, 1),
+		"2": commit("build: bump go to 1.22\n\nRenovate-Package: go\nRenovate-To: 1.22", 2),
+		"3": commit("chore(deps)!: drop node 16", 3),
+		"4": commit("chore: bump the version in the readme", 4),
+	}
+
+	classified := ClassifyCommits(commits)
+	require.Len(t, classified, 4)
+	assert.Equal(t, "chore", classified[0].Type)
+	assert.Equal(t, Breaking, classified[1].Type)
+	assert.Equal(t, Dependencies, classified[2].Type)
+	assert.Equal(t, []Dependency{{Name: "go", To: "1.22"}}, classified[2].Dependencies)
+	assert.Equal(t, Dependencies, classified[3].Type)
+	assert.Len(t, classified[3].Dependencies, 2)
+	assert.Equal(t, Patch, classified[3].Increment())
+}
+
+func TestDependencyPolicy_Increment(t *testing.T) {
+	production := Dependency{Name: "a", From: "1.2.0", To: "1.3.0", Type: Production}
+	development := Dependency{Name: "b", From: "1.0.0", To: "2.0.0", Type: Development}
+	indirect := Dependency{Name: "c", Type: Indirect, Update: "major"}
+	unknown := Dependency{Name: "d", To: "v4"}
+	policy := DependencyPolicy{
+		Production:  DependencyBump{Increment: None, Follow: true},
+		Development: DependencyBump{Increment: None},
+		Indirect:    DependencyBump{Increment: Patch},
+		Unknown:     DependencyBump{Increment: Patch},
+	}
+
+	testCases := []struct {
+		name         string
+		dependencies []Dependency
+		want         Increment
+	}{
+		{"follow the versions", []Dependency{production}, Minor},
+		{"ignored", []Dependency{development}, None},
+		{"indirect", []Dependency{indirect}, Patch},
+		{"unknown type", []Dependency{unknown}, Patch},
+		{"no dependencies", nil, Patch},
+		{"greatest of the group", []Dependency{development, indirect, production}, Minor},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, policy.Increment(tc.dependencies))
+		})
+	}
+}
+
+func TestIncrementTrigger_Policy(t *testing.T) {
+	original := Policy
+	defer func() { Policy = original }()
+	Policy.Production = DependencyBump{Increment: None, Follow: true}
+	Policy.Development = DependencyBump{Increment: None}
+
+	commit := func(message string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{Commit: &github.Commit{Message: github.String(message)}}
+	}
+	major := commit("chore(deps): bump a from 1.0.0 to 2.0.0\n\n---\nupdated-dependencies:\n- dependency-name: a\n  dependency-type: direct:production\n...")
+	dev := commit("chore(deps-dev): bump b from 1.0.0 to 2.0.0")
+	fix := commit("fix: bug")
+
+	increment, trigger := Commits{Dependencies: {major, dev}, "fix": {fix}}.IncrementTrigger()
+	assert.Equal(t, Major, increment)
+	assert.Same(t, major, trigger)
+
+	increment, trigger = Commits{Dependencies: {dev}, "fix": {fix}}.IncrementTrigger()
+	assert.Equal(t, Patch, increment)
+	assert.Same(t, fix, trigger)
+
+	increment, trigger = Commits{Dependencies: {dev}}.IncrementTrigger()
+	assert.Equal(t, None, increment)
+	assert.Nil(t, trigger)
+}
+
+func TestParseDependencyBump(t *testing.T) {
+	for name, want := range map[string]DependencyBump{
+		"follow": {Increment: None, Follow: true},
+		"minor":  {Increment: Minor},
+		"none":   {Increment: None},
+	} {
+		bump, err := ParseDependencyBump(name)
+		require.Nil(t, err)
+		assert.Equal(t, want, bump)
+		assert.Equal(t, name, bump.String())
+	}
+
+	_, err := ParseDependencyBump("skip")
+	require.EqualError(t, err, `unknown bump "skip", expected one of major, minor, patch, none, follow`)
+}
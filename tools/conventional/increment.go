@@ -7,9 +7,12 @@ import (
 
 // VersionConfig is a struct that contains the configuration for the versioning process
 type VersionConfig struct {
-	DefaultBranch        string // The default branch for the repository
-	BaseBranch           string // The current branch version to increment
-	PrereleaseIdentifier string // The prerelease tag to use for prerelease versions
+	DefaultBranch        string          // The default branch for the repository
+	BaseBranch           string          // The current branch version to increment
+	PrereleaseIdentifier string          // The prerelease tag to use for prerelease versions
+	PreMajor             bool            // Below 1.0.0 breaking changes increment the minor and features the patch version
+	Graduate             bool            // Increment a version below 1.0.0 to 1.0.0 regardless of the commits
+	ReleaseAs            *semver.Version // The release version to use instead of incrementing, e.g. of a Release-As footer
 }
 
 // VersionInfo is a struct that contains the current version and the current release candidate version
@@ -20,19 +23,64 @@ type VersionInfo struct {
 
 // IncVersion increments the version based on the current version, the current release candidate, and the configuration
 func IncVersion(info VersionInfo, config VersionConfig, increment Increment) (*semver.Version, error) {
+	version, _, err := ExplainIncVersion(info, config, increment)
+	return version, err
+}
 
+// ExplainIncVersion increments the version like IncVersion and returns the steps of how the version was derived.
+func ExplainIncVersion(info VersionInfo, config VersionConfig, increment Increment) (*semver.Version, []string, error) {
+	var steps []string
 	var newVersion *semver.Version
-	if info.CurrentVersion == nil {
+	switch {
+	case config.ReleaseAs != nil:
+		newVersion = config.ReleaseAs
+		steps = append(steps, fmt.Sprintf("The version is set to %s by Release-As, the increment of the commits is not applied.", config.ReleaseAs))
+	case config.Graduate && (info.CurrentVersion == nil || info.CurrentVersion.Major() == 0):
+		newVersion = semver.MustParse("1.0.0")
+		if info.CurrentVersion == nil {
+			steps = append(steps, "No release version was found, the version graduates to 1.0.0.")
+		} else {
+			steps = append(steps, fmt.Sprintf("The version graduates from %s to 1.0.0.", info.CurrentVersion))
+		}
+	case info.CurrentVersion == nil:
 		newVersion = semver.MustParse("0.0.0")
-	} else {
+		steps = append(steps, "No release version was found, the version starts at 0.0.0.")
+	default:
+		if config.Graduate {
+			steps = append(steps, fmt.Sprintf("The version %s is not below 1.0.0, graduating is ignored.", info.CurrentVersion))
+		}
+		if preMajor := preMajorIncrement(increment); config.PreMajor && info.CurrentVersion.Major() == 0 && preMajor != increment {
+			steps = append(steps, fmt.Sprintf("The version %s is below 1.0.0, the %s increment is applied as a %s increment.", info.CurrentVersion, increment, preMajor))
+			increment = preMajor
+		}
 		newVersion = incrementVersion(info.CurrentVersion, increment)
+		if increment == None {
+			steps = append(steps, fmt.Sprintf("No increment is necessary, the version stays at %s.", info.CurrentVersion))
+		} else {
+			steps = append(steps, fmt.Sprintf("The %s increment of %s is %s.", increment, info.CurrentVersion, newVersion))
+		}
 	}
 
 	if config.BaseBranch != config.DefaultBranch {
-		return incPrereleaseVersion(newVersion, info.CurrentReleaseCandidate, config.PrereleaseIdentifier)
+		version, step, err := incPrereleaseVersion(newVersion, info.CurrentReleaseCandidate, config.PrereleaseIdentifier)
+		steps = append(steps, fmt.Sprintf("The base branch %s is not the release branch %s, the version is a %s prerelease.", config.BaseBranch, config.DefaultBranch, config.PrereleaseIdentifier), step)
+		return version, steps, err
 	}
 
-	return newVersion, nil
+	steps = append(steps, fmt.Sprintf("The base branch %s is the release branch, the version is a release version.", config.BaseBranch))
+	return newVersion, steps, nil
+}
+
+// preMajorIncrement returns the increment applied to versions below 1.0.0 with PreMajor, breaking changes increment the
+// minor and features the patch version.
+func preMajorIncrement(increment Increment) Increment {
+	switch increment {
+	case Major:
+		return Minor
+	case Minor:
+		return Patch
+	}
+	return increment
 }
 
 // incrementVersion increments the version based on the current version and the increment type
@@ -50,20 +98,27 @@ func incrementVersion(version *semver.Version, increment Increment) *semver.Vers
 	}
 }
 
-func incPrereleaseVersion(newVersion *semver.Version, curReleaseCandidate *semver.Version, prereleaseIdentifier string) (*semver.Version, error) {
-	if curReleaseCandidate == nil || (curReleaseCandidate.Major() != newVersion.Major() || curReleaseCandidate.Minor() != newVersion.Minor()) {
-		// No release candidate exists or the current release candidate version is not the same as the new version
-		return newVersion.AsPrereleaseVersion(prereleaseIdentifier, 0)
-	} else {
-		rcValue, err := curReleaseCandidate.PrereleaseVersionNumber()
-		if err != nil {
-			return nil, err
-		}
-		if curReleaseCandidate.PrereleaseIdentifier() == prereleaseIdentifier && // if the new version is a greater base version than the current release candidate we can restart from 0
-			!newVersion.GreaterThan(semver.MustParse(fmt.Sprintf("%d.%d.%d", curReleaseCandidate.Major(), curReleaseCandidate.Minor(), curReleaseCandidate.Patch()))) {
-			return newVersion.AsPrereleaseVersion(prereleaseIdentifier, rcValue+1)
-		} else {
-			return newVersion.AsPrereleaseVersion(prereleaseIdentifier, 0)
-		}
+// incPrereleaseVersion returns the prerelease version of the new version and how its prerelease number was derived.
+func incPrereleaseVersion(newVersion *semver.Version, curReleaseCandidate *semver.Version, prereleaseIdentifier string) (*semver.Version, string, error) {
+	if curReleaseCandidate == nil {
+		version, err := newVersion.AsPrereleaseVersion(prereleaseIdentifier, 0)
+		return version, fmt.Sprintf("No %s prerelease was found, the prerelease number starts at 0.", prereleaseIdentifier), err
+	}
+	if curReleaseCandidate.Major() != newVersion.Major() || curReleaseCandidate.Minor() != newVersion.Minor() {
+		// the current release candidate version is not the same as the new version
+		version, err := newVersion.AsPrereleaseVersion(prereleaseIdentifier, 0)
+		return version, fmt.Sprintf("The latest prerelease %s is of another minor version than %s, the prerelease number restarts at 0.", curReleaseCandidate, newVersion), err
+	}
+
+	rcValue, err := curReleaseCandidate.PrereleaseVersionNumber()
+	if err != nil {
+		return nil, "", err
+	}
+	if curReleaseCandidate.PrereleaseIdentifier() == prereleaseIdentifier && // if the new version is a greater base version than the current release candidate we can restart from 0
+		!newVersion.GreaterThan(semver.MustParse(fmt.Sprintf("%d.%d.%d", curReleaseCandidate.Major(), curReleaseCandidate.Minor(), curReleaseCandidate.Patch()))) {
+		version, err := newVersion.AsPrereleaseVersion(prereleaseIdentifier, rcValue+1)
+		return version, fmt.Sprintf("The latest prerelease %s is of the same version, the prerelease number is incremented to %d.", curReleaseCandidate, rcValue+1), err
 	}
+	version, err := newVersion.AsPrereleaseVersion(prereleaseIdentifier, 0)
+	return version, fmt.Sprintf("The latest prerelease %s has another identifier or a lower version than %s, the prerelease number restarts at 0.", curReleaseCandidate, newVersion), err
 }
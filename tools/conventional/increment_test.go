@@ -57,7 +57,7 @@ func TestBumpVersion(t *testing.T) {
 	// Iterate over the test cases
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := IncVersion(VersionInfo{tc.currentVersion, tc.currentReleaseCandidate}, VersionConfig{tc.defaultBranch, tc.currentBranch, tc.prerelease}, tc.increment)
+			got, err := IncVersion(VersionInfo{tc.currentVersion, tc.currentReleaseCandidate}, VersionConfig{DefaultBranch: tc.defaultBranch, BaseBranch: tc.currentBranch, PrereleaseIdentifier: tc.prerelease}, tc.increment)
 			if tc.wantErr {
 				require.Error(t, err, "BumpVersion() should have returned an error")
 				require.Equal(t, tc.err, err)
@@ -68,3 +68,132 @@ func TestBumpVersion(t *testing.T) {
 		})
 	}
 }
+
+// TestBumpVersion_PreMajor is a table-driven test for IncVersion with the pre-1.0 policy and graduation to 1.0.0
+func TestBumpVersion_PreMajor(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		currentVersion          *semver.Version
+		currentReleaseCandidate *semver.Version
+		currentBranch           string
+		increment               Increment
+		preMajor                bool
+		graduate                bool
+		want                    *semver.Version
+	}{
+		{"Breaking Change Bumps Minor", semver.MustParse("0.4.2"), nil, "main", Major, true, false, semver.MustParse("0.5.0")},
+		{"Feature Bumps Patch", semver.MustParse("0.4.2"), nil, "main", Minor, true, false, semver.MustParse("0.4.3")},
+		{"Fix Bumps Patch", semver.MustParse("0.4.2"), nil, "main", Patch, true, false, semver.MustParse("0.4.3")},
+		{"No Increment", semver.MustParse("0.4.2"), nil, "main", None, true, false, semver.MustParse("0.4.2")},
+		{"Breaking Change Without Policy", semver.MustParse("0.4.2"), nil, "main", Major, false, false, semver.MustParse("1.0.0")},
+		{"Policy Above 1.0.0", semver.MustParse("1.4.2"), nil, "main", Major, true, false, semver.MustParse("2.0.0")},
+		{"Initial Version", nil, nil, "main", Major, true, false, semver.MustParse("0.0.0")},
+
+		{"Prerelease Breaking Change", semver.MustParse("0.4.2"), nil, "development", Major, true, false, semver.MustParse("0.5.0-rc.0")},
+		{"Prerelease Breaking Change Increment", semver.MustParse("0.4.2"), semver.MustParse("0.5.0-rc.1"), "development", Major, true, false, semver.MustParse("0.5.0-rc.2")},
+		{"Prerelease Feature", semver.MustParse("0.4.2"), semver.MustParse("0.4.3-rc.0"), "development", Minor, true, false, semver.MustParse("0.4.3-rc.1")},
+
+		{"Graduate", semver.MustParse("0.4.2"), nil, "main", Patch, true, true, semver.MustParse("1.0.0")},
+		{"Graduate Without Commits", semver.MustParse("0.4.2"), nil, "main", None, false, true, semver.MustParse("1.0.0")},
+		{"Graduate Initial Version", nil, nil, "main", None, false, true, semver.MustParse("1.0.0")},
+		{"Graduate Prerelease", semver.MustParse("0.4.2"), semver.MustParse("0.5.0-rc.3"), "development", Major, true, true, semver.MustParse("1.0.0-rc.0")},
+		{"Graduate Prerelease Increment", semver.MustParse("0.4.2"), semver.MustParse("1.0.0-rc.0"), "development", Patch, true, true, semver.MustParse("1.0.0-rc.1")},
+		{"Graduate Above 1.0.0", semver.MustParse("1.4.2"), nil, "main", Minor, false, true, semver.MustParse("1.5.0")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := IncVersion(VersionInfo{tc.currentVersion, tc.currentReleaseCandidate}, VersionConfig{
+				DefaultBranch:        "main",
+				BaseBranch:           tc.currentBranch,
+				PrereleaseIdentifier: "rc",
+				PreMajor:             tc.preMajor,
+				Graduate:             tc.graduate,
+			}, tc.increment)
+			require.NoError(t, err, fmt.Sprintf("BumpVersion() should not have returned an error: %v", err))
+			require.Equal(t, tc.want.String(), got.String(), "BumpVersion() did not return expected version")
+		})
+	}
+}
+
+func TestExplainIncVersion(t *testing.T) {
+	testCases := []struct {
+		name      string
+		info      VersionInfo
+		base      string
+		increment Increment
+		graduate  bool
+		want      string
+		steps     []string
+	}{
+		{"initial", VersionInfo{}, "main", Minor, false, "0.0.0", []string{
+			"No release version was found, the version starts at 0.0.0.",
+			"The base branch main is the release branch, the version is a release version.",
+		}},
+		{"no increment", VersionInfo{CurrentVersion: semver.MustParse("1.2.3")}, "main", None, false, "1.2.3", []string{
+			"No increment is necessary, the version stays at 1.2.3.",
+			"The base branch main is the release branch, the version is a release version.",
+		}},
+		{"first prerelease", VersionInfo{CurrentVersion: semver.MustParse("1.2.3")}, "development", Minor, false, "1.3.0-rc.0", []string{
+			"The minor increment of 1.2.3 is 1.3.0.",
+			"The base branch development is not the release branch main, the version is a rc prerelease.",
+			"No rc prerelease was found, the prerelease number starts at 0.",
+		}},
+		{"next prerelease", VersionInfo{CurrentVersion: semver.MustParse("1.2.3"), CurrentReleaseCandidate: semver.MustParse("1.3.0-rc.4")}, "development", Minor, false, "1.3.0-rc.5", []string{
+			"The minor increment of 1.2.3 is 1.3.0.",
+			"The base branch development is not the release branch main, the version is a rc prerelease.",
+			"The latest prerelease 1.3.0-rc.4 is of the same version, the prerelease number is incremented to 5.",
+		}},
+		{"other minor version", VersionInfo{CurrentVersion: semver.MustParse("1.2.3"), CurrentReleaseCandidate: semver.MustParse("1.2.4-rc.1")}, "development", Minor, false, "1.3.0-rc.0", []string{
+			"The minor increment of 1.2.3 is 1.3.0.",
+			"The base branch development is not the release branch main, the version is a rc prerelease.",
+			"The latest prerelease 1.2.4-rc.1 is of another minor version than 1.3.0, the prerelease number restarts at 0.",
+		}},
+		{"greater version", VersionInfo{CurrentVersion: semver.MustParse("1.2.4"), CurrentReleaseCandidate: semver.MustParse("1.2.4-rc.1")}, "development", Patch, false, "1.2.5-rc.0", []string{
+			"The patch increment of 1.2.4 is 1.2.5.",
+			"The base branch development is not the release branch main, the version is a rc prerelease.",
+			"The latest prerelease 1.2.4-rc.1 has another identifier or a lower version than 1.2.5, the prerelease number restarts at 0.",
+		}},
+		{"pre-1.0", VersionInfo{CurrentVersion: semver.MustParse("0.4.2")}, "main", Major, false, "0.5.0", []string{
+			"The version 0.4.2 is below 1.0.0, the major increment is applied as a minor increment.",
+			"The minor increment of 0.4.2 is 0.5.0.",
+			"The base branch main is the release branch, the version is a release version.",
+		}},
+		{"graduate", VersionInfo{CurrentVersion: semver.MustParse("0.4.2")}, "main", Major, true, "1.0.0", []string{
+			"The version graduates from 0.4.2 to 1.0.0.",
+			"The base branch main is the release branch, the version is a release version.",
+		}},
+		{"graduate above 1.0.0", VersionInfo{CurrentVersion: semver.MustParse("1.2.3")}, "main", Patch, true, "1.2.4", []string{
+			"The version 1.2.3 is not below 1.0.0, graduating is ignored.",
+			"The patch increment of 1.2.3 is 1.2.4.",
+			"The base branch main is the release branch, the version is a release version.",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			version, steps, err := ExplainIncVersion(tc.info, VersionConfig{DefaultBranch: "main", BaseBranch: tc.base, PrereleaseIdentifier: "rc", PreMajor: true, Graduate: tc.graduate}, tc.increment)
+			require.Nil(t, err)
+			require.Equal(t, tc.want, version.String())
+			require.Equal(t, tc.steps, steps)
+		})
+	}
+}
+
+func TestExplainIncVersion_ReleaseAs(t *testing.T) {
+	info := VersionInfo{CurrentVersion: semver.MustParse("1.2.3"), CurrentReleaseCandidate: semver.MustParse("3.0.0-rc.1")}
+	config := VersionConfig{DefaultBranch: "main", BaseBranch: "main", PrereleaseIdentifier: "rc", Graduate: true, ReleaseAs: semver.MustParse("3.0.0")}
+
+	version, steps, err := ExplainIncVersion(info, config, Patch)
+	require.Nil(t, err)
+	require.Equal(t, "3.0.0", version.String())
+	require.Equal(t, []string{
+		"The version is set to 3.0.0 by Release-As, the increment of the commits is not applied.",
+		"The base branch main is the release branch, the version is a release version.",
+	}, steps)
+
+	config.BaseBranch = "development"
+	version, err = IncVersion(info, config, Patch)
+	require.Nil(t, err)
+	require.Equal(t, "3.0.0-rc.2", version.String())
+}
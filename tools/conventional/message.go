@@ -47,38 +47,20 @@ func validateCommitMessage(commitType, commitMessage string) bool {
 	return re.MatchString(commitMessage)
 }
 
-func (m *Message) IsDocs() bool {
-	return validateCommitMessage("docs", *m.Commit.Message)
+// Is reports whether the commit message is of the commit type.
+func (m *Message) Is(commitType string) bool {
+	return validateCommitMessage(commitType, NormalizeRevert(*m.Commit.Message))
 }
 
-func (m *Message) IsStyle() bool {
-	return validateCommitMessage("style", *m.Commit.Message)
-}
-
-func (m *Message) IsRefactor() bool {
-	return validateCommitMessage("refactor", *m.Commit.Message)
-}
-
-func (m *Message) IsPerf() bool {
-	return validateCommitMessage("perf", *m.Commit.Message)
-}
-
-func (m *Message) IsTest() bool {
-	return validateCommitMessage("test", *m.Commit.Message)
-}
-
-func (m *Message) IsBuild() bool {
-	return validateCommitMessage("build", *m.Commit.Message)
-}
-
-func (m *Message) IsCI() bool {
-	return validateCommitMessage("ci", *m.Commit.Message)
-}
-
-func (m *Message) IsDebug() bool {
-	return validateCommitMessage("debug", *m.Commit.Message)
-}
-
-func (m *Message) IsChore() bool {
-	return validateCommitMessage("chore", *m.Commit.Message)
+// isDependencyUpdate reports whether the commit is a dependency update: its scope is deps or deps-dev, or its message
+// carries the metadata of Dependabot or Renovate. Dependency updates are only recognized when Dependencies is one of
+// Types.
+func (m *Message) isDependencyUpdate() bool {
+	if _, ok := LookupType(Dependencies); !ok {
+		return false
+	}
+	if m.Scope != nil && (*m.Scope == "deps" || *m.Scope == "deps-dev") {
+		return true
+	}
+	return hasDependencyMetadata(m.GetCommit().GetMessage())
 }
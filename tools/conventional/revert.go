@@ -0,0 +1,85 @@
+package conventional
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Reverts is the name of the section of reverts, the commits of the revert type, e.g. "revert: feat: add flag", or with
+// the header git revert and the Revert button of GitHub create, e.g. Revert "feat: add flag". A revert and the commit
+// it reverts cancel out when both are in the range of commits, so only the reverts of commits released before are
+// listed.
+const Reverts = "revert"
+
+var (
+	// revertHeader matches the header of a revert, the subject of the reverted commit follows the type
+	revertHeader = regexp.MustCompile(`^revert(?:\([^)]*\))?!?: (.+)$`)
+	// gitRevertHeader matches the header of a revert created by git revert, the subject of the reverted commit is quoted
+	gitRevertHeader = regexp.MustCompile(`^Revert "(.+)"$`)
+	// revertSHA matches the line git adds to the body of a revert, e.g. This reverts commit 1234567.
+	revertSHA = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-fA-F]{7,40})`)
+)
+
+// NormalizeRevert returns the message of a revert created by git revert with the header of the revert type, e.g.
+// revert: feat: add flag for Revert "feat: add flag", so that it is parsed as a conventional commit. Other messages are
+// returned unchanged.
+func NormalizeRevert(message string) string {
+	header, body, _ := strings.Cut(message, "\n")
+	match := gitRevertHeader.FindStringSubmatch(strings.TrimSpace(header))
+	if match == nil {
+		return message
+	}
+	normalized := Reverts + ": " + match[1]
+	if strings.Contains(message, "\n") {
+		normalized += "\n" + body
+	}
+	return normalized
+}
+
+// parseRevert returns the subject and the SHA of the commit reverted by the commit message, the SHA is empty if the
+// message does not name it. ok is false if the message is not a revert.
+func parseRevert(message string) (subject, sha string, ok bool) {
+	header, _, _ := strings.Cut(NormalizeRevert(message), "\n")
+	match := revertHeader.FindStringSubmatch(strings.TrimSpace(header))
+	if match == nil {
+		return "", "", false
+	}
+	if m := revertSHA.FindStringSubmatch(message); m != nil {
+		sha = strings.ToLower(m[1])
+	}
+	return strings.TrimSpace(match[1]), sha, true
+}
+
+// pairReverts cancels out the reverts of the classifications, ordered newest first, with the commits they revert: the
+// older commit whose SHA starts with the SHA of the revert, or else the older commit with the subject of the revert.
+// Both are not accounted for. A revert of a revert cancels out first, so the originally reverted commit is kept.
+func pairReverts(classified []Classification) {
+	for i := range classified {
+		revert := &classified[i]
+		if revert.Type == "" || revert.Reverted != nil {
+			continue
+		}
+		subject, sha, ok := parseRevert(revert.Commit.GetCommit().GetMessage())
+		if !ok {
+			continue
+		}
+		target := -1
+		for j := i + 1; j < len(classified) && target < 0; j++ {
+			if classified[j].Reverted == nil && sha != "" && strings.HasPrefix(strings.ToLower(classified[j].Commit.GetSHA()), sha) {
+				target = j
+			}
+		}
+		for j := i + 1; j < len(classified) && target < 0; j++ {
+			header, _, _ := strings.Cut(classified[j].Commit.GetCommit().GetMessage(), "\n")
+			if classified[j].Reverted == nil && strings.TrimSpace(header) == subject {
+				target = j
+			}
+		}
+		if target < 0 {
+			continue
+		}
+		reverted := &classified[target]
+		revert.Type, revert.Reverted = "", reverted.Commit
+		reverted.Type, reverted.Reverted = "", revert.Commit
+	}
+}
@@ -0,0 +1,130 @@
+package conventional
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRevert(t *testing.T) {
+	testCases := []struct {
+		name    string
+		message string
+		subject string
+		sha     string
+		ok      bool
+	}{
+		{"subject", "revert: feat: add flag", "feat: add flag", "", true},
+		{"sha", "revert(cli): feat(cli): add flag\n\nThis reverts commit 1234567ABCDEF.", "feat(cli): add flag", "1234567abcdef", true},
+		{"git revert", "Revert \"feat: add flag\"\n\nThis reverts commit aaaaaaa111.", "feat: add flag", "aaaaaaa111", true},
+		{"not a revert", "fix: revert the flag", "", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subject, sha, ok := parseRevert(tc.message)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.subject, subject)
+			assert.Equal(t, tc.sha, sha)
+		})
+	}
+}
+
+func TestClassifyCommits_Reverts(t *testing.T) {
+	commit := func(sha, message string, day int) *github.RepositoryCommit {
+		return &github.RepositoryCommit{SHA: github.String(sha), Commit: &github.Commit{
+			Message:   github.String(message),
+			Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}},
+		}}
+	}
+	feat := commit("aaaaaaa111", "feat: add flag", 1)
+	fix := commit("bbbbbbb222", "fix: bug", 2)
+	revertFeat := commit("ccccccc333", "revert: feat: add flag\n\nThis reverts commit aaaaaaa111.", 3)
+	revertFix := commit("ddddddd444", "revert: fix: bug", 4)
+	revertRevert := commit("eeeeeee555", "revert: revert: fix: bug\n\nThis reverts commit ddddddd.", 5)
+	released := commit("fffffff666", "revert: feat: released feature\n\nThis reverts commit 9999999.", 6)
+
+	classified := ClassifyCommits(map[string]*github.RepositoryCommit{
+		"1": feat, "2": fix, "3": revertFeat, "4": revertFix, "5": revertRevert, "6": released,
+	})
+	require.Len(t, classified, 6)
+	types := make(map[*github.RepositoryCommit]Classification)
+	for _, c := range classified {
+		types[c.Commit] = c
+	}
+
+	// the feature and its revert cancel out by SHA
+	assert.Equal(t, "", types[feat].Type)
+	assert.Same(t, revertFeat, types[feat].Reverted)
+	assert.Equal(t, "", types[revertFeat].Type)
+	assert.Same(t, feat, types[revertFeat].Reverted)
+	// the revert of the revert cancels out first, the fix is kept
+	assert.Same(t, revertFix, types[revertRevert].Reverted)
+	assert.Equal(t, "fix", types[fix].Type)
+	assert.Nil(t, types[fix].Reverted)
+	// the revert of a released commit is listed
+	assert.Equal(t, Reverts, types[released].Type)
+
+	parsed := CollectCommits(classified)
+	assert.Equal(t, Commits{"fix": {fix}, Reverts: {released}}, parsed)
+	assert.Equal(t, Patch, parsed.Increment())
+}
+
+func TestClassifyCommits_RevertCancelsIncrement(t *testing.T) {
+	commit := func(sha, message string, day int) *github.RepositoryCommit {
+		return &github.RepositoryCommit{SHA: github.String(sha), Commit: &github.Commit{
+			Message:   github.String(message),
+			Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}},
+		}}
+	}
+	classified := ClassifyCommits(map[string]*github.RepositoryCommit{
+		"1": commit("1111111", "feat!: drop the v1 api\n\nRelease-As: 2.0.0", 1),
+		"2": commit("2222222", "revert: feat!: drop the v1 api", 2),
+	})
+	parsed := CollectCommits(classified)
+	assert.Empty(t, parsed)
+	assert.Equal(t, None, parsed.Increment())
+
+	// the Release-As footer of a reverted commit is ignored
+	version, _, err := ReleaseAs(classified)
+	require.Nil(t, err)
+	assert.Nil(t, version)
+}
+
+func TestClassifyCommits_GitRevert(t *testing.T) {
+	commit := func(sha, message string, day int) *github.RepositoryCommit {
+		return &github.RepositoryCommit{SHA: github.String(sha), Commit: &github.Commit{
+			Message:   github.String(message),
+			Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}},
+		}}
+	}
+	feat := commit("aaaaaaa111", "feat: add flag", 1)
+	revertFeat := commit("bbbbbbb222", "Revert \"feat: add flag\"\n\nThis reverts commit aaaaaaa111.", 2)
+	released := commit("ccccccc333", "Revert \"fix: released bug\"\n\nThis reverts commit 9999999.", 3)
+
+	classified := ClassifyCommits(map[string]*github.RepositoryCommit{"1": feat, "2": revertFeat, "3": released})
+	types := make(map[*github.RepositoryCommit]Classification)
+	for _, c := range classified {
+		types[c.Commit] = c
+	}
+
+	// the feature and its revert cancel out by SHA
+	assert.Same(t, revertFeat, types[feat].Reverted)
+	assert.Same(t, feat, types[revertFeat].Reverted)
+	// the revert of a released commit is listed
+	assert.Equal(t, Reverts, types[released].Type)
+	assert.Nil(t, types[released].Err)
+
+	parsed := CollectCommits(classified)
+	assert.Equal(t, Commits{Reverts: {released}}, parsed)
+	assert.Equal(t, Patch, parsed.Increment())
+}
+
+func TestNormalizeRevert(t *testing.T) {
+	assert.Equal(t, "revert: feat: add flag\n\nThis reverts commit aaaaaaa.", NormalizeRevert("Revert \"feat: add flag\"\n\nThis reverts commit aaaaaaa."))
+	assert.Equal(t, "revert: feat: add flag", NormalizeRevert("Revert \"feat: add flag\""))
+	assert.Equal(t, "fix: revert the flag", NormalizeRevert("fix: revert the flag"))
+}
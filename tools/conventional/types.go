@@ -0,0 +1,99 @@
+package conventional
+
+import (
+	"fmt"
+	"github.com/google/go-github/v58/github"
+	"sort"
+)
+
+// Breaking is the name of the section of breaking changes, the commits of any type with a ! after the type or scope or
+// with a BREAKING CHANGE footer.
+const Breaking = "breaking"
+
+// Type is a conventional commit type, or the Breaking section, and how its commits are released.
+type Type struct {
+	Name   string    // the commit type, e.g. feat
+	Title  string    // the title of the changelog section
+	Order  int       // changelog sections are ordered by ascending order
+	Hidden bool      // commits of hidden types are left out of the changelog, they still increment the version
+	Bump   Increment // the version increment required by commits of the type, None if they do not require one
+}
+
+// Types are the commit types that are recognized. Commits of other types are not accounted for.
+var Types = DefaultTypes()
+
+// DefaultTypes returns the commit types recognized when no types are configured.
+func DefaultTypes() []Type {
+	return []Type{
+		{Name: Breaking, Title: "⚠ BREAKING CHANGES", Order: 0, Bump: Major},
+		{Name: "feat", Title: "Features", Order: 10, Bump: Minor},
+		{Name: "fix", Title: "Fixes", Order: 20, Bump: Patch},
+		{Name: Reverts, Title: "Reverts", Order: 25, Bump: Patch}, // reverts of commits released before
+		{Name: "docs", Title: "Documentation", Order: 30, Bump: None},
+		{Name: "style", Title: "Styles", Order: 40, Bump: None},
+		{Name: "refactor", Title: "Refactors", Order: 50, Bump: None},
+		{Name: "perf", Title: "Performance", Order: 60, Bump: None},
+		{Name: "test", Title: "Test", Order: 70, Bump: None},
+		{Name: "build", Title: "Build", Order: 80, Bump: None},
+		{Name: "ci", Title: "CI/CD", Order: 90, Bump: None},
+		{Name: "debug", Title: "Debugging", Order: 100, Bump: None},
+		{Name: Dependencies, Title: "Dependencies", Order: 105, Bump: None}, // the increment is decided by Policy
+		{Name: "chore", Title: "Chores", Order: 110, Bump: None},
+	}
+}
+
+// LookupType returns the recognized commit type with the name.
+func LookupType(name string) (Type, bool) {
+	for _, t := range Types {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// ParseIncrement parses the name of a version increment, one of major, minor, patch or none.
+func ParseIncrement(name string) (Increment, error) {
+	switch name {
+	case "major":
+		return Major, nil
+	case "minor":
+		return Minor, nil
+	case "patch":
+		return Patch, nil
+	case "none":
+		return None, nil
+	}
+	return None, fmt.Errorf("unknown increment %q, expected one of major, minor, patch, none", name)
+}
+
+// String returns the name of the increment, the inverse of ParseIncrement.
+func (i Increment) String() string {
+	switch i {
+	case Major:
+		return "major"
+	case Minor:
+		return "minor"
+	case Patch:
+		return "patch"
+	}
+	return "none"
+}
+
+// Section is the commits of a commit type, in the order they appear in the changelog.
+type Section struct {
+	Type
+	Commits []*github.RepositoryCommit
+}
+
+// Sections returns the changelog sections of the commits, a section for each type that is not hidden and has commits,
+// ordered by the order of the types.
+func (c Commits) Sections() (sections []Section) {
+	for _, t := range Types {
+		if !t.Hidden && len(c[t.Name]) > 0 {
+			sections = append(sections, Section{t, c[t.Name]})
+		}
+	}
+	sort.SliceStable(sections, func(i, j int) bool { return sections[i].Order < sections[j].Order })
+	return
+}
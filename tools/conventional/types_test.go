@@ -0,0 +1,195 @@
+package conventional
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withTypes(t *testing.T, types []Type) {
+	original := Types
+	Types = types
+	t.Cleanup(func() { Types = original })
+}
+
+func TestParseCommits_CustomTypes(t *testing.T) {
+	withTypes(t, append(DefaultTypes(),
+		Type{Name: "security", Title: "Security", Order: 5, Bump: Patch},
+		Type{Name: "deps", Title: "Dependencies", Order: 200, Hidden: true, Bump: Patch},
+	))
+	commit := func(message string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{
+			Commit: &github.Commit{
+				Message:   github.String(message),
+				Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: time.Now()}},
+			},
+		}
+	}
+
+	parsed := ParseCommits(map[string]*github.RepositoryCommit{
+		"1": commit("security: sanitize input"),
+		"2": commit("deps: bump zerolog"),
+		"3": commit("docs: explain types"),
+		"4": commit("unknown: dropped"),
+	})
+	assert.Len(t, parsed["security"], 1)
+	assert.Len(t, parsed["deps"], 1)
+	assert.Len(t, parsed["docs"], 1)
+	assert.NotContains(t, parsed, "unknown")
+	assert.Equal(t, Patch, parsed.Increment())
+
+	sections := parsed.Sections()
+	require.Len(t, sections, 2)
+	assert.Equal(t, "Security", sections[0].Title)
+	assert.Equal(t, "Documentation", sections[1].Title)
+
+	assert.Equal(t, None, ParseCommits(map[string]*github.RepositoryCommit{"1": commit("docs: explain types")}).Increment())
+}
+
+func TestIncrement(t *testing.T) {
+	commits := []*github.RepositoryCommit{{Commit: &github.Commit{Message: github.String("type: message")}}}
+	testCases := []struct {
+		name     string
+		commits  Commits
+		expected Increment
+	}{
+		{"none", Commits{}, None},
+		{"no bump", Commits{"docs": commits, "chore": commits}, None},
+		{"patch", Commits{"fix": commits, "docs": commits}, Patch},
+		{"minor", Commits{"fix": commits, "feat": commits}, Minor},
+		{"major", Commits{Breaking: commits, "feat": commits}, Major},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.commits.Increment())
+		})
+	}
+}
+
+func TestIncrementTrigger(t *testing.T) {
+	commit := func(message string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{Commit: &github.Commit{Message: github.String(message)}}
+	}
+	newFeat, oldFeat, fix := commit("feat: new"), commit("feat: old"), commit("fix: bug")
+	newDeps, oldDeps, chore := commit("chore(deps): new"), commit("chore(deps): old"), commit("chore: tidy")
+	testCases := []struct {
+		name      string
+		commits   Commits
+		increment Increment
+		trigger   *github.RepositoryCommit
+	}{
+		{"none", Commits{"chore": {chore}}, None, nil},
+		{"oldest of the type", Commits{"feat": {newFeat, oldFeat}, "fix": {fix}}, Minor, oldFeat},
+		{"dependency update", Commits{Dependencies: {newDeps, oldDeps}, "chore": {chore}}, Patch, oldDeps},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			increment, trigger := tc.commits.IncrementTrigger()
+			assert.Equal(t, tc.increment, increment)
+			assert.Same(t, tc.trigger, trigger)
+		})
+	}
+}
+
+func TestClassifyCommits(t *testing.T) {
+	commit := func(message string, day int) *github.RepositoryCommit {
+		return &github.RepositoryCommit{Commit: &github.Commit{
+			Message:   github.String(message),
+			Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}},
+		}}
+	}
+	commits := map[string]*github.RepositoryCommit{
+		"1": commit("feat: feature", 1),
+		"2": commit("fix!: breaking fix", 2),
+		"3": commit("wip: unknown type", 3),
+		"4": commit("not conventional", 4),
+	}
+
+	classified := ClassifyCommits(commits)
+	require.Len(t, classified, 4)
+	for i, c := range classified {
+		assert.Same(t, commits[fmt.Sprint(4-i)], c.Commit, "newest first")
+	}
+	assert.Equal(t, Classification{Commit: commits["1"], Type: "feat", Parsed: "feat"}, classified[3])
+	assert.Equal(t, Classification{Commit: commits["2"], Type: Breaking, Parsed: "fix", Breaking: true}, classified[2])
+	assert.Equal(t, Classification{Commit: commits["3"], Parsed: "wip"}, classified[1])
+	assert.Equal(t, "", classified[0].Type)
+	assert.Equal(t, "", classified[0].Parsed)
+	assert.NotNil(t, classified[0].Err)
+}
+
+func TestReleaseAs(t *testing.T) {
+	commit := func(message string, day int) *github.RepositoryCommit {
+		return &github.RepositoryCommit{SHA: github.String(fmt.Sprint(day)), Commit: &github.Commit{
+			Message:   github.String(message),
+			Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}},
+		}}
+	}
+	older, newer := commit("feat: older\n\nRelease-As: 2.0.0", 1), commit("fix: newer\n\nRelease-As: v3.0.0", 2)
+	classified := ClassifyCommits(map[string]*github.RepositoryCommit{"1": older, "2": newer, "3": commit("chore: none", 3)})
+	assert.Equal(t, "2.0.0", classified[2].ReleaseAs)
+
+	version, trigger, err := ReleaseAs(classified)
+	require.Nil(t, err)
+	assert.Equal(t, "3.0.0", version.String())
+	assert.Same(t, newer, trigger)
+
+	version, trigger, err = ReleaseAs(ClassifyCommits(map[string]*github.RepositoryCommit{"1": commit("feat: none", 1)}))
+	require.Nil(t, err)
+	assert.Nil(t, version)
+	assert.Nil(t, trigger)
+
+	_, _, err = ReleaseAs(ClassifyCommits(map[string]*github.RepositoryCommit{"1": commit("feat: invalid\n\nRelease-As: next", 1)}))
+	require.EqualError(t, err, `invalid Release-As footer of commit 1: "next" is not a semantic version: Invalid Semantic Version`)
+}
+
+func TestParseReleaseAs(t *testing.T) {
+	testCases := []struct {
+		value string
+		want  string
+		err   string
+	}{
+		{"3.0.0", "3.0.0", ""},
+		{"v3.1.0", "3.1.0", ""},
+		{"3.0.0-rc.1", "", `"3.0.0-rc.1" is not a release version, the prerelease number is derived from the branch`},
+		{"3.0.0+build", "", `"3.0.0+build" is not a release version, the prerelease number is derived from the branch`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			version, err := ParseReleaseAs(tc.value)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tc.want, version.String())
+		})
+	}
+}
+
+func TestParseIncrement(t *testing.T) {
+	for name, expected := range map[string]Increment{"major": Major, "minor": Minor, "patch": Patch, "none": None} {
+		increment, err := ParseIncrement(name)
+		require.Nil(t, err)
+		assert.Equal(t, expected, increment)
+	}
+
+	_, err := ParseIncrement("micro")
+	require.EqualError(t, err, `unknown increment "micro", expected one of major, minor, patch, none`)
+}
+
+func TestLookupType(t *testing.T) {
+	feat, ok := LookupType("feat")
+	require.True(t, ok)
+	assert.Equal(t, Type{Name: "feat", Title: "Features", Order: 10, Bump: Minor}, feat)
+
+	_, ok = LookupType("security")
+	require.False(t, ok)
+}
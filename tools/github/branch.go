@@ -2,9 +2,11 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/go-github/v58/github"
 	"github.com/rs/zerolog/log"
+	"net/http"
 )
 
 // Branch is a struct that contains the RepositoriesService, context, token, owner, name, and branch. It is used to
@@ -14,8 +16,9 @@ type Branch struct {
 	RepositoryMetadata RepositoryMetadata
 	GitService
 	*github.Branch
-	Ctx  context.Context
-	Name string
+	Ctx        context.Context
+	Name       string
+	repository *Repository
 }
 
 // GetDistinctCommits returns a map of unique commits from the base branch to the head branch
@@ -67,15 +70,22 @@ func (b *Branch) GetCommitsSinceCommit(hash *string) (map[string]*github.Reposit
 			commits[*commit.SHA] = commit
 		}
 
-		if response != nil {
-			if nextPage = response.NextPage; nextPage == 0 {
-				break // break if there are no more pages
-			}
+		if response == nil {
+			break
+		}
+		if nextPage = response.NextPage; nextPage == 0 {
+			break // break if there are no more pages
 		}
 	}
 	return commits, nil
 }
 
+// NearestVersion returns the Version with the release tag nearest to the branch tip in its history. See
+// Repository.NearestVersion.
+func (b *Branch) NearestVersion() (*Version, error) {
+	return b.repository.NearestVersion(b.Name)
+}
+
 // GetLastCommitMessage retrieves the last commit message from the branch
 func (b *Branch) GetLastCommitMessage() (string, error) {
 	commits, _, err := b.ListCommits(b.Ctx, b.RepositoryMetadata.Owner, b.RepositoryMetadata.Name, &github.CommitsListOptions{
@@ -93,6 +103,22 @@ func (b *Branch) GetLastCommitMessage() (string, error) {
 	return *commits[0].Commit.Message, nil
 }
 
+// GetFile returns the content of the file at path on the branch, or FileNotFound if the branch has no such file.
+func (b *Branch) GetFile(path string) (string, error) {
+	file, _, _, err := b.GetContents(b.Ctx, b.RepositoryMetadata.Owner, b.RepositoryMetadata.Name, path, &github.RepositoryContentGetOptions{Ref: b.Name})
+	var response *github.ErrorResponse
+	if errors.As(err, &response) && response.Response != nil && response.Response.StatusCode == http.StatusNotFound {
+		return "", FileNotFound{Path: path, Branch: b.Name}
+	}
+	if err != nil {
+		return "", err
+	}
+	if file == nil { // a directory
+		return "", fmt.Errorf("%s is not a file on branch %s", path, b.Name)
+	}
+	return file.GetContent()
+}
+
 func (b *Branch) Reset(sha *string) error {
 	ref := &github.Reference{Ref: github.String("refs/heads/" + b.Name), Object: &github.GitObject{SHA: sha}}
 	_, _, err := b.UpdateRef(b.Ctx, b.RepositoryMetadata.Owner, b.RepositoryMetadata.Name, ref, true)
@@ -11,6 +11,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"io"
 	"os"
+	"strings"
 )
 
 type Handler struct {
@@ -26,95 +27,217 @@ type Handler struct {
 	Latest               *github.Version
 	LatestPrerelease     *github.Version
 	CommitFiles          []string
+	PreMajor             bool            // below 1.0.0 breaking changes increment the minor and features the patch version
+	Graduate             bool            // propose 1.0.0 when the latest version is below 1.0.0
+	ReleaseAs            *semver.Version // propose the version instead of the computed version, see gatherReleaseAs
+	ReleaseBranchPrefix  string          // prefix of the release branches, ReleaseBranchPrefix if empty
 
+	classified      []conventional.Classification // the commits of the release, including those not accounted for
 	commits         *conventional.Commits
+	next            *semver.Version
+	releaseAs       *semver.Version // the version the next version is forced to, see gatherReleaseAs
+	releaseAsBy     string          // what forced the next version, e.g. the Release-As footer of a commit
 	title           string
 	body            changelog.Markdown
 	latestChangelog changelog.Markdown
 	fullChangelog   changelog.Markdown
+	releases        changelog.Markdown // the releases file with the next version, see changelog.JSON
 
 	inner     error
 	promotion bool
 
-	Trigger string
+	Trigger      string
+	Released     *github.RepositoryRelease
+	ReleaseNotes changelog.Markdown // the notes of the proposed or released version
+	Component    *Component         // the component versioned by the handler, nil for the whole repository
+	DryRun       bool               // record the writes in Plan instead of making them
+	Plan         *Plan              // what PullRequest or Release computed and would write, set when DryRun is set
 }
 
+// Component is a part of the repository, e.g. a Go module or a service in a monorepo, that is versioned independently.
+// The commits of a component are the commits changing files within its Path, its versions are tagged with TagPrefix
+// (e.g. api/v1.4.0) and its changelog is written to Changelog.
+type Component struct {
+	Name      string
+	Path      string
+	TagPrefix string
+	Changelog string
+}
+
+// ForComponent returns a handler for the component with the settings of h.
+func (h *Handler) ForComponent(component Component) *Handler {
+	client := *h.Client
+	client.TagPrefix = component.TagPrefix
+	return &Handler{
+		Client:               &client,
+		Owner:                h.Owner,
+		Name:                 h.Name,
+		Head:                 h.Head,
+		Base:                 h.Base,
+		PrereleaseIdentifier: h.PrereleaseIdentifier,
+		ReleaseBranch:        h.ReleaseBranch,
+		CommitFiles:          h.CommitFiles,
+		PreMajor:             h.PreMajor,
+		Graduate:             h.Graduate,
+		ReleaseAs:            h.ReleaseAs,
+		ReleaseBranchPrefix:  h.ReleaseBranchPrefix,
+		Trigger:              h.Trigger,
+		Component:            &component,
+		DryRun:               h.DryRun,
+	}
+}
+
+// Wrapper runs f unless a previous step failed, the first error is kept and returned by PullRequest.
 func (h *Handler) Wrapper(f func() error) {
-	if h.inner != nil {
+	if h.inner == nil {
 		h.inner = f()
 	}
 }
 
-const releaseBranchPrefix = "release--branch--"
+// ReleaseBranchPrefix is the default prefix of the release branch created for a base branch, e.g. release--branch--main
+const ReleaseBranchPrefix = "release--branch--"
+
+// releaseBranch returns the name of the release branch for the branch, release branches of a component include its
+// name, e.g. release--branch--api--main
+func (h *Handler) releaseBranch(branch string) string {
+	prefix := h.ReleaseBranchPrefix
+	if prefix == "" {
+		prefix = ReleaseBranchPrefix
+	}
+	if h.Component != nil {
+		return prefix + h.Component.Name + "--" + branch
+	}
+	return prefix + branch
+}
+
+// changelog returns the changelog file of the repository or of the component.
+func (h *Handler) changelog() changelog.File {
+	if h.Component != nil {
+		return changelog.File{Path: h.Component.Changelog, TagPrefix: h.Component.TagPrefix}
+	}
+	return changelog.Default()
+}
+
+// releaseNotes returns the path of the file the release notes are written to.
+func (h *Handler) releaseNotes() string {
+	if h.Component != nil {
+		return "release-" + h.Component.Name + ".txt"
+	}
+	return "release.txt"
+}
+
+// releaseDocument returns the path of the file the JSON release document is written to, next to the release notes.
+func (h *Handler) releaseDocument() string {
+	return strings.TrimSuffix(h.releaseNotes(), ".txt") + ".json"
+}
+
+// Tag returns the name of the tag for the version, including the tag prefix of the component.
+func (h *Handler) Tag(version *semver.Version) string {
+	return h.changelog().Tag(version)
+}
 
-func (h *Handler) gatherVersions() {
-	var err error
-	h.Latest, err = h.Repository().LatestVersion()
+// gatherVersions sets the latest release version as the release tag nearest to the head branch in its history, and the
+// latest prerelease version as the highest prerelease tag in the repository for the prerelease identifier, so that the
+// next prerelease number is not taken by a tag on another line of history.
+func (h *Handler) gatherVersions() error {
+	head, err := h.Repository().Branch(h.Head)
 	if err != nil {
-		if errors.Is(err, github.NoReleaseVersionFound{}) {
-			log.Warn().Err(err).Msg("No release version found, semver-action will behave as if the next version should be v0.0.0")
-		} else {
-			panic(err)
-		}
+		return fmt.Errorf("failed to get branch %s: %w", h.Head, err)
+	}
+	h.Latest, err = head.NearestVersion()
+	if errors.Is(err, github.NoReleaseVersionFound{}) {
+		log.Warn().Err(err).Msg("No release version found, semver-action will behave as if the next version should be v0.0.0")
+	} else if err != nil {
+		return fmt.Errorf("failed to find the latest version: %w", err)
 	}
 
 	if h.PrereleaseIdentifier != "" {
 		h.LatestPrerelease, err = h.Repository().LatestPrereleaseVersion(h.PrereleaseIdentifier)
 		if err != nil && !errors.Is(err, github.NoPrereleaseVersionFound{}) {
-			panic(err)
+			return fmt.Errorf("failed to find the latest %s prerelease version: %w", h.PrereleaseIdentifier, err)
 		}
 	}
+	return nil
 }
 
-func (h *Handler) Commits() (commits *conventional.Commits) {
-	if h.commits == nil {
-		var err error
-		var raw map[string]*github.RepositoryCommit
+// gatherCommits parses the commits of the head branch since the latest version, or that are not on the release branch.
+func (h *Handler) gatherCommits() error {
+	head, err := h.head()
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]*github.RepositoryCommit
+	if h.sinceLatest() {
 		var sha *string
-		if h.Head == h.ReleaseBranch {
-			if h.Latest != nil {
-				sha = h.Latest.Commit.SHA
-			}
-			raw, err = h.head().GetCommitsSinceCommit(sha)
-			if err != nil {
-				panic(err)
-			}
-		} else {
-			raw, err = h.head().GetDistinctCommits(h.ReleaseBranch)
-			if err != nil {
-				panic(err)
-			}
+		if h.Latest != nil {
+			sha = h.Latest.Commit.SHA
 		}
-		c := conventional.ParseCommits(raw)
-		h.commits = &c
+		raw, err = head.GetCommitsSinceCommit(sha)
+	} else {
+		raw, err = head.GetDistinctCommits(h.ReleaseBranch)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the commits of %s: %w", head.GetName(), err)
+	}
+	if h.Component != nil {
+		raw, err = h.Repository().CommitsInPath(raw, h.Component.Path)
+		if err != nil {
+			return err
+		}
+	}
+	h.classified = conventional.ClassifyCommits(raw)
+	c := conventional.CollectCommits(h.classified)
+	h.commits = &c
+	return nil
+}
+
+// sinceLatest reports whether the commits of the release are the commits since the latest version, which is the case
+// when the release branch is versioned or prereleases are promoted to it, so that the release covers every change since
+// the latest release version. Otherwise they are the commits that are not on the release branch.
+func (h *Handler) sinceLatest() bool {
+	return h.Head == h.ReleaseBranch || h.promotes()
+}
+
+// promotes reports whether the prereleases of the head branch are promoted to a release of the release branch.
+func (h *Handler) promotes() bool {
+	return h.Head != h.Base && h.Base == h.ReleaseBranch
+}
+
+// Commits returns the parsed commits, they are gathered by PullRequest and Release.
+func (h *Handler) Commits() *conventional.Commits {
+	if h.commits == nil {
+		return &conventional.Commits{}
 	}
 	return h.commits
 }
 
-func (h *Handler) base() *github.Branch {
+func (h *Handler) base() (*github.Branch, error) {
 	if h.bb == nil {
 		var err error
 		h.bb, err = h.Repository().Branch(h.Base)
 		if err != nil {
-			panic(err)
+			return nil, fmt.Errorf("failed to get branch %s: %w", h.Base, err)
 		}
 	}
-	return h.bb
+	return h.bb, nil
 }
 
-func (h *Handler) head() *github.Branch {
+func (h *Handler) head() (*github.Branch, error) {
 	if h.hb == nil {
-		branchName := releaseBranchPrefix + h.Head
+		branchName := h.releaseBranch(h.Head)
 		if h.Head != h.Base { // release branch generated off the base branch
-			branchName = releaseBranchPrefix + h.Base
+			branchName = h.releaseBranch(h.Base)
 			h.promotion = true
 		}
-		h.setBranch(branchName)
+		if err := h.setBranch(branchName); err != nil {
+			return nil, err
+		}
 	}
-	return h.hb
+	return h.hb, nil
 }
 
-// PullRequest creates a release--branch--{branchName} pull request for branchName
+// PullRequest creates a {ReleaseBranchPrefix}{branchName} pull request for branchName
 // PR Details:
 // - title: "release({branchName}): {nextVersion}"
 // - base: {branchName}
@@ -123,43 +246,189 @@ func (h *Handler) head() *github.Branch {
 //   - if prerelease: ":robot: I have created a release candidate *beep* *boop*"
 //   - else: ":robot: I have created a release *beep* *boop*"
 func (h *Handler) PullRequest() error {
-	h.gatherVersions()
-	if h.Commits().Increment() == -1 && h.Latest != nil && h.Latest.Version != nil {
+	if h.DryRun {
+		h.Plan = &Plan{}
+		defer h.recordState()
+	}
+	h.Wrapper(h.gatherVersions)
+	h.Wrapper(h.gatherCommits)
+	h.Wrapper(h.gatherNextVersion)
+	if h.inner == nil && !h.Proposed() {
 		log.Info().Msg("No version increment necessary")
 		return nil
 	}
-	h.gatherChangelog()
-	h.composePullRequest()
-
+	h.Wrapper(func() error { return h.checkVersionAvailable(h.NextVersion()) })
+	h.Wrapper(h.gatherChangelog)
+	h.Wrapper(h.composePullRequest)
 	if h.promotion || h.Trigger != "release" {
-		h.commitChangelog()
-		h.setPullRequest()
+		h.Wrapper(h.commitChangelog)
+		h.Wrapper(h.setPullRequest)
 	}
+	h.Wrapper(func() (err error) {
+		h.ReleaseNotes, err = changelog.RenderWith(changelog.ReleaseNotesTemplate, h.changelogData(h.NextVersion()), h.latestChangelog)
+		if err != nil {
+			return err
+		}
+		if err = h.exportRelease(h.NextVersion()); err != nil {
+			return err
+		}
+		return h.writeFile(h.releaseNotes(), h.ReleaseNotes)
+	})
+
+	return h.inner
+}
+
+// Proposed reports whether a new version is proposed, which is the case when the commits require a version increment,
+// there is no release version yet or the version is forced.
+func (h *Handler) Proposed() bool {
+	return h.Commits().Increment() != conventional.None || h.Latest == nil || h.Latest.Version == nil || h.graduates(h.Graduate) ||
+		h.releaseAs != nil
+}
+
+// graduates reports whether the version graduates to 1.0.0 when graduate is set.
+func (h *Handler) graduates(graduate bool) bool {
+	return graduate && (h.Latest == nil || h.Latest.Version == nil || h.Latest.Major() == 0)
+}
 
-	err := changelog.WriteToFile("release.txt", h.latestChangelog)
+// Release tags and publishes a GitHub release when the head of the base branch is the merge commit of a
+// release--branch--{base} pull request. The tag is created on the merge commit and the release notes are the changelog
+// for the released version. If the head of the base branch is not a merged release pull request, nothing is released.
+func (h *Handler) Release() error {
+	if h.DryRun {
+		h.Plan = &Plan{}
+		defer h.recordState()
+	}
+	base, err := h.base()
 	if err != nil {
 		return err
 	}
+	sha := base.Commit.GetSHA()
+	pr, err := h.GetMergedPullRequest(h.releaseBranch(h.Base), h.Base, sha)
+	if errors.Is(err, github.NoPullRequestFoundError{Head: h.releaseBranch(h.Base), Base: h.Base}) {
+		log.Info().Msgf("No release pull request was merged as %s, nothing to release", sha)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to find merged release pull request: %w", err)
+	}
 
-	return h.inner
+	h.hb = base // the release branch has been merged, the commits are read from the base branch
+	h.Wrapper(h.gatherVersions)
+	h.Wrapper(h.gatherCommits)
+	h.Wrapper(h.gatherNextVersion)
+	if h.inner != nil {
+		return h.inner
+	}
+	version := h.NextVersion()
+	tag := h.Tag(version)
+	if proposed := strings.SplitN(pr.GetTitle(), ": ", 2); len(proposed) == 2 && proposed[1] != tag {
+		if version, err = h.graduated(proposed[1]); err != nil {
+			return err
+		}
+		if version == nil {
+			return VersionMismatchError{Number: pr.GetNumber(), Proposed: proposed[1], Computed: tag}
+		}
+		log.Info().Msgf("The release pull request graduates to %s", proposed[1])
+		h.next, tag = version, h.Tag(version)
+	}
+	if err = h.checkVersionAvailable(version); err != nil {
+		return err
+	}
+	h.latestChangelog, err = changelog.Render(changelog.ChangelogTemplate, h.changelogData(version))
+	if err != nil {
+		return err
+	}
+	h.ReleaseNotes, err = changelog.RenderWith(changelog.ReleaseNotesTemplate, h.changelogData(version), h.latestChangelog)
+	if err != nil {
+		return err
+	}
+	if err = h.exportRelease(version); err != nil {
+		return err
+	}
+
+	if h.DryRun {
+		h.Plan.Tag = &PlanTag{Name: tag, SHA: sha}
+		h.Plan.Release = &PlanRelease{Tag: tag, Prerelease: version.IsPrerelease(), Notes: h.ReleaseNotes.String()}
+		return h.writeFile(h.releaseNotes(), h.ReleaseNotes)
+	}
+
+	log.Info().Msgf("Tagging %s as %s", sha, tag)
+	if err = h.Repository().CreateTag(tag, &sha); err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", tag, err)
+	}
+
+	release, err := h.Repository().PublishRelease(tag, h.ReleaseNotes, version.IsPrerelease())
+	if err != nil {
+		return fmt.Errorf("failed to publish release %s: %w", tag, err)
+	}
+	log.Info().Msgf("Published release %s", release.GetHTMLURL())
+	h.Released = release
+
+	return h.writeFile(h.releaseNotes(), h.ReleaseNotes)
+}
+
+// graduated returns the version graduated to 1.0.0 if it is the proposed tag, a release pull request opened with
+// Graduate set is released as proposed. Nil is returned if the proposed tag is not the graduated version or the
+// handler graduates already.
+func (h *Handler) graduated(proposed string) (*semver.Version, error) {
+	if h.Graduate || !h.graduates(true) {
+		return nil, nil
+	}
+	version, err := conventional.IncVersion(h.VersionInfo(), h.versionConfig(true), h.Commits().Increment())
+	if err != nil || h.Tag(version) != proposed {
+		return nil, err
+	}
+	return version, nil
+}
+
+// checkVersionAvailable returns a VersionAlreadyExists error if the version has already been tagged in the repository,
+// this guards against proposing or releasing a version that already exists.
+func (h *Handler) checkVersionAvailable(version *semver.Version) error {
+	exists, err := h.Repository().VersionExists(version)
+	if err != nil {
+		return fmt.Errorf("failed to verify if version v%s exists: %w", version.String(), err)
+	}
+	if exists {
+		return github.VersionAlreadyExists{Version: version.String(), TagPrefix: h.changelog().TagPrefix}
+	}
+	return nil
 }
 
-func (h *Handler) setPullRequest() {
-	err := h.SetPullRequest(h.head().Name, h.base().Name, h.title, false, func(_ *string) (changelog.Markdown, error) {
+func (h *Handler) setPullRequest() error {
+	head, err := h.head()
+	if err != nil {
+		return err
+	}
+	base, err := h.base()
+	if err != nil {
+		return err
+	}
+	if h.DryRun {
+		h.Plan.PullRequest = &PlanPR{Head: h.Plan.Branch.Name, Base: base.Name, Title: h.title, Body: h.body.String()}
+		return nil
+	}
+	err = h.SetPullRequest(head.Name, base.Name, h.title, false, func(_ *string) (changelog.Markdown, error) {
 		return h.body, nil
 	})
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to set the release pull request: %w", err)
 	}
+	return nil
 }
 
-func (h *Handler) setBranch(name string) {
+// setBranch sets the head branch to the branch with the name, which is created or reset to the head commit of Head.
+// With DryRun the head branch is Head itself, which has the commits the branch would be reset to.
+func (h *Handler) setBranch(name string) error {
 	head, err := h.Repository().Branch(h.Head)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to get branch %s: %w", h.Head, err)
 	}
 
 	branch, err := h.Repository().Branch(name)
+	if h.DryRun && (err == nil || errors.Is(err, github.BranchNotFound{Name: name})) {
+		h.Plan.Branch = &PlanBranch{Name: name, From: h.Head, SHA: head.Commit.GetSHA(), Create: err != nil}
+		h.hb = head
+		return nil
+	}
 	if errors.Is(err, github.BranchNotFound{Name: name}) {
 		h.hb, err = h.Repository().CreateBranch(name, head.Commit.SHA)
 	} else if err == nil {
@@ -167,11 +436,12 @@ func (h *Handler) setBranch(name string) {
 		err = h.hb.Reset(head.Commit.SHA)
 	}
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to set branch %s: %w", name, err)
 	}
+	return nil
 }
 
-func (h *Handler) updateAdditionalFiles(files []github.File) []github.File {
+func (h *Handler) updateAdditionalFiles(files []github.File) ([]github.File, error) {
 	for _, path := range h.CommitFiles {
 		err := utility.Open(path, func(file *os.File) error {
 			content, err := io.ReadAll(file)
@@ -179,49 +449,116 @@ func (h *Handler) updateAdditionalFiles(files []github.File) []github.File {
 			return err
 		})
 		if err != nil {
-			panic(err)
+			return nil, fmt.Errorf("failed to read commit file: %w", err)
 		}
 	}
-	return files
+	return files, nil
 }
 
-func (h *Handler) commitChangelog() {
+func (h *Handler) commitChangelog() error {
 	log.Info().Msg("Committing changelog")
-	files := []github.File{{Path: "CHANGELOG.md", Content: h.fullChangelog.String()}}
-	files = h.updateAdditionalFiles(files)
+	files := []github.File{{Path: h.changelog().Path, Content: h.fullChangelog.String()}}
+	if h.releases != nil {
+		files = append(files, github.File{Path: h.changelog().ExportPath(), Content: h.releases.String()})
+	}
+	files, err := h.updateAdditionalFiles(files)
+	if err != nil {
+		return err
+	}
+	head, err := h.head()
+	if err != nil {
+		return err
+	}
+	message := h.title
+	// the footer carries the version to the release of a pull request merged with a merge commit, squash merges drop it
+	// and the version is passed to the release command with --release-as instead
+	if h.ReleaseAs != nil {
+		message += fmt.Sprintf("\n\nRelease-As: %s", h.ReleaseAs)
+	}
+	if h.DryRun {
+		h.Plan.Commit = &PlanCommit{Branch: h.Plan.Branch.Name, Message: message}
+		for _, file := range files {
+			h.Plan.Commit.Files = append(h.Plan.Commit.Files, PlanFile{Path: file.Path, Content: file.Content})
+		}
+		return nil
+	}
 
-	newTreeSHA, parentCommitSHA, err := h.head().AddFiles(files)
+	newTreeSHA, parentCommitSHA, err := head.AddFiles(files)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to add the changelog to %s: %w", head.GetName(), err)
 	}
 
-	err = h.head().CommitChanges(newTreeSHA, parentCommitSHA, h.title)
+	err = head.CommitChanges(newTreeSHA, parentCommitSHA, message)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to commit the changelog to %s: %w", head.GetName(), err)
 	}
+	return nil
 }
 
-func (h *Handler) gatherChangelog() {
-	var err error
-	h.latestChangelog, h.fullChangelog, err = changelog.WriteChangelog(h.Owner, h.Name, h.VersionInfo().CurrentVersion, h.NextVersion(), *h.Commits(), false)
+func (h *Handler) gatherChangelog() (err error) {
+	h.latestChangelog, err = changelog.Render(changelog.ChangelogTemplate, h.changelogData(h.NextVersion()))
 	if err != nil {
-		panic(err)
+		return err
+	}
+	h.fullChangelog, err = h.changelog().Prepend(h.NextVersion(), h.latestChangelog)
+	if err != nil {
+		return fmt.Errorf("failed to compose the changelog: %w", err)
+	}
+	if err = h.writeFile(h.changelog().Path, h.fullChangelog); err != nil || !changelog.JSON {
+		return err
 	}
+	releases, err := h.changelog().AddRelease(h.export(h.NextVersion()))
+	if err != nil {
+		return fmt.Errorf("failed to compose the releases file: %w", err)
+	}
+	if h.releases, err = changelog.MarshalJSON(releases); err != nil {
+		return err
+	}
+	return h.writeFile(h.changelog().ExportPath(), h.releases)
+}
+
+// export returns the JSON release document of the version.
+func (h *Handler) export(version *semver.Version) changelog.JSONRelease {
+	return h.changelog().Export(h.Owner, h.Name, h.VersionInfo().CurrentVersion, version, *h.Commits())
 }
 
-func (h *Handler) composePullRequest() {
-	h.title = fmt.Sprintf("release(%s): v%s", h.Base, h.NextVersion().String())
-	header := "### :robot: I have created a release candidate *beep* *boop*"
-	if h.Base == h.ReleaseBranch { // if the release branch is the target, we're promoting a release candidate to a release
-		header = "### :robot: I have created a release *beep* *boop*"
+// exportRelease writes the JSON release document of the version next to the release notes, if changelog.JSON is set.
+func (h *Handler) exportRelease(version *semver.Version) error {
+	if !changelog.JSON {
+		return nil
+	}
+	lines, err := changelog.MarshalJSON(h.export(version))
+	if err != nil {
+		return err
 	}
+	return h.writeFile(h.releaseDocument(), lines)
+}
 
-	h.body = append([]string{header,
-		"",
-	}, h.latestChangelog...)
-	// footer
-	h.body = append(h.body, "#", "",
-		"This release was composed by [version_actions](https://github.com/jakbytes/version_actions)")
+// writeFile writes the lines to the local file at path, with DryRun the file is recorded in the plan instead.
+func (h *Handler) writeFile(path string, lines changelog.Markdown) error {
+	if h.DryRun {
+		h.Plan.Files = append(h.Plan.Files, PlanFile{Path: path, Content: lines.String()})
+		return nil
+	}
+	if err := changelog.WriteToFile(path, lines); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// changelogData returns the template data for the changelog of the version.
+func (h *Handler) changelogData(version *semver.Version) changelog.Data {
+	data := h.changelog().Data(h.Owner, h.Name, h.VersionInfo().CurrentVersion, version, *h.Commits(), false)
+	data.ReleaseAs = h.releaseAsBy
+	return data
+}
+
+func (h *Handler) composePullRequest() (err error) {
+	h.title = fmt.Sprintf("release(%s): %s", h.Base, h.Tag(h.NextVersion()))
+	// the release pull request proposes a release candidate, unless the release branch is the target and a release
+	// candidate is promoted to a release
+	h.body, err = changelog.RenderWith(changelog.ReleasePullRequestTemplate, h.changelogData(h.NextVersion()), h.latestChangelog)
+	return err
 }
 
 func (h *Handler) VersionInfo() (info conventional.VersionInfo) {
@@ -236,15 +573,54 @@ func (h *Handler) VersionInfo() (info conventional.VersionInfo) {
 	return
 }
 
-func (h *Handler) NextVersion() *semver.Version {
-	version, err := conventional.IncVersion(h.VersionInfo(),
-		conventional.VersionConfig{
-			DefaultBranch:        h.ReleaseBranch,
-			BaseBranch:           h.Base,
-			PrereleaseIdentifier: h.PrereleaseIdentifier,
-		}, h.Commits().Increment())
+// gatherNextVersion computes the next version from the latest versions and the increment required by the commits, or
+// the version it is forced to.
+func (h *Handler) gatherNextVersion() (err error) {
+	if err = h.gatherReleaseAs(); err != nil {
+		return err
+	}
+	h.next, err = conventional.IncVersion(h.VersionInfo(), h.versionConfig(h.Graduate), h.Commits().Increment())
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to compute the next version: %w", err)
 	}
-	return version
+	return nil
+}
+
+// gatherReleaseAs sets the version the next version is forced to: ReleaseAs, or else the version of the Release-As footer
+// of the newest commit that has one. The forced version must be greater than the latest release version.
+func (h *Handler) gatherReleaseAs() error {
+	version, by := h.ReleaseAs, "the release-as input"
+	if version == nil {
+		var commit *github.RepositoryCommit
+		var err error
+		version, commit, err = conventional.ReleaseAs(h.classified)
+		if err != nil || version == nil {
+			return err
+		}
+		by = fmt.Sprintf("the Release-As footer of commit %s", short(commit.GetSHA()))
+	}
+	if h.Latest != nil && h.Latest.Version != nil && !version.GreaterThan(h.Latest.Version) {
+		return ReleaseAsError{Version: h.Tag(version), Latest: h.Tag(h.Latest.Version), By: by}
+	}
+	log.Info().Msgf("The version is set to %s by %s", version, by)
+	h.releaseAs, h.releaseAsBy = version, by
+	return nil
+}
+
+// versionConfig returns the configuration of the version increment, graduate is passed explicitly so that the
+// graduation of a release pull request can be computed without changing the handler.
+func (h *Handler) versionConfig(graduate bool) conventional.VersionConfig {
+	return conventional.VersionConfig{
+		DefaultBranch:        h.ReleaseBranch,
+		BaseBranch:           h.Base,
+		PrereleaseIdentifier: h.PrereleaseIdentifier,
+		PreMajor:             h.PreMajor,
+		Graduate:             graduate,
+		ReleaseAs:            h.releaseAs,
+	}
+}
+
+// NextVersion returns the next version, it is computed by PullRequest and Release.
+func (h *Handler) NextVersion() *semver.Version {
+	return h.next
 }
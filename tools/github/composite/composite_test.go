@@ -0,0 +1,116 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	gh "github.com/google/go-github/v58/github"
+	"github.com/jakbytes/version_actions/internal/mocks"
+	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/jakbytes/version_actions/tools/semver"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+func newHandler(repositories *mocks.RepositoryService) *Handler {
+	return &Handler{
+		Client: &github.Client{
+			Ctx:          context.Background(),
+			Repositories: repositories,
+			Git:          &mocks.GitService{},
+			PullRequests: &mocks.PullRequestsService{},
+			RepositoryMetadata: github.RepositoryMetadata{
+				Owner: "owner",
+				Name:  "name",
+			},
+		},
+		Owner:                "owner",
+		Name:                 "name",
+		Head:                 "main",
+		Base:                 "main",
+		PrereleaseIdentifier: "rc",
+		ReleaseBranch:        "main",
+	}
+}
+
+func TestCheckVersionAvailable(t *testing.T) {
+	h := newHandler(&mocks.RepositoryService{})
+	require.Nil(t, h.checkVersionAvailable(semver.MustParse("1.1.0")))
+	require.Equal(t, github.VersionAlreadyExists{Version: "1.0.1"}, h.checkVersionAvailable(semver.MustParse("1.0.1")))
+	require.Equal(t, github.VersionAlreadyExists{Version: "1.0.2-rc.1"}, h.checkVersionAvailable(semver.MustParse("1.0.2-rc.1")))
+}
+
+func TestForComponent(t *testing.T) {
+	h := newHandler(&mocks.RepositoryService{})
+	component := h.ForComponent(Component{Name: "api", Path: "services/api", TagPrefix: "api/", Changelog: "services/api/CHANGELOG.md"})
+
+	require.Equal(t, "", h.Client.TagPrefix)
+	require.Equal(t, "api/", component.Client.TagPrefix)
+	require.Equal(t, "release--branch--main", h.releaseBranch("main"))
+	require.Equal(t, "release--branch--api--main", component.releaseBranch("main"))
+	require.Equal(t, "v1.2.0", h.Tag(semver.MustParse("1.2.0")))
+	require.Equal(t, "api/v1.2.0", component.Tag(semver.MustParse("1.2.0")))
+	require.Equal(t, "release.txt", h.releaseNotes())
+	require.Equal(t, "release-api.txt", component.releaseNotes())
+	require.Equal(t, "services/api/CHANGELOG.md", component.changelog().Path)
+}
+
+func TestWrapper(t *testing.T) {
+	h := newHandler(&mocks.RepositoryService{})
+	first := errors.New("first")
+	var calls int
+	h.Wrapper(func() error { calls++; return nil })
+	h.Wrapper(func() error { calls++; return first })
+	h.Wrapper(func() error { calls++; return errors.New("second") })
+	require.Equal(t, 2, calls)
+	require.Equal(t, first, h.inner)
+}
+
+func TestPullRequest_Error(t *testing.T) {
+	notFound := errors.New("not found")
+	h := newHandler(&mocks.RepositoryService{
+		GetBranchError: func(ctx context.Context, owner string, repo string, branch string, maxRedirects int) error {
+			return notFound
+		},
+	})
+	err := h.PullRequest()
+	require.ErrorIs(t, err, notFound)
+	require.ErrorContains(t, err, "failed to get branch main")
+	require.Empty(t, *h.Commits())
+	require.Nil(t, h.NextVersion())
+}
+
+func TestRelease_Error(t *testing.T) {
+	notFound := errors.New("not found")
+	h := newHandler(&mocks.RepositoryService{
+		GetBranchError: func(ctx context.Context, owner string, repo string, branch string, maxRedirects int) error {
+			return notFound
+		},
+	})
+	require.ErrorIs(t, h.Release(), notFound)
+	require.Nil(t, h.Released)
+}
+
+func TestRelease_Graduated(t *testing.T) {
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+	require.Nil(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { require.Nil(t, os.Chdir(wd)) })
+
+	h := newHandler(&mocks.RepositoryService{Tags: []*gh.RepositoryTag{
+		{Name: gh.String("v0.4.0"), Commit: &gh.Commit{SHA: gh.String("hash3-hash3")}},
+		{Name: gh.String("v0.4.1"), Commit: &gh.Commit{SHA: gh.String("hash2-hash2")}},
+	}})
+	h.PullRequests = &mocks.PullRequestsService{Closed: []*gh.PullRequest{{
+		Number:         gh.Int(3),
+		Title:          gh.String("release(main): v1.0.0"),
+		MergedAt:       &gh.Timestamp{Time: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+		MergeCommitSHA: gh.String("hash"),
+	}}}
+
+	require.Nil(t, h.Release())
+	require.Equal(t, "v1.0.0", h.Released.GetTagName())
+	require.Equal(t, semver.MustParse("1.0.0"), h.NextVersion())
+	require.False(t, h.Graduate, "the handler is not changed by the graduation of the release pull request")
+}
@@ -0,0 +1,27 @@
+package composite
+
+import "fmt"
+
+// VersionMismatchError is returned when the version proposed by a merged release pull request is not the version
+// computed from the commits, e.g. because commits were added to the base branch before the release.
+type VersionMismatchError struct {
+	Number   int
+	Proposed string
+	Computed string
+}
+
+func (e VersionMismatchError) Error() string {
+	return fmt.Errorf("release pull request #%d proposes %s but %s was computed", e.Number, e.Proposed, e.Computed).Error()
+}
+
+// ReleaseAsError is returned when the version a release is forced to, by the release-as input or a Release-As footer,
+// is not greater than the latest release version.
+type ReleaseAsError struct {
+	Version string
+	Latest  string
+	By      string
+}
+
+func (e ReleaseAsError) Error() string {
+	return fmt.Errorf("%s set by %s is not greater than the latest version %s", e.Version, e.By, e.Latest).Error()
+}
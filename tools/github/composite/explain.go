@@ -0,0 +1,155 @@
+package composite
+
+import (
+	"fmt"
+	"github.com/google/go-github/v58/github"
+	"github.com/jakbytes/version_actions/tools/changelog"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"strings"
+)
+
+// Explain computes the next version like PullRequest, without writing anything, and returns a Markdown explanation of
+// how it was derived: the base version, the range of commits, how each commit was classified, which commit requires
+// the increment and how the prerelease number was derived.
+func (h *Handler) Explain() (changelog.Markdown, error) {
+	h.DryRun = true // the head branch is read instead of creating or resetting the release branch
+	h.Plan = &Plan{}
+	h.Wrapper(h.gatherVersions)
+	h.Wrapper(h.gatherCommits)
+	h.Wrapper(h.gatherNextVersion)
+	if h.inner != nil {
+		return nil, h.inner
+	}
+	increment, trigger := h.Commits().IncrementTrigger()
+	_, steps, err := conventional.ExplainIncVersion(h.VersionInfo(), h.versionConfig(h.Graduate), increment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the next version: %w", err)
+	}
+	classified := h.classified
+
+	heading := fmt.Sprintf("## Next version `%s`", h.Tag(h.NextVersion()))
+	if h.Component != nil {
+		heading = fmt.Sprintf("## %s: next version `%s`", h.Component.Name, h.Tag(h.NextVersion()))
+	}
+	md := changelog.Markdown{heading, "", "| | |", "|---|---|"}
+	md = append(md, fmt.Sprintf("| Base version | %s |", h.describeLatest()))
+	if h.PrereleaseIdentifier != "" {
+		md = append(md, fmt.Sprintf("| Latest prerelease | %s |", h.describeLatestPrerelease()))
+	}
+	md = append(md,
+		fmt.Sprintf("| Commit range | %s |", h.describeRange()),
+		fmt.Sprintf("| Increment | %s |", describeIncrement(increment, trigger, classified)),
+	)
+	if h.releaseAs != nil {
+		md = append(md, fmt.Sprintf("| Release-As | `%s`, set by %s |", h.releaseAs, h.releaseAsBy))
+	}
+	md = append(md,
+		fmt.Sprintf("| Next version | `%s` |", h.Tag(h.NextVersion())),
+		"", "### Commits", "",
+	)
+
+	var unparsed []conventional.Classification
+	if len(classified) == 0 {
+		md = append(md, "No commits.")
+	} else {
+		md = append(md, "| Commit | Message | Classification | Bump |", "|---|---|---|---|")
+	}
+	for _, c := range classified {
+		bump := "–"
+		if c.Type != "" {
+			bump = c.Increment().String()
+		}
+		md = append(md, fmt.Sprintf("| `%s` | %s | %s | %s |", short(c.Commit.GetSHA()), escapeCell(subject(c.Commit)), classification(c), bump))
+		if c.Err != nil {
+			unparsed = append(unparsed, c)
+		}
+	}
+
+	if len(unparsed) > 0 {
+		md = append(md, "", "### Commits that failed to parse", "")
+		for _, c := range unparsed {
+			md = append(md, fmt.Sprintf("- `%s` %s: %s", short(c.Commit.GetSHA()), subject(c.Commit), c.Err))
+		}
+	}
+
+	md = append(md, "", "### Version derivation", "")
+	for i, step := range steps {
+		md = append(md, fmt.Sprintf("%d. %s", i+1, step))
+	}
+	return md, nil
+}
+
+func (h *Handler) describeLatest() string {
+	if h.Latest == nil || h.Latest.Version == nil {
+		return "none, there is no release tag in the history of `" + h.Head + "`"
+	}
+	return fmt.Sprintf("`%s` at `%s`, the release tag nearest to the head of `%s`", h.Latest.GetName(), short(h.Latest.Commit.GetSHA()), h.Head)
+}
+
+func (h *Handler) describeLatestPrerelease() string {
+	if h.LatestPrerelease == nil || h.LatestPrerelease.Version == nil {
+		return fmt.Sprintf("none, there is no `%s` prerelease tag", h.PrereleaseIdentifier)
+	}
+	return fmt.Sprintf("`%s`, the highest `%s` prerelease tag", h.LatestPrerelease.GetName(), h.PrereleaseIdentifier)
+}
+
+func (h *Handler) describeRange() (description string) {
+	switch {
+	case !h.sinceLatest():
+		description = fmt.Sprintf("the commits of `%s` that are not on the release branch `%s`", h.Head, h.ReleaseBranch)
+	case h.Latest == nil || h.Latest.Version == nil:
+		description = fmt.Sprintf("all commits of `%s`", h.Head)
+	default:
+		description = fmt.Sprintf("the commits of `%s` since `%s`", h.Head, h.Latest.GetName())
+	}
+	if h.Component != nil {
+		description += fmt.Sprintf(" changing files in `%s`", h.Component.Path)
+	}
+	return
+}
+
+func describeIncrement(increment conventional.Increment, trigger *github.RepositoryCommit, classified []conventional.Classification) string {
+	if trigger == nil {
+		return "none, no commit requires an increment"
+	}
+	for _, c := range classified {
+		if c.Commit != trigger {
+			continue
+		}
+		if c.Type == conventional.Dependencies {
+			return fmt.Sprintf("%s, required by the dependency update `%s`", increment, short(trigger.GetSHA()))
+		}
+		return fmt.Sprintf("%s, required by `%s`, the oldest commit classified as %s", increment, short(trigger.GetSHA()), c.Type)
+	}
+	return increment.String()
+}
+
+func classification(c conventional.Classification) string {
+	switch {
+	case c.Reverted != nil:
+		return fmt.Sprintf("ignored, cancels out with `%s`", short(c.Reverted.GetSHA()))
+	case c.Breaking:
+		return fmt.Sprintf("%s (`%s` with a breaking change)", conventional.Breaking, c.Parsed)
+	case c.Type != "":
+		return c.Type
+	case c.Parsed != "":
+		return fmt.Sprintf("ignored, unrecognized type `%s`", escapeCell(c.Parsed))
+	}
+	return "ignored, not a conventional commit"
+}
+
+// short returns the first 7 characters of the SHA.
+func short(sha string) string {
+	return sha[:min(7, len(sha))]
+}
+
+// subject returns the first line of the commit message.
+func subject(commit *github.RepositoryCommit) string {
+	line, _, _ := strings.Cut(commit.GetCommit().GetMessage(), "\n")
+	return line
+}
+
+// escapeCell escapes the text for a Markdown table cell.
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -0,0 +1,216 @@
+package composite
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jakbytes/version_actions/tools/conventional"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Plan describes what the release flow computed and the writes it would make. It is recorded instead of making the
+// writes when the handler runs with DryRun set.
+type Plan struct {
+	Component        string        `json:"component,omitempty"`
+	LatestVersion    string        `json:"latest_version,omitempty"`    // tag of the latest release version
+	LatestPrerelease string        `json:"latest_prerelease,omitempty"` // tag of the latest prerelease version
+	Commits          []PlanSection `json:"commits"`                     // the parsed commits per type, in changelog order
+	NextVersion      string        `json:"next_version,omitempty"`      // tag of the next version
+	Proposed         bool          `json:"proposed"`                    // whether the commits propose a new version
+	Branch           *PlanBranch   `json:"branch,omitempty"`            // the release branch that would be created or reset
+	Commit           *PlanCommit   `json:"commit,omitempty"`            // the commit of the changelog to the release branch
+	PullRequest      *PlanPR       `json:"pull_request,omitempty"`      // the release pull request that would be opened or updated
+	Tag              *PlanTag      `json:"tag,omitempty"`               // the tag that would be created by a release
+	Release          *PlanRelease  `json:"release,omitempty"`           // the release that would be published
+	Files            []PlanFile    `json:"files,omitempty"`             // local files that would be written, e.g. release.txt
+}
+
+// PlanSection is the commits of a commit type.
+type PlanSection struct {
+	Type    string   `json:"type"`
+	Commits []string `json:"commits"` // the short SHA and the first line of the message of each commit
+}
+
+// PlanBranch is a branch that would be created, or reset if it exists, to the head commit of From.
+type PlanBranch struct {
+	Name   string `json:"name"`
+	From   string `json:"from"`
+	SHA    string `json:"sha"`
+	Create bool   `json:"create"`
+}
+
+// PlanCommit is a commit of files to a branch.
+type PlanCommit struct {
+	Branch  string     `json:"branch"`
+	Message string     `json:"message"`
+	Files   []PlanFile `json:"files"`
+}
+
+// PlanFile is a file and its content.
+type PlanFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// PlanPR is a pull request that would be opened, or updated if one is open for the head and base branches.
+type PlanPR struct {
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// PlanTag is a tag that would be created on the commit.
+type PlanTag struct {
+	Name string `json:"name"`
+	SHA  string `json:"sha"`
+}
+
+// PlanRelease is a GitHub release that would be published.
+type PlanRelease struct {
+	Tag        string `json:"tag"`
+	Prerelease bool   `json:"prerelease"`
+	Notes      string `json:"notes"`
+}
+
+// recordState records the versions and commits gathered by the handler in the plan.
+func (h *Handler) recordState() {
+	p := h.Plan
+	if h.Component != nil {
+		p.Component = h.Component.Name
+	}
+	if h.Latest != nil && h.Latest.Version != nil {
+		p.LatestVersion = h.Tag(h.Latest.Version)
+	}
+	if h.LatestPrerelease != nil && h.LatestPrerelease.Version != nil {
+		p.LatestPrerelease = h.Tag(h.LatestPrerelease.Version)
+	}
+	if h.NextVersion() != nil {
+		p.NextVersion = h.Tag(h.NextVersion())
+		p.Proposed = h.Proposed()
+	}
+
+	types := slices.Clone(conventional.Types)
+	slices.SortStableFunc(types, func(a, b conventional.Type) int { return a.Order - b.Order })
+	p.Commits = []PlanSection{}
+	for _, t := range types {
+		commits := (*h.Commits())[t.Name]
+		if len(commits) == 0 {
+			continue
+		}
+		section := PlanSection{Type: t.Name}
+		for _, commit := range commits {
+			section.Commits = append(section.Commits, fmt.Sprintf("%s %s", short(commit.GetSHA()), subject(commit)))
+		}
+		p.Commits = append(p.Commits, section)
+	}
+}
+
+// WriteText writes the plan as human-readable text.
+func (p *Plan) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	if p.Component != "" {
+		_, _ = fmt.Fprintf(&sb, "Component: %s\n", p.Component)
+	}
+	_, _ = fmt.Fprintf(&sb, "Latest version: %s\n", orNone(p.LatestVersion))
+	if p.LatestPrerelease != "" {
+		_, _ = fmt.Fprintf(&sb, "Latest prerelease: %s\n", p.LatestPrerelease)
+	}
+	sb.WriteString("Commits:\n")
+	if len(p.Commits) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, section := range p.Commits {
+		_, _ = fmt.Fprintf(&sb, "  %s (%d)\n", section.Type, len(section.Commits))
+		for _, commit := range section.Commits {
+			_, _ = fmt.Fprintf(&sb, "    %s\n", commit)
+		}
+	}
+	_, _ = fmt.Fprintf(&sb, "Next version: %s\n", orNone(p.NextVersion))
+	if !p.Proposed {
+		sb.WriteString("No version increment necessary, nothing would be written\n")
+	}
+
+	if b := p.Branch; b != nil {
+		action := "Reset"
+		if b.Create {
+			action = "Create"
+		}
+		_, _ = fmt.Fprintf(&sb, "%s branch %s at %s (head of %s)\n", action, b.Name, b.SHA, b.From)
+	}
+	if c := p.Commit; c != nil {
+		_, _ = fmt.Fprintf(&sb, "Commit %q to %s:\n", c.Message, c.Branch)
+		writeFiles(&sb, c.Files)
+	}
+	if pr := p.PullRequest; pr != nil {
+		_, _ = fmt.Fprintf(&sb, "Open or update pull request %s -> %s: %s\n", pr.Head, pr.Base, pr.Title)
+		writeContent(&sb, pr.Body)
+	}
+	if t := p.Tag; t != nil {
+		_, _ = fmt.Fprintf(&sb, "Create tag %s at %s\n", t.Name, t.SHA)
+	}
+	if r := p.Release; r != nil {
+		kind := "release"
+		if r.Prerelease {
+			kind = "prerelease"
+		}
+		_, _ = fmt.Fprintf(&sb, "Publish %s %s:\n", kind, r.Tag)
+		writeContent(&sb, r.Notes)
+	}
+	if len(p.Files) > 0 {
+		sb.WriteString("Write local files:\n")
+		writeFiles(&sb, p.Files)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeFiles(sb *strings.Builder, files []PlanFile) {
+	for _, file := range files {
+		_, _ = fmt.Fprintf(sb, "  %s\n", file.Path)
+		writeContent(sb, file.Content)
+	}
+}
+
+// writeContent writes the lines of the content indented and prefixed with |, so that they stand out from the plan.
+func writeContent(sb *strings.Builder, content string) {
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		_, _ = fmt.Fprintf(sb, "    | %s\n", line)
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// PlanFormats are the formats plans are written in.
+var PlanFormats = []string{"text", "json"}
+
+// WritePlans writes the plans in the format, text or json. A single plan is written as a JSON object and the plans of
+// components as a JSON array.
+func WritePlans(w io.Writer, format string, plans ...*Plan) error {
+	if format == "json" {
+		var value any = plans
+		if len(plans) == 1 && plans[0].Component == "" {
+			value = plans[0]
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+	for i, plan := range plans {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := plan.WriteText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package composite
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWritePlans(t *testing.T) {
+	plan := &Plan{
+		LatestVersion: "v1.0.0",
+		Commits:       []PlanSection{{Type: "feat", Commits: []string{"abc1234 feat: init"}}},
+		NextVersion:   "v1.1.0",
+		Proposed:      true,
+		Branch:        &PlanBranch{Name: "release--branch--main", From: "main", SHA: "abc1234", Create: true},
+		Commit:        &PlanCommit{Branch: "release--branch--main", Message: "release(main): v1.1.0", Files: []PlanFile{{Path: "CHANGELOG.md", Content: "# Changelog\n\n- init\n"}}},
+		PullRequest:   &PlanPR{Head: "release--branch--main", Base: "main", Title: "release(main): v1.1.0", Body: "- init"},
+	}
+
+	var text bytes.Buffer
+	require.Nil(t, WritePlans(&text, "text", plan))
+	require.Equal(t, `Latest version: v1.0.0
+Commits:
+  feat (1)
+    abc1234 feat: init
+Next version: v1.1.0
+Create branch release--branch--main at abc1234 (head of main)
+Commit "release(main): v1.1.0" to release--branch--main:
+  CHANGELOG.md
+    | # Changelog
+    | 
+    | - init
+Open or update pull request release--branch--main -> main: release(main): v1.1.0
+    | - init
+`, text.String())
+
+	var components bytes.Buffer
+	require.Nil(t, WritePlans(&components, "text", &Plan{Component: "api", Commits: []PlanSection{}}, &Plan{Component: "web", Commits: []PlanSection{}}))
+	require.Equal(t, `Component: api
+Latest version: none
+Commits:
+  none
+Next version: none
+No version increment necessary, nothing would be written
+
+Component: web
+Latest version: none
+Commits:
+  none
+Next version: none
+No version increment necessary, nothing would be written
+`, components.String())
+
+	var single, multiple bytes.Buffer
+	require.Nil(t, WritePlans(&single, "json", &Plan{LatestVersion: "v1.0.0", Commits: []PlanSection{}}))
+	require.JSONEq(t, `{"latest_version": "v1.0.0", "commits": [], "proposed": false}`, single.String())
+	require.Nil(t, WritePlans(&multiple, "json", &Plan{Component: "api", Commits: []PlanSection{}}))
+	require.JSONEq(t, `[{"component": "api", "commits": [], "proposed": false}]`, multiple.String())
+}
@@ -32,10 +32,29 @@ func (e MultiplePullRequestsFoundError) Error() string {
 	return fmt.Errorf("multiple pull requests found for branch %s targeting %s", e.Head, e.Base).Error()
 }
 
-type BranchNotFound struct{
+type BranchNotFound struct {
 	Name string
 }
 
 func (e BranchNotFound) Error() string {
 	return fmt.Errorf("branch %s not found", e.Name).Error()
 }
+
+// FileNotFound is returned when a file does not exist on a branch.
+type FileNotFound struct {
+	Path   string
+	Branch string
+}
+
+func (e FileNotFound) Error() string {
+	return fmt.Sprintf("file %s not found on branch %s", e.Path, e.Branch)
+}
+
+type VersionAlreadyExists struct {
+	Version   string
+	TagPrefix string
+}
+
+func (e VersionAlreadyExists) Error() string {
+	return fmt.Errorf("version %sv%s already exists as a tag", e.TagPrefix, e.Version).Error()
+}
@@ -38,3 +38,8 @@ func TestNoPrereleaseVersionFound_Error(t *testing.T) {
 	err := NoPrereleaseVersionFound{}
 	require.Equal(t, "no commits found with a prerelease tag", err.Error())
 }
+
+func TestVersionAlreadyExists_Error(t *testing.T) {
+	err := VersionAlreadyExists{Version: "1.0.0"}
+	require.Equal(t, "version v1.0.0 already exists as a tag", err.Error())
+}
@@ -17,6 +17,7 @@ type CommitsComparison = github.CommitsComparison
 type CommitAuthor = github.CommitAuthor
 type Timestamp = github.Timestamp
 type Tree = github.Tree
+type Reference = github.Reference
 
 // Client is a struct that contains the go-github client and the repository metadata to interact with the GitHub API.
 type Client struct {
@@ -25,6 +26,9 @@ type Client struct {
 	Repositories RepositoriesService
 	Git          GitService
 	RepositoryMetadata
+	TagPrefix string // prefix of the version tags read and created through the client, empty for the repository itself
+
+	repository *Repository // see Repository
 }
 
 type RepositoryMetadata struct {
@@ -51,14 +55,24 @@ func NewClient(ctx context.Context, token, owner, name string) *Client {
 	}
 }
 
+// Repository returns the repository of the client. The repository, with the tags, versions and branches it has read, is
+// cached on the client as long as the services, the repository metadata and the tag prefix it was created with are
+// unchanged, so a copy of the client for a component, see TagPrefix, gets a repository of its own.
 func (c *Client) Repository() *Repository {
-	return &Repository{
-		GitService:          c.Git,
-		RepositoriesService: c.Repositories,
-		RepositoryMetadata:  c.RepositoryMetadata,
-		Ctx:                 c.Ctx,
-		branches:            make(map[string]*Branch),
+	r := c.repository
+	if r == nil || r.GitService != c.Git || r.RepositoriesService != c.Repositories || r.RepositoryMetadata != c.RepositoryMetadata ||
+		r.Ctx != c.Ctx || r.TagPrefix != c.TagPrefix {
+		r = &Repository{
+			GitService:          c.Git,
+			RepositoriesService: c.Repositories,
+			RepositoryMetadata:  c.RepositoryMetadata,
+			Ctx:                 c.Ctx,
+			branches:            make(map[string]*Branch),
+			TagPrefix:           c.TagPrefix,
+		}
+		c.repository = r
 	}
+	return r
 }
 
 func String(s string) *string {
@@ -1,11 +1,33 @@
 package github
 
 import (
+	"context"
 	"testing"
 
+	"github.com/jakbytes/version_actions/internal/mocks"
 	"github.com/stretchr/testify/require"
 )
 
+func TestClient_Repository(t *testing.T) {
+	client := &Client{Ctx: context.Background(), Repositories: &mocks.RepositoryService{}, Git: &mocks.GitService{}}
+	repository := client.Repository()
+	_, err := repository.Tags()
+	require.Nil(t, err)
+	require.Same(t, repository, client.Repository())
+	require.NotNil(t, client.Repository().tags, "the tags are cached with the repository")
+
+	// a copy of the client for a component gets a repository with the tag prefix of the component
+	component := *client
+	component.TagPrefix = "api/"
+	require.NotSame(t, repository, component.Repository())
+	require.Equal(t, "api/", component.Repository().TagPrefix)
+	require.Same(t, component.Repository(), component.Repository())
+	require.Same(t, repository, client.Repository())
+
+	client.Repositories = &mocks.RepositoryService{}
+	require.NotSame(t, repository, client.Repository())
+}
+
 func TestString(t *testing.T) {
 	expected := "test"
 	require.Equal(t, &expected, String("test"))
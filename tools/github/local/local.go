@@ -0,0 +1,437 @@
+// Package local implements the read operations of the github.RepositoriesService on top of a local git checkout, so
+// that commits, tags and ancestry are read from the .git directory instead of the GitHub API.
+package local
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/go-github/v58/github"
+	internal "github.com/jakbytes/version_actions/tools/github"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// API is the backend reading the repository through the GitHub API.
+	API = "api"
+	// Local is the backend reading the repository from the local git checkout.
+	Local = "local"
+)
+
+// Backends are the supported backends.
+var Backends = []string{API, Local}
+
+// DefaultPerPage is the number of items returned per page when no page size is requested, as in the GitHub API.
+const DefaultPerPage = 30
+
+// NoRemoteError is returned for operations the local checkout cannot answer when there is no remote to fall back to.
+type NoRemoteError struct {
+	Operation string
+}
+
+func (e NoRemoteError) Error() string {
+	return fmt.Sprintf("%s is not supported by the local git backend without a remote", e.Operation)
+}
+
+// Repositories is a github.RepositoriesService reading from the git checkout in Dir. Writes, and reads of refs that do
+// not exist in the checkout or that were written through the API during the run (e.g. the release branch), are
+// delegated to Remote.
+type Repositories struct {
+	Dir    string                       // directory of the checkout, the working directory if empty
+	Origin string                       // name of the git remote whose remote-tracking branches are used, origin if empty
+	Remote internal.RepositoriesService // may be nil to work offline
+
+	written map[string]bool // branches and tags written during the run, whose checkout refs are stale
+}
+
+// Git is an internal.GitService recording the branches and tags it creates or updates in Repositories, so that they are
+// read from the remote rather than from the stale refs of the checkout.
+type Git struct {
+	internal.GitService
+	Repositories *Repositories
+}
+
+// CreateRef creates the ref and records it as written.
+func (g *Git) CreateRef(ctx context.Context, owner string, repo string, ref *github.Reference) (*github.Reference, *github.Response, error) {
+	g.Repositories.write(ref.GetRef())
+	return g.GitService.CreateRef(ctx, owner, repo, ref)
+}
+
+// UpdateRef updates the ref and records it as written.
+func (g *Git) UpdateRef(ctx context.Context, owner string, repo string, ref *github.Reference, force bool) (*github.Reference, *github.Response, error) {
+	g.Repositories.write(ref.GetRef())
+	return g.GitService.UpdateRef(ctx, owner, repo, ref, force)
+}
+
+// write records the ref, e.g. refs/heads/main or refs/tags/v1.0.0, as written through the API. It is recorded before
+// the write is attempted, since a failed write may still have changed the ref.
+func (r *Repositories) write(ref string) {
+	if r.written == nil {
+		r.written = make(map[string]bool)
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/")
+	r.written[name] = true
+}
+
+// Select returns the client using the given backend for reading the repository. The local backend reads from the
+// checkout in the working directory.
+func Select(client *internal.Client, backend string) (*internal.Client, error) {
+	switch backend {
+	case "", API:
+		return client, nil
+	case Local:
+		log.Info().Msg("Reading the repository from the local git checkout")
+		local := *client
+		repositories := &Repositories{Remote: client.Repositories}
+		local.Repositories = repositories
+		local.Git = &Git{GitService: client.Git, Repositories: repositories}
+		return &local, nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q, expected one of %s", backend, strings.Join(Backends, ", "))
+	}
+}
+
+func (r *Repositories) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+func (r *Repositories) origin() string {
+	if r.Origin == "" {
+		return "origin"
+	}
+	return r.Origin
+}
+
+// resolve returns the commit SHA of a branch, tag or commit. Branches that only exist as remote-tracking branches, as
+// in a fresh checkout, are resolved as well. ok is false if the ref does not exist in the checkout or was written
+// through the API during the run, since the checkout does not see the write.
+func (r *Repositories) resolve(ref string) (sha string, ok bool) {
+	if r.written[ref] {
+		return "", false
+	}
+	for _, candidate := range []string{ref, "refs/remotes/" + r.origin() + "/" + ref, "refs/tags/" + ref} {
+		out, err := r.git("rev-parse", "--verify", "--quiet", candidate+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(out), true
+		}
+	}
+	return "", false
+}
+
+// fields of a commit in the log output, separated by unit separators and terminated by a record separator
+const format = "%H%x1f%T%x1f%P%x1f%an%x1f%ae%x1f%aI%x1f%cn%x1f%ce%x1f%cI%x1f%B%x1e"
+
+func (r *Repositories) log(args ...string) ([]*github.RepositoryCommit, error) {
+	out, err := r.git(append([]string{"log", "--format=" + format}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*github.RepositoryCommit
+	for _, record := range strings.Split(out, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != 10 {
+			return nil, fmt.Errorf("unexpected git log output %q", record)
+		}
+
+		var parents []*github.Commit
+		for _, parent := range strings.Fields(fields[2]) {
+			parents = append(parents, &github.Commit{SHA: github.String(parent)})
+		}
+		commits = append(commits, &github.RepositoryCommit{
+			SHA: github.String(fields[0]),
+			Commit: &github.Commit{
+				SHA:       github.String(fields[0]),
+				Tree:      &github.Tree{SHA: github.String(fields[1])},
+				Author:    author(fields[3], fields[4], fields[5]),
+				Committer: author(fields[6], fields[7], fields[8]),
+				Message:   github.String(strings.TrimRight(fields[9], "\n")),
+			},
+			Parents: parents,
+		})
+	}
+	return commits, nil
+}
+
+func author(name, email, date string) *github.CommitAuthor {
+	a := &github.CommitAuthor{Name: github.String(name), Email: github.String(email)}
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		a.Date = &github.Timestamp{Time: t}
+	}
+	return a
+}
+
+// page returns the number of items to skip and the page size for the list options, pages start at 1.
+func page(opts github.ListOptions) (skip int, perPage int) {
+	perPage = opts.PerPage
+	if perPage <= 0 {
+		perPage = DefaultPerPage
+	}
+	if opts.Page > 1 {
+		skip = (opts.Page - 1) * perPage
+	}
+	return
+}
+
+// response returns the response for a page, the next page is set if there are more items than returned.
+func response(opts github.ListOptions, more bool) *github.Response {
+	r := &github.Response{}
+	if more {
+		r.NextPage = max(opts.Page, 1) + 1
+	}
+	return r
+}
+
+// ListCommits lists the commits reachable from opts.SHA, or HEAD, newest first.
+func (r *Repositories) ListCommits(ctx context.Context, owner string, repo string, opts *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	if opts == nil {
+		opts = &github.CommitsListOptions{}
+	}
+	ref := "HEAD"
+	if opts.SHA != "" {
+		sha, ok := r.resolve(opts.SHA)
+		if !ok {
+			if r.Remote != nil {
+				return r.Remote.ListCommits(ctx, owner, repo, opts)
+			}
+			return nil, nil, fmt.Errorf("404 %s not found in the local repository", opts.SHA)
+		}
+		ref = sha
+	}
+
+	skip, perPage := page(opts.ListOptions)
+	args := []string{"--skip=" + strconv.Itoa(skip), "--max-count=" + strconv.Itoa(perPage+1), ref}
+	if opts.Path != "" {
+		args = append(args, "--", opts.Path)
+	}
+	commits, err := r.log(args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	more := len(commits) > perPage
+	if more {
+		commits = commits[:perPage]
+	}
+	return commits, response(opts.ListOptions, more), nil
+}
+
+// CompareCommits returns the commits reachable from head but not from base, oldest first as in the GitHub API.
+func (r *Repositories) CompareCommits(ctx context.Context, owner string, repo string, base string, head string, opts *github.ListOptions) (*github.CommitsComparison, *github.Response, error) {
+	baseSHA, baseOk := r.resolve(base)
+	headSHA, headOk := r.resolve(head)
+	if !baseOk || !headOk {
+		if r.Remote != nil {
+			return r.Remote.CompareCommits(ctx, owner, repo, base, head, opts)
+		}
+		return nil, nil, fmt.Errorf("404 %s...%s not found in the local repository", base, head)
+	}
+
+	commits, err := r.log("--reverse", baseSHA+".."+headSHA)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts, err := r.git("rev-list", "--left-right", "--count", baseSHA+"..."+headSHA)
+	if err != nil {
+		return nil, nil, err
+	}
+	var behind, ahead int
+	if _, err = fmt.Sscanf(counts, "%d\t%d", &behind, &ahead); err != nil {
+		return nil, nil, fmt.Errorf("unexpected git rev-list output %q: %w", counts, err)
+	}
+
+	status := "diverged"
+	switch {
+	case ahead == 0 && behind == 0:
+		status = "identical"
+	case behind == 0:
+		status = "ahead"
+	case ahead == 0:
+		status = "behind"
+	}
+	comparison := &github.CommitsComparison{
+		Status:       github.String(status),
+		AheadBy:      github.Int(ahead),
+		BehindBy:     github.Int(behind),
+		TotalCommits: github.Int(len(commits)),
+		Commits:      commits,
+	}
+	if mergeBase, err := r.git("merge-base", baseSHA, headSHA); err == nil {
+		if commit, err := r.log("--max-count=1", strings.TrimSpace(mergeBase)); err == nil && len(commit) == 1 {
+			comparison.MergeBaseCommit = commit[0]
+		}
+	}
+	return comparison, &github.Response{}, nil
+}
+
+// GetCommit returns the commit with the files it changes compared to its first parent. Unlike the GitHub API the files
+// are not paginated, every file is returned on the first page.
+func (r *Repositories) GetCommit(ctx context.Context, owner string, repo string, sha string, opts *github.ListOptions) (*github.RepositoryCommit, *github.Response, error) {
+	resolved, ok := r.resolve(sha)
+	if !ok {
+		if r.Remote != nil {
+			return r.Remote.GetCommit(ctx, owner, repo, sha, opts)
+		}
+		return nil, nil, fmt.Errorf("404 %s not found in the local repository", sha)
+	}
+	commits, err := r.log("--max-count=1", resolved)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(commits) == 0 {
+		return nil, nil, fmt.Errorf("404 %s not found in the local repository", sha)
+	}
+	commit := commits[0]
+
+	var out string
+	if len(commit.Parents) > 0 {
+		out, err = r.git("diff", "--name-only", "--no-renames", commit.Parents[0].GetSHA(), resolved)
+	} else {
+		out, err = r.git("diff-tree", "--no-commit-id", "--name-only", "--no-renames", "-r", "--root", resolved)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, file := range strings.Split(strings.TrimSpace(out), "\n") {
+		if file != "" {
+			commit.Files = append(commit.Files, &github.CommitFile{Filename: github.String(file)})
+		}
+	}
+	return commit, &github.Response{}, nil
+}
+
+// ListTags lists the tags of the checkout with the commit they point at, annotated tags are peeled to their commit.
+func (r *Repositories) ListTags(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
+	if opts == nil {
+		opts = &github.ListOptions{}
+	}
+	out, err := r.git("for-each-ref", "--sort=-creatordate", "--format=%(refname:strip=2)%09%(objectname)%09%(*objectname)", "refs/tags")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tags []*github.RepositoryTag
+	// the lines are not trimmed, the last field is empty for lightweight tags
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		sha := fields[1]
+		if fields[2] != "" { // annotated tag
+			sha = fields[2]
+		}
+		tags = append(tags, &github.RepositoryTag{Name: github.String(fields[0]), Commit: &github.Commit{SHA: github.String(sha)}})
+	}
+
+	skip, perPage := page(*opts)
+	if skip > len(tags) {
+		skip = len(tags)
+	}
+	end := min(skip+perPage, len(tags))
+	return tags[skip:end], response(*opts, end < len(tags)), nil
+}
+
+// GetBranch returns the branch with its head commit. Branches that do not exist in the checkout are read from the
+// remote, or reported as internal.BranchNotFound when there is none.
+func (r *Repositories) GetBranch(ctx context.Context, owner string, repo string, branch string, maxRedirects int) (*github.Branch, *github.Response, error) {
+	sha, ok := r.resolve(branch)
+	if !ok {
+		if r.Remote != nil {
+			return r.Remote.GetBranch(ctx, owner, repo, branch, maxRedirects)
+		}
+		return nil, nil, internal.BranchNotFound{Name: branch}
+	}
+	commits, err := r.log("--max-count=1", sha)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(commits) == 0 {
+		return nil, nil, internal.BranchNotFound{Name: branch}
+	}
+	return &github.Branch{Name: github.String(branch), Commit: commits[0]}, &github.Response{}, nil
+}
+
+// Get returns the repository with the default branch of the remote, as recorded by git clone and actions/checkout,
+// falling back to the remote and then to the checked out branch.
+func (r *Repositories) Get(ctx context.Context, owner string, repo string) (*github.Repository, *github.Response, error) {
+	out, err := r.git("symbolic-ref", "--short", "refs/remotes/"+r.origin()+"/HEAD")
+	if err != nil && r.Remote != nil {
+		return r.Remote.Get(ctx, owner, repo)
+	}
+	if err != nil {
+		out, err = r.git("symbolic-ref", "--short", "HEAD")
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	branch := strings.TrimPrefix(strings.TrimSpace(out), r.origin()+"/")
+	return &github.Repository{
+		Name:          github.String(repo),
+		Owner:         &github.User{Login: github.String(owner)},
+		DefaultBranch: github.String(branch),
+	}, &github.Response{}, nil
+}
+
+// CreateRelease creates the release through the remote.
+func (r *Repositories) CreateRelease(ctx context.Context, owner string, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	if r.Remote == nil {
+		return nil, nil, NoRemoteError{Operation: "creating a release"}
+	}
+	return r.Remote.CreateRelease(ctx, owner, repo, release)
+}
+
+// GetContents returns the content of the file at path on opts.Ref, or HEAD, from the checkout. Files that do not exist
+// are reported with a 404 github.ErrorResponse as in the GitHub API, directories are not supported.
+func (r *Repositories) GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+	ref := "HEAD"
+	if opts != nil && opts.Ref != "" {
+		ref = opts.Ref
+	}
+	sha, ok := r.resolve(ref)
+	if !ok {
+		if r.Remote != nil {
+			return r.Remote.GetContents(ctx, owner, repo, path, opts)
+		}
+		return nil, nil, nil, fmt.Errorf("404 %s not found in the local repository", ref)
+	}
+
+	object := sha + ":" + strings.TrimPrefix(path, "/")
+	if kind, err := r.git("cat-file", "-t", object); err != nil && missing(err) {
+		response := &http.Response{StatusCode: http.StatusNotFound}
+		return nil, nil, &github.Response{Response: response}, &github.ErrorResponse{Response: response, Message: fmt.Sprintf("%s not found at %s", path, ref)}
+	} else if err != nil {
+		return nil, nil, nil, err
+	} else if strings.TrimSpace(kind) != "blob" {
+		return nil, nil, nil, fmt.Errorf("%s is not a file at %s", path, ref)
+	}
+	content, err := r.git("cat-file", "blob", object)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &github.RepositoryContent{Type: github.String("file"), Path: github.String(path), Content: github.String(content)}, nil, &github.Response{}, nil
+}
+
+// missing reports whether git failed because the path does not exist in the tree, other failures such as a corrupt
+// object are not reported as a missing file.
+func missing(err error) bool {
+	return strings.Contains(err.Error(), "does not exist in") || strings.Contains(err.Error(), "exists on disk, but not in")
+}
+
+var _ internal.RepositoriesService = (*Repositories)(nil)
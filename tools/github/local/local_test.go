@@ -0,0 +1,291 @@
+package local
+
+import (
+	"context"
+	"github.com/google/go-github/v58/github"
+	"github.com/jakbytes/version_actions/internal/mocks"
+	internal "github.com/jakbytes/version_actions/tools/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRepository creates a git repository with the history
+//
+//	main:    init (v1.0.0) - fix: bug (v1.0.1, annotated)
+//	feature:                 \ feat: new feature - feat!: breaking
+func newRepository(t *testing.T) (*Repositories, map[string]string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	r := &Repositories{Dir: t.TempDir()}
+	run := func(args ...string) string {
+		out, err := r.git(args...)
+		require.Nil(t, err)
+		return strings.TrimSpace(out)
+	}
+	commit := func(message string) string {
+		run("commit", "--allow-empty", "-m", message)
+		return run("rev-parse", "HEAD")
+	}
+
+	run("init", "--initial-branch=main")
+	run("config", "user.name", "Test")
+	run("config", "user.email", "test@example.com")
+	run("config", "commit.gpgsign", "false")
+	run("config", "tag.gpgsign", "false")
+
+	shas := map[string]string{}
+	shas["init"] = commit("chore: init")
+	run("tag", "v1.0.0")
+	shas["fix"] = commit("fix: bug\n\nThe bug is fixed.")
+	run("tag", "-a", "v1.0.1", "-m", "v1.0.1")
+	run("checkout", "-b", "feature")
+	shas["feat"] = commit("feat: new feature")
+	shas["breaking"] = commit("feat!: breaking")
+	run("checkout", "main")
+	return r, shas
+}
+
+func TestListCommits(t *testing.T) {
+	r, shas := newRepository(t)
+
+	commits, response, err := r.ListCommits(context.Background(), "owner", "name", &github.CommitsListOptions{SHA: "feature", ListOptions: github.ListOptions{PerPage: 3}})
+	require.Nil(t, err)
+	require.Len(t, commits, 3)
+	assert.Equal(t, shas["breaking"], commits[0].GetSHA())
+	assert.Equal(t, "feat!: breaking", commits[0].GetCommit().GetMessage())
+	assert.Equal(t, shas["feat"], commits[0].Parents[0].GetSHA())
+	assert.Equal(t, "Test", commits[0].GetCommit().GetAuthor().GetName())
+	assert.False(t, commits[0].GetCommit().GetAuthor().GetDate().IsZero())
+	assert.Equal(t, "fix: bug\n\nThe bug is fixed.", commits[2].GetCommit().GetMessage())
+	require.Equal(t, 2, response.NextPage)
+
+	commits, response, err = r.ListCommits(context.Background(), "owner", "name", &github.CommitsListOptions{SHA: "feature", ListOptions: github.ListOptions{Page: 2, PerPage: 3}})
+	require.Nil(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, shas["init"], commits[0].GetSHA())
+	require.Equal(t, 0, response.NextPage)
+}
+
+func TestListCommits_NotFound(t *testing.T) {
+	r, _ := newRepository(t)
+	_, _, err := r.ListCommits(context.Background(), "owner", "name", &github.CommitsListOptions{SHA: "missing"})
+	require.ErrorContains(t, err, "404")
+
+	r.Remote = &mocks.RepositoryService{}
+	commits, _, err := r.ListCommits(context.Background(), "owner", "name", &github.CommitsListOptions{SHA: "missing"})
+	require.Nil(t, err)
+	require.Equal(t, "hash1-hash1", commits[0].GetSHA())
+}
+
+func TestCompareCommits(t *testing.T) {
+	r, shas := newRepository(t)
+
+	comparison, _, err := r.CompareCommits(context.Background(), "owner", "name", "main", "feature", nil)
+	require.Nil(t, err)
+	require.Len(t, comparison.Commits, 2)
+	assert.Equal(t, shas["feat"], comparison.Commits[0].GetSHA())
+	assert.Equal(t, shas["breaking"], comparison.Commits[1].GetSHA())
+	assert.Equal(t, "ahead", comparison.GetStatus())
+	assert.Equal(t, 2, comparison.GetAheadBy())
+	assert.Equal(t, 0, comparison.GetBehindBy())
+	assert.Equal(t, shas["fix"], comparison.GetMergeBaseCommit().GetSHA())
+
+	comparison, _, err = r.CompareCommits(context.Background(), "owner", "name", "feature", "v1.0.0", nil)
+	require.Nil(t, err)
+	assert.Empty(t, comparison.Commits)
+	assert.Equal(t, "behind", comparison.GetStatus())
+}
+
+func TestListTags(t *testing.T) {
+	r, shas := newRepository(t)
+
+	tags, _, err := r.ListTags(context.Background(), "owner", "name", &github.ListOptions{PerPage: 100})
+	require.Nil(t, err)
+	found := map[string]string{}
+	for _, tag := range tags {
+		found[tag.GetName()] = tag.GetCommit().GetSHA()
+	}
+	assert.Equal(t, map[string]string{"v1.0.0": shas["init"], "v1.0.1": shas["fix"]}, found)
+
+	tags, response, err := r.ListTags(context.Background(), "owner", "name", &github.ListOptions{PerPage: 1})
+	require.Nil(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, 2, response.NextPage)
+}
+
+func TestGetBranch(t *testing.T) {
+	r, shas := newRepository(t)
+
+	branch, _, err := r.GetBranch(context.Background(), "owner", "name", "feature", 2)
+	require.Nil(t, err)
+	assert.Equal(t, "feature", branch.GetName())
+	assert.Equal(t, shas["breaking"], branch.GetCommit().GetSHA())
+
+	_, _, err = r.GetBranch(context.Background(), "owner", "name", "missing", 2)
+	require.Equal(t, internal.BranchNotFound{Name: "missing"}, err)
+}
+
+func TestGetBranch_RemoteTracking(t *testing.T) {
+	r, shas := newRepository(t)
+	_, err := r.git("update-ref", "refs/remotes/origin/release", shas["fix"])
+	require.Nil(t, err)
+	_, err = r.git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/release")
+	require.Nil(t, err)
+
+	branch, _, err := r.GetBranch(context.Background(), "owner", "name", "release", 2)
+	require.Nil(t, err)
+	assert.Equal(t, shas["fix"], branch.GetCommit().GetSHA())
+
+	repository, _, err := r.Get(context.Background(), "owner", "name")
+	require.Nil(t, err)
+	assert.Equal(t, "release", repository.GetDefaultBranch())
+}
+
+func TestGetBranch_Written(t *testing.T) {
+	r, shas := newRepository(t)
+	_, err := r.git("update-ref", "refs/remotes/origin/release--branch--main", shas["init"])
+	require.Nil(t, err)
+	r.Remote = &mocks.RepositoryService{}
+	client := &internal.Client{Ctx: context.Background(), Repositories: r, Git: &mocks.GitService{}}
+	selected := *client
+	selected.Git = &Git{GitService: client.Git, Repositories: r}
+
+	// the stale remote-tracking branch is read until the run resets the branch
+	branch, _, err := r.GetBranch(context.Background(), "owner", "name", "release--branch--main", 2)
+	require.Nil(t, err)
+	assert.Equal(t, shas["init"], branch.GetCommit().GetSHA())
+
+	b, err := selected.Repository().Branch("release--branch--main")
+	require.Nil(t, err)
+	require.Nil(t, b.Reset(github.String(shas["fix"])))
+	branch, _, err = r.GetBranch(context.Background(), "owner", "name", "release--branch--main", 2)
+	require.Nil(t, err)
+	assert.Equal(t, "hash", branch.GetCommit().GetSHA(), "the branch is read from the remote")
+
+	_, _, err = r.ListCommits(context.Background(), "owner", "name", &github.CommitsListOptions{SHA: "release--branch--main"})
+	require.Nil(t, err)
+	_, _, err = r.CompareCommits(context.Background(), "owner", "name", "main", "release--branch--main", nil)
+	require.Nil(t, err)
+
+	// branches not written during the run are still read from the checkout
+	branch, _, err = r.GetBranch(context.Background(), "owner", "name", "feature", 2)
+	require.Nil(t, err)
+	assert.Equal(t, shas["breaking"], branch.GetCommit().GetSHA())
+}
+
+func TestGet_CurrentBranch(t *testing.T) {
+	r, _ := newRepository(t)
+	repository, _, err := r.Get(context.Background(), "owner", "name")
+	require.Nil(t, err)
+	assert.Equal(t, "main", repository.GetDefaultBranch())
+}
+
+func TestGetCommit(t *testing.T) {
+	r, shas := newRepository(t)
+	require.Nil(t, os.MkdirAll(filepath.Join(r.Dir, "services", "api"), 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(r.Dir, "services", "api", "main.go"), []byte("package main\n"), 0644))
+	require.Nil(t, os.WriteFile(filepath.Join(r.Dir, "README.md"), []byte("# readme\n"), 0644))
+	_, err := r.git("add", "-A")
+	require.Nil(t, err)
+	_, err = r.git("commit", "-m", "feat(api): add service")
+	require.Nil(t, err)
+
+	commit, _, err := r.GetCommit(context.Background(), "owner", "name", "main", nil)
+	require.Nil(t, err)
+	require.Len(t, commit.Files, 2)
+	assert.Equal(t, "README.md", commit.Files[0].GetFilename())
+	assert.Equal(t, "services/api/main.go", commit.Files[1].GetFilename())
+
+	commit, _, err = r.GetCommit(context.Background(), "owner", "name", shas["init"], nil)
+	require.Nil(t, err)
+	assert.Equal(t, shas["init"], commit.GetSHA())
+	assert.Empty(t, commit.Files)
+}
+
+func TestGetContents(t *testing.T) {
+	r, _ := newRepository(t)
+	require.Nil(t, os.WriteFile(filepath.Join(r.Dir, "CHANGELOG.md"), []byte("# Changelog\n"), 0644))
+	_, err := r.git("add", "-A")
+	require.Nil(t, err)
+	_, err = r.git("commit", "-m", "docs: add changelog")
+	require.Nil(t, err)
+
+	file, _, _, err := r.GetContents(context.Background(), "owner", "name", "CHANGELOG.md", &github.RepositoryContentGetOptions{Ref: "main"})
+	require.Nil(t, err)
+	content, err := file.GetContent()
+	require.Nil(t, err)
+	assert.Equal(t, "# Changelog\n", content)
+
+	_, err = (&internal.Branch{RepositoriesService: r, Ctx: context.Background(), Name: "feature"}).GetFile("CHANGELOG.md")
+	require.Equal(t, internal.FileNotFound{Path: "CHANGELOG.md", Branch: "feature"}, err)
+}
+
+func TestGetContents_Corrupt(t *testing.T) {
+	r, _ := newRepository(t)
+	require.Nil(t, os.WriteFile(filepath.Join(r.Dir, "CHANGELOG.md"), []byte("# Changelog\n"), 0644))
+	_, err := r.git("add", "-A")
+	require.Nil(t, err)
+	_, err = r.git("commit", "-m", "docs: add changelog")
+	require.Nil(t, err)
+	blob, err := r.git("rev-parse", "HEAD:CHANGELOG.md")
+	require.Nil(t, err)
+	blob = strings.TrimSpace(blob)
+	require.Nil(t, os.Remove(filepath.Join(r.Dir, ".git", "objects", blob[:2], blob[2:])))
+
+	// a file that cannot be read is an error, not a missing file
+	_, _, _, err = r.GetContents(context.Background(), "owner", "name", "CHANGELOG.md", nil)
+	require.NotNil(t, err)
+	_, ok := err.(*github.ErrorResponse)
+	assert.False(t, ok)
+}
+
+func TestCreateRelease(t *testing.T) {
+	r := &Repositories{}
+	_, _, err := r.CreateRelease(context.Background(), "owner", "name", &github.RepositoryRelease{})
+	require.Equal(t, NoRemoteError{Operation: "creating a release"}, err)
+
+	remote := &mocks.RepositoryService{}
+	r.Remote = remote
+	_, _, err = r.CreateRelease(context.Background(), "owner", "name", &github.RepositoryRelease{TagName: github.String("v1.0.0")})
+	require.Nil(t, err)
+	require.Len(t, remote.Releases, 1)
+}
+
+func TestSelect(t *testing.T) {
+	client := &internal.Client{Repositories: &mocks.RepositoryService{}}
+
+	selected, err := Select(client, "")
+	require.Nil(t, err)
+	require.Same(t, client, selected)
+
+	selected, err = Select(client, Local)
+	require.Nil(t, err)
+	require.Equal(t, &Repositories{Remote: client.Repositories}, selected.Repositories)
+	require.Equal(t, &Git{Repositories: selected.Repositories.(*Repositories)}, selected.Git)
+
+	_, err = Select(client, "svn")
+	require.EqualError(t, err, `unknown backend "svn", expected one of api, local`)
+}
+
+func TestRepository(t *testing.T) {
+	r, shas := newRepository(t)
+	client := &internal.Client{Ctx: context.Background(), Repositories: r, RepositoryMetadata: internal.RepositoryMetadata{Owner: "owner", Name: "name"}}
+
+	version, err := client.Repository().NearestVersion("feature")
+	require.Nil(t, err)
+	require.Equal(t, "1.0.1", version.String())
+	require.Equal(t, shas["fix"], version.Commit.GetSHA())
+
+	branch, err := client.Repository().Branch("feature")
+	require.Nil(t, err)
+	commits, err := branch.GetDistinctCommits("main")
+	require.Nil(t, err)
+	require.Len(t, commits, 2)
+	require.Contains(t, commits, shas["breaking"])
+}
@@ -50,6 +50,29 @@ func (c *Client) GetPullRequest(head string, base string) (*github.PullRequest,
 	return prs[0], nil
 }
 
+// GetMergedPullRequest returns the pull request from head into base that was merged as the commit sha. If no such pull
+// request exists, a NoPullRequestFoundError is returned.
+func (c *Client) GetMergedPullRequest(head, base, sha string) (*github.PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State:       "closed",
+		Head:        c.Owner + ":" + head,
+		Base:        base,
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	prs, _, err := c.PullRequests.List(c.Ctx, c.Owner, c.Name, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range prs {
+		if pr.MergedAt != nil && pr.GetMergeCommitSHA() == sha {
+			return pr, nil
+		}
+	}
+	return nil, NoPullRequestFoundError{Head: head, Base: base}
+}
+
 func (c *Client) EditPullRequest(head, base, title string, body changelog.Markdown) (*github.PullRequest, error) {
 	pr, err := c.GetPullRequest(head, base)
 	if err != nil {
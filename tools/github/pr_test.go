@@ -244,3 +244,29 @@ func TestPullRequestBody_String(t *testing.T) {
 
 	require.Equal(t, "line1\nline2", body.String())
 }
+
+func TestGetMergedPullRequest(t *testing.T) {
+	client := NewClient(context.Background(), "token", "owner", "name")
+	client.PullRequests = &mocks.PullRequestsService{
+		Closed: []*github.PullRequest{
+			{Number: github.Int(1), MergeCommitSHA: github.String("sha1")}, // closed without merging
+			{Number: github.Int(2), MergeCommitSHA: github.String("sha2"), MergedAt: &github.Timestamp{}},
+		},
+	}
+
+	pr, err := client.GetMergedPullRequest("head", "base", "sha2")
+	require.Nil(t, err)
+	require.Equal(t, 2, pr.GetNumber())
+
+	_, err = client.GetMergedPullRequest("head", "base", "sha1")
+	require.Equal(t, NoPullRequestFoundError{Head: "head", Base: "base"}, err)
+}
+
+func TestGetMergedPullRequest_Error(t *testing.T) {
+	client := NewClient(context.Background(), "token", "owner", "name")
+	client.PullRequests = &mocks.PullRequestsService{Inner: &github.ErrorResponse{}}
+
+	pr, err := client.GetMergedPullRequest("head", "base", "sha")
+	require.NotNil(t, err)
+	require.Nil(t, pr)
+}
@@ -0,0 +1,32 @@
+package github
+
+import (
+	"github.com/google/go-github/v58/github"
+	"github.com/jakbytes/version_actions/tools/changelog"
+)
+
+type RepositoryRelease = github.RepositoryRelease
+
+// CreateTag creates a lightweight tag with the given name pointing at the commit SHA. The cached tags and versions are
+// dropped, so that they are read again with the tag.
+func (r *Repository) CreateTag(name string, sha *string) error {
+	tag := &github.Reference{Ref: github.String("refs/tags/" + name), Object: &github.GitObject{SHA: sha}}
+	_, _, err := r.CreateRef(r.Ctx, r.RepositoryMetadata.Owner, r.RepositoryMetadata.Name, tag)
+	r.tags, r.versions = nil, nil
+	return err
+}
+
+// PublishRelease publishes a GitHub release for an existing tag, using the Markdown as the release notes. Prereleases are
+// marked as such so that they are not shown as the latest release of the repository.
+func (r *Repository) PublishRelease(tag string, notes changelog.Markdown, prerelease bool) (*github.RepositoryRelease, error) {
+	release, _, err := r.CreateRelease(r.Ctx, r.RepositoryMetadata.Owner, r.RepositoryMetadata.Name, &github.RepositoryRelease{
+		TagName:    github.String(tag),
+		Name:       github.String(tag),
+		Body:       github.String(notes.String()),
+		Prerelease: github.Bool(prerelease),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return release, nil
+}
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"context"
+	"github.com/jakbytes/version_actions/internal/mocks"
+	"github.com/jakbytes/version_actions/tools/changelog"
+	"testing"
+
+	"github.com/google/go-github/v58/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTag(t *testing.T) {
+	var refs []*github.Reference
+	repository := &Repository{
+		GitService: &mocks.GitService{Refs: &refs},
+		RepositoryMetadata: RepositoryMetadata{
+			Owner: "owner",
+			Name:  "name",
+		},
+		Ctx: context.Background(),
+	}
+	err := repository.CreateTag("v1.0.0", github.String("hash1-hash1"))
+	require.Nil(t, err)
+	require.Len(t, refs, 1)
+	require.Equal(t, "refs/tags/v1.0.0", refs[0].GetRef())
+	require.Equal(t, "hash1-hash1", refs[0].Object.GetSHA())
+}
+
+func TestCreateTag_Error(t *testing.T) {
+	repository := &Repository{
+		GitService: &mocks.GitService{CreateRefError: assert.AnError},
+		Ctx:        context.Background(),
+	}
+	err := repository.CreateTag("v1.0.0", github.String("hash1-hash1"))
+	require.Equal(t, assert.AnError, err)
+}
+
+func TestPublishRelease(t *testing.T) {
+	repositories := &mocks.RepositoryService{}
+	repository := &Repository{
+		RepositoriesService: repositories,
+		RepositoryMetadata: RepositoryMetadata{
+			Owner: "owner",
+			Name:  "name",
+		},
+		Ctx: context.Background(),
+	}
+	release, err := repository.PublishRelease("v1.0.0-rc.0", changelog.Markdown{"## [v1.0.0-rc.0]", "", "notes"}, true)
+	require.Nil(t, err)
+	require.Equal(t, "https://github.com/owner/name/releases/tag/v1.0.0-rc.0", release.GetHTMLURL())
+	require.Len(t, repositories.Releases, 1)
+	require.Equal(t, "v1.0.0-rc.0", repositories.Releases[0].GetName())
+	require.Equal(t, "## [v1.0.0-rc.0]\n\nnotes", repositories.Releases[0].GetBody())
+	require.True(t, repositories.Releases[0].GetPrerelease())
+}
+
+func TestPublishRelease_Error(t *testing.T) {
+	repository := &Repository{
+		RepositoriesService: &mocks.RepositoryService{Inner: assert.AnError},
+		Ctx:                 context.Background(),
+	}
+	release, err := repository.PublishRelease("v1.0.0", changelog.Markdown{}, false)
+	require.Equal(t, assert.AnError, err)
+	require.Nil(t, release)
+}
@@ -2,7 +2,9 @@ package github
 
 import (
 	"context"
+	"fmt"
 	"github.com/google/go-github/v58/github"
+	"path/filepath"
 	"strings"
 )
 
@@ -10,9 +12,12 @@ import (
 type RepositoriesService interface {
 	CompareCommits(ctx context.Context, owner string, repo string, base string, head string, opts *github.ListOptions) (*github.CommitsComparison, *github.Response, error)
 	ListCommits(ctx context.Context, owner string, repo string, opt *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error)
+	GetCommit(ctx context.Context, owner string, repo string, sha string, opts *github.ListOptions) (*github.RepositoryCommit, *github.Response, error)
 	ListTags(ctx context.Context, owner string, repo string, opt *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error)
 	GetBranch(ctx context.Context, owner string, repo string, branch string, maxRedirects int) (*github.Branch, *github.Response, error)
 	Get(ctx context.Context, owner string, repo string) (*github.Repository, *github.Response, error)
+	CreateRelease(ctx context.Context, owner string, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
+	GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (fileContent *github.RepositoryContent, directoryContent []*github.RepositoryContent, resp *github.Response, err error)
 }
 
 // Repository is a struct that contains the RepositoriesService, context, token, owner, and name. It is used to
@@ -26,6 +31,8 @@ type Repository struct {
 	Ctx                context.Context
 	tags               []*github.RepositoryTag
 	versions           *RepositoryVersions
+	files              map[string][]string // paths changed by a commit, keyed by SHA
+	TagPrefix          string              // prefix of the version tags, e.g. api/ for api/v1.0.0
 }
 
 func (r *Repository) getBranch(name string) (*github.Branch, error) {
@@ -54,6 +61,7 @@ func (r *Repository) Branch(name string) (b *Branch, err error) {
 			Branch:              branch,
 			Ctx:                 r.Ctx,
 			Name:                name,
+			repository:          r,
 		}
 		r.branches[name] = b
 	}
@@ -79,3 +87,61 @@ func (r *Repository) CreateBranch(name string, sha *string) (branch *Branch, err
 	}
 	return r.Branch(name)
 }
+
+// CommitFiles returns the paths of the files changed by the commit with the given SHA, walking every page of files. The
+// paths are cached in the repository struct, so subsequent calls for the same commit will not make additional network
+// requests.
+func (r *Repository) CommitFiles(sha string) ([]string, error) {
+	if files, ok := r.files[sha]; ok {
+		return files, nil
+	}
+
+	var files []string
+	nextPage := 0
+	for {
+		commit, response, err := r.GetCommit(r.Ctx, r.RepositoryMetadata.Owner, r.RepositoryMetadata.Name, sha, &github.ListOptions{Page: nextPage, PerPage: 100})
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range commit.Files {
+			files = append(files, file.GetFilename())
+		}
+
+		if response == nil {
+			break
+		}
+		if nextPage = response.NextPage; nextPage == 0 {
+			break // break if there are no more pages
+		}
+	}
+
+	if r.files == nil {
+		r.files = make(map[string][]string)
+	}
+	r.files[sha] = files
+	return files, nil
+}
+
+// CommitsInPath returns the commits that change at least one file within path. An empty path or . matches every
+// commit.
+func (r *Repository) CommitsInPath(commits map[string]*github.RepositoryCommit, path string) (map[string]*github.RepositoryCommit, error) {
+	path = strings.Trim(filepath.ToSlash(filepath.Clean(path)), "/")
+	if path == "" || path == "." {
+		return commits, nil
+	}
+
+	filtered := make(map[string]*github.RepositoryCommit)
+	for sha, commit := range commits {
+		files, err := r.CommitFiles(sha)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get files of commit %s: %w", sha, err)
+		}
+		for _, file := range files {
+			if file == path || strings.HasPrefix(file, path+"/") {
+				filtered[sha] = commit
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
@@ -104,3 +104,39 @@ func TestCreateBranch_Error(t *testing.T) {
 	require.NotNil(t, err)
 	require.Equal(t, errors.New("404"), err)
 }
+
+func TestCommitsInPath(t *testing.T) {
+	service := &mocks.RepositoryService{
+		Files: map[string][]string{
+			"sha1": {"services/api/main.go"},
+			"sha2": {"services/api-gateway/main.go"},
+			"sha3": {"README.md", "services/api"},
+		},
+	}
+	repository := &Repository{RepositoriesService: service, Ctx: context.Background()}
+	commits := map[string]*github.RepositoryCommit{
+		"sha1": {SHA: github.String("sha1")},
+		"sha2": {SHA: github.String("sha2")},
+		"sha3": {SHA: github.String("sha3")},
+	}
+
+	filtered, err := repository.CommitsInPath(commits, "services/api/")
+	require.Nil(t, err)
+	require.Len(t, filtered, 2)
+	require.Contains(t, filtered, "sha1")
+	require.Contains(t, filtered, "sha3")
+
+	filtered, err = repository.CommitsInPath(commits, ".")
+	require.Nil(t, err)
+	require.Len(t, filtered, 3)
+
+	files, err := repository.CommitFiles("sha3")
+	require.Nil(t, err)
+	require.Equal(t, []string{"README.md", "services/api"}, files)
+}
+
+func TestCommitsInPath_Error(t *testing.T) {
+	repository := &Repository{RepositoriesService: &mocks.RepositoryService{Inner: errors.New("error")}, Ctx: context.Background()}
+	_, err := repository.CommitsInPath(map[string]*github.RepositoryCommit{"sha1": {}}, "api")
+	require.EqualError(t, err, "failed to get files of commit sha1: error")
+}
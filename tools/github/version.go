@@ -4,9 +4,11 @@ import (
 	"github.com/google/go-github/v58/github"
 	"github.com/jakbytes/version_actions/tools/semver"
 	"github.com/rs/zerolog/log"
+	"strings"
 )
 
-// Version is a struct that contains the semantic version and the GitHub RepositoryTag associated with it.
+// Version is a struct that contains the semantic version and the GitHub RepositoryTag associated with it. The tag name
+// includes the tag prefix of the repository, if any.
 type Version struct {
 	*semver.Version
 	*github.RepositoryTag
@@ -24,13 +26,29 @@ type RepositoryVersions struct {
 	prerelease map[string]*Versions // prerelease versions, keyed by prerelease name
 }
 
-// Tags returns the list of tags in the repository. The tags are cached in the repository struct, so subsequent calls
+// Tags returns the list of tags in the repository, walking every page of results. The tags are cached in the repository struct, so subsequent calls
 // to Tags will not make additional network requests.
-func (r *Repository) Tags() (tags []*github.RepositoryTag, err error) {
+func (r *Repository) Tags() ([]*github.RepositoryTag, error) {
 	if r.tags == nil {
-		r.tags, _, err = r.ListTags(r.Ctx, r.RepositoryMetadata.Owner, r.RepositoryMetadata.Name, nil)
+		tags := make([]*github.RepositoryTag, 0)
+		nextPage := 0
+		for {
+			page, response, err := r.ListTags(r.Ctx, r.RepositoryMetadata.Owner, r.RepositoryMetadata.Name, &github.ListOptions{Page: nextPage, PerPage: 100})
+			if err != nil {
+				return nil, err
+			}
+			tags = append(tags, page...)
+
+			if response == nil {
+				break
+			}
+			if nextPage = response.NextPage; nextPage == 0 {
+				break // break if there are no more pages
+			}
+		}
+		r.tags = tags
 	}
-	return r.tags, err
+	return r.tags, nil
 }
 
 // Versions returns the stable and prerelease versions for the repository. The tags and versions are cached in the
@@ -53,11 +71,16 @@ func (r *Repository) Versions() (*RepositoryVersions, error) {
 	return r.versions, nil
 }
 
-// parseTag parses the tag as a semantic version, if the tag is not a valid semantic version, it is ignored.
+// parseTag parses the tag as a semantic version, if the tag is not a valid semantic version, it is ignored. Only tags
+// with the TagPrefix of the repository are parsed, the prefix is not part of the version.
 func (r *Repository) parseTag(tag *github.RepositoryTag) {
-	version, err := semver.NewVersion(*tag.Name)
+	name, ok := strings.CutPrefix(tag.GetName(), r.TagPrefix)
+	if !ok {
+		return
+	}
+	version, err := semver.NewVersion(name)
 	if err != nil {
-		log.Warn().Err(err).Msgf("Failed to parse version: %s", version)
+		log.Warn().Err(err).Msgf("Failed to parse version: %s", tag.GetName())
 		return
 	}
 
@@ -148,3 +171,92 @@ func (r *Repository) LatestPrereleaseVersion(prereleaseIdentifier string) (*Vers
 
 	return versions.prerelease[prereleaseIdentifier].latest, nil
 }
+
+// VersionExists returns true if a tag in the repository already represents the given version, either as a release or a
+// prerelease version.
+func (r *Repository) VersionExists(version *semver.Version) (bool, error) {
+	versions, err := r.Versions()
+	if err != nil {
+		return false, err
+	}
+
+	candidates := append([]*Version{}, versions.release.inner...)
+	for _, prerelease := range versions.prerelease {
+		candidates = append(candidates, prerelease.inner...)
+	}
+	for _, candidate := range candidates {
+		if candidate.Equal(version) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NearestVersion returns the Version with the release tag nearest to ref in its history, walking the commits reachable
+// from ref from newest to oldest. Unlike LatestVersion, tags on other lines of history (e.g. a v2.x tag while ref is a
+// release/1.x maintenance branch) are not considered. If no release tag is reachable from ref, an error is returned.
+func (r *Repository) NearestVersion(ref string) (*Version, error) {
+	versions, err := r.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := r.nearest(ref, versions.release)
+	if err == nil && version == nil {
+		return nil, NoReleaseVersionFound{}
+	}
+	return version, err
+}
+
+// NearestDepth is the maximum number of commits walked from ref to find the nearest version, so that a history without
+// release tags is not listed up to its root commit.
+var NearestDepth = 1000
+
+// nearest walks the commits reachable from ref and returns the highest Version tagged on the first commit that has one.
+// If versions is empty, the history is not walked and nil is returned. Nil is also returned if no tagged commit is found
+// within NearestDepth commits.
+func (r *Repository) nearest(ref string, versions *Versions) (*Version, error) {
+	if versions == nil || len(versions.inner) == 0 {
+		return nil, nil
+	}
+
+	tagged := make(map[string][]*Version)
+	for _, version := range versions.inner {
+		if version.Commit != nil && version.Commit.SHA != nil {
+			tagged[*version.Commit.SHA] = append(tagged[*version.Commit.SHA], version)
+		}
+	}
+
+	nextPage, walked := 0, 0
+	for {
+		commits, response, err := r.ListCommits(r.Ctx, r.RepositoryMetadata.Owner, r.RepositoryMetadata.Name, &github.CommitsListOptions{SHA: ref, ListOptions: github.ListOptions{Page: nextPage, PerPage: 100}})
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			if walked >= NearestDepth {
+				log.Warn().Msgf("No release tag found within %d commits of %s", NearestDepth, ref)
+				return nil, nil
+			}
+			walked++
+			var nearest *Version
+			for _, version := range tagged[commit.GetSHA()] {
+				if nearest == nil || version.GreaterThan(nearest.Version) {
+					nearest = version
+				}
+			}
+			if nearest != nil {
+				log.Debug().Msgf("Nearest version to %s: %s", ref, nearest.String())
+				return nearest, nil
+			}
+		}
+
+		if response == nil {
+			break
+		}
+		if nextPage = response.NextPage; nextPage == 0 {
+			break // break if there are no more pages
+		}
+	}
+	return nil, nil
+}
@@ -2,7 +2,9 @@ package github
 
 import (
 	"context"
+	"fmt"
 	"github.com/jakbytes/version_actions/internal/mocks"
+	"github.com/jakbytes/version_actions/tools/semver"
 	"testing"
 
 	"github.com/google/go-github/v58/github"
@@ -61,6 +63,25 @@ func TestLatestVersion_InvalidTag(t *testing.T) {
 	require.Equal(t, NoReleaseVersionFound{}, err)
 }
 
+func TestLatestVersion_TagPrefix(t *testing.T) {
+	repository := &Repository{
+		RepositoriesService: &mocks.RepositoryService{
+			Tags: []*github.RepositoryTag{
+				{Name: github.String("v3.0.0")},
+				{Name: github.String("api/v1.2.0")},
+				{Name: github.String("api/v1.10.0")},
+				{Name: github.String("web/v2.0.0")},
+			},
+		},
+		Ctx:       context.Background(),
+		TagPrefix: "api/",
+	}
+	version, err := repository.LatestVersion()
+	require.Nil(t, err)
+	require.Equal(t, "api/v1.10.0", version.GetName())
+	require.Equal(t, "1.10.0", version.Version.String())
+}
+
 func TestLatestPrereleaseVersion(t *testing.T) {
 	repository := &Repository{
 		RepositoriesService: &mocks.RepositoryService{
@@ -134,3 +155,175 @@ func TestPreviousVersion_NoTags(t *testing.T) {
 	require.NotNil(t, err)
 	require.Equal(t, NoReleaseVersionFound{}, err)
 }
+
+func TestTags_Pagination(t *testing.T) {
+	// Generate 250 release candidate tags, the highest version is on the last page
+	tags := make([]*github.RepositoryTag, 250)
+	for i := 0; i < 250; i++ {
+		tags[i] = &github.RepositoryTag{
+			Name: github.String(fmt.Sprintf("v1.0.0-rc.%d", i)),
+		}
+	}
+	tags = append(tags, &github.RepositoryTag{Name: github.String("v1.0.0")})
+
+	repository := &Repository{
+		RepositoriesService: &mocks.RepositoryService{Tags: tags},
+		Ctx:                 context.Background(),
+	}
+	all, err := repository.Tags()
+	require.Nil(t, err)
+	require.Len(t, all, 251)
+
+	version, err := repository.LatestVersion()
+	require.Nil(t, err)
+	require.Equal(t, "v1.0.0", *version.Name)
+
+	prerelease, err := repository.LatestPrereleaseVersion("rc")
+	require.Nil(t, err)
+	require.Equal(t, "v1.0.0-rc.249", *prerelease.Name)
+}
+
+func TestVersionExists(t *testing.T) {
+	repository := &Repository{
+		RepositoriesService: &mocks.RepositoryService{},
+		Ctx:                 context.Background(),
+	}
+	exists, err := repository.VersionExists(semver.MustParse("1.0.1"))
+	require.Nil(t, err)
+	require.True(t, exists)
+
+	exists, err = repository.VersionExists(semver.MustParse("1.0.2-rc.1"))
+	require.Nil(t, err)
+	require.True(t, exists)
+
+	exists, err = repository.VersionExists(semver.MustParse("1.0.2"))
+	require.Nil(t, err)
+	require.False(t, exists)
+}
+
+func TestVersionExists_Candidates(t *testing.T) {
+	repository := &Repository{
+		RepositoriesService: &mocks.RepositoryService{Tags: []*github.RepositoryTag{
+			{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("hash1-hash1")}},
+			{Name: github.String("v1.0.1"), Commit: &github.Commit{SHA: github.String("hash2-hash2")}},
+			{Name: github.String("v1.0.2"), Commit: &github.Commit{SHA: github.String("hash3-hash3")}},
+			{Name: github.String("v1.0.3-rc.1"), Commit: &github.Commit{SHA: github.String("hash4-hash4")}},
+		}},
+		Ctx: context.Background(),
+	}
+	versions, err := repository.Versions()
+	require.Nil(t, err)
+	release := versions.release.inner
+	require.Greater(t, cap(release), len(release))
+	before := append([]*Version{}, release...)
+
+	exists, err := repository.VersionExists(semver.MustParse("1.0.3-rc.1"))
+	require.Nil(t, err)
+	require.True(t, exists)
+	// the release versions are unchanged and the prerelease versions are not appended to them in place
+	versions, err = repository.Versions()
+	require.Nil(t, err)
+	require.Equal(t, before, versions.release.inner)
+	for _, version := range release[len(release):cap(release)] {
+		require.Nil(t, version)
+	}
+}
+
+func TestVersionExists_Error(t *testing.T) {
+	repository := &Repository{
+		RepositoriesService: &mocks.RepositoryService{
+			Inner: assert.AnError,
+		},
+		Ctx: context.Background(),
+	}
+	_, err := repository.VersionExists(semver.MustParse("1.0.2"))
+	require.Equal(t, assert.AnError, err)
+}
+
+func TestNearestVersion(t *testing.T) {
+	repository := &Repository{
+		RepositoriesService: &mocks.RepositoryService{
+			Commits: []*github.RepositoryCommit{
+				{SHA: github.String("hash3-hash3")},
+				{SHA: github.String("hash2-hash2")},
+				{SHA: github.String("hash1-hash1")},
+			},
+			Tags: []*github.RepositoryTag{
+				{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("hash1-hash1")}},
+				{Name: github.String("v1.1.0"), Commit: &github.Commit{SHA: github.String("hash2-hash2")}},
+				{Name: github.String("v1.1.0-rc.1"), Commit: &github.Commit{SHA: github.String("hash3-hash3")}},
+				{Name: github.String("v2.0.0"), Commit: &github.Commit{SHA: github.String("hash4-hash4")}}, // another line of history
+				{Name: github.String("v2.0.0-rc.0"), Commit: &github.Commit{SHA: github.String("hash4-hash4")}},
+			},
+		},
+		branches: make(map[string]*Branch),
+		Ctx:      context.Background(),
+	}
+
+	latest, err := repository.LatestVersion()
+	require.Nil(t, err)
+	require.Equal(t, "v2.0.0", *latest.Name)
+
+	branch, err := repository.Branch("release/1.x")
+	require.Nil(t, err)
+
+	nearest, err := branch.NearestVersion()
+	require.Nil(t, err)
+	require.Equal(t, "v1.1.0", *nearest.Name)
+}
+
+func TestNearestVersion_NotReachable(t *testing.T) {
+	repository := &Repository{
+		RepositoriesService: &mocks.RepositoryService{
+			Commits: []*github.RepositoryCommit{
+				{SHA: github.String("hash1-hash1")},
+			},
+			Tags: []*github.RepositoryTag{
+				{Name: github.String("v2.0.0"), Commit: &github.Commit{SHA: github.String("hash4-hash4")}},
+			},
+		},
+		Ctx: context.Background(),
+	}
+
+	_, err := repository.NearestVersion("release/1.x")
+	require.Equal(t, NoReleaseVersionFound{}, err)
+}
+
+func TestNearestVersion_Depth(t *testing.T) {
+	depth := NearestDepth
+	defer func() { NearestDepth = depth }()
+	NearestDepth = 2
+
+	repository := &Repository{
+		RepositoriesService: &mocks.RepositoryService{
+			Commits: []*github.RepositoryCommit{
+				{SHA: github.String("hash3-hash3")},
+				{SHA: github.String("hash2-hash2")},
+				{SHA: github.String("hash1-hash1")},
+			},
+			Tags: []*github.RepositoryTag{
+				{Name: github.String("v1.0.0"), Commit: &github.Commit{SHA: github.String("hash1-hash1")}},
+			},
+		},
+		Ctx: context.Background(),
+	}
+
+	_, err := repository.NearestVersion("main")
+	require.Equal(t, NoReleaseVersionFound{}, err)
+
+	NearestDepth = 3
+	nearest, err := repository.NearestVersion("main")
+	require.Nil(t, err)
+	require.Equal(t, "v1.0.0", *nearest.Name)
+}
+
+func TestNearestVersion_Error(t *testing.T) {
+	repository := &Repository{
+		RepositoriesService: &mocks.RepositoryService{
+			Inner: assert.AnError,
+		},
+		Ctx: context.Background(),
+	}
+	_, err := repository.NearestVersion("main")
+	require.Equal(t, assert.AnError, err)
+}
@@ -54,6 +54,11 @@ func (v *Version) AsPrereleaseVersion(prereleaseIdentifier string, versionNumber
 func (v *Version) GreaterThan(version *Version) bool {
 	return v.Version.GreaterThan(version.Version)
 }
+
+func (v *Version) Equal(version *Version) bool {
+	return v.Version.Equal(version.Version)
+}
+
 func (v *Version) LessThan(version *Version) bool {
 	return v.Version.LessThan(version.Version)
 }
@@ -113,3 +113,16 @@ func TestLessThan(t *testing.T) {
 		t.Errorf("Expected %s not to be less than %s", v2.String(), v1.String())
 	}
 }
+
+func TestEqual(t *testing.T) {
+	v1, _ := NewVersion("v1.2.3")
+	v2, _ := NewVersion("1.2.3")
+	v3, _ := NewVersion("1.2.3-rc.0")
+	if !v1.Equal(v2) {
+		t.Errorf("Expected %s to be equal to %s", v1.String(), v2.String())
+	}
+
+	if v1.Equal(v3) {
+		t.Errorf("Expected %s not to be equal to %s", v1.String(), v3.String())
+	}
+}
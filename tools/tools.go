@@ -1,37 +1,108 @@
 package tools
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/jakbytes/version_actions/internal/utility"
 	"os"
+	"strconv"
+	"strings"
 )
 
 func String(input string) *string {
 	return &input
 }
 
+// Output is the GITHUB_OUTPUT file the step outputs are written to. The first error setting an output is kept and
+// returned by OpenOutput, later outputs are not written.
 type Output struct {
 	*os.File
+	err error
 }
 
-func OpenOutput(handler func(out Output)) {
+// OpenOutput opens the GITHUB_OUTPUT file and passes it to the handler, nothing is done if GITHUB_OUTPUT is not set.
+func OpenOutput(handler func(out *Output)) error {
 	if os.Getenv("GITHUB_OUTPUT") == "" {
-		return
+		return nil
 	}
 	err := utility.OpenFile(os.Getenv("GITHUB_OUTPUT"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644, func(file *os.File) error {
-		handler(Output{file})
+		out := &Output{File: file}
+		handler(out)
+		return out.err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set outputs: %w", err)
+	}
+	return nil
+}
+
+// AppendSummary appends the Markdown content to the GITHUB_STEP_SUMMARY file shown on the summary page of the workflow
+// run, nothing is done if GITHUB_STEP_SUMMARY is not set.
+func AppendSummary(content string) error {
+	if os.Getenv("GITHUB_STEP_SUMMARY") == "" {
 		return nil
+	}
+	err := utility.OpenFile(os.Getenv("GITHUB_STEP_SUMMARY"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644, func(file *os.File) error {
+		_, err := file.WriteString(content + "\n")
+		return err
 	})
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to write the step summary: %w", err)
 	}
+	return nil
 }
 
+// Set sets the output to value, nothing is set if value is nil. Values containing newlines are written with the
+// multi-line key<<DELIMITER syntax, using a random delimiter that does not occur in the value.
 func (o *Output) Set(key string, value *string) {
-	if value == nil {
+	if value == nil || o.err != nil {
+		return
+	}
+	if strings.ContainsAny(key, "=<\r\n") {
+		o.err = fmt.Errorf("invalid output name %q", key)
 		return
 	}
-	if _, err := o.WriteString(fmt.Sprintf("%s=%s\n", key, *value)); err != nil {
+	line := fmt.Sprintf("%s=%s\n", key, *value)
+	if strings.ContainsAny(*value, "\r\n") {
+		delimiter := Delimiter()
+		for strings.Contains(*value, delimiter) {
+			delimiter = Delimiter()
+		}
+		line = fmt.Sprintf("%s<<%s\n%s\n%s\n", key, delimiter, *value, delimiter)
+	}
+	_, o.err = o.WriteString(line)
+}
+
+// SetString sets the output to value.
+func (o *Output) SetString(key string, value string) {
+	o.Set(key, &value)
+}
+
+// SetBool sets the output to true or false.
+func (o *Output) SetBool(key string, value bool) {
+	o.SetString(key, strconv.FormatBool(value))
+}
+
+// SetJSON sets the output to the JSON encoding of value, e.g. for use with fromJSON in workflow matrices.
+func (o *Output) SetJSON(key string, value any) {
+	if o.err != nil {
+		return
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		o.err = fmt.Errorf("failed to encode output %s: %w", key, err)
+		return
+	}
+	o.SetString(key, string(encoded))
+}
+
+// Delimiter returns a random delimiter for multi-line output values.
+var Delimiter = func() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
 		panic(err)
 	}
+	return "ghadelimiter_" + hex.EncodeToString(b)
 }
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readOutput runs the handler with GITHUB_OUTPUT set to a temporary file and returns the content written to it.
+func readOutput(t *testing.T, handler func(out *Output)) string {
+	path := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", path)
+	require.Nil(t, OpenOutput(handler))
+	content, err := os.ReadFile(path)
+	require.Nil(t, err)
+	return string(content)
+}
+
+func TestOutput_Set(t *testing.T) {
+	original := Delimiter
+	delimiters := []string{"EOF", "DELIMITER"}
+	Delimiter = func() string {
+		delimiter := delimiters[0]
+		delimiters = delimiters[1:]
+		return delimiter
+	}
+	defer func() { Delimiter = original }()
+
+	content := readOutput(t, func(out *Output) {
+		out.Set("empty", nil)
+		out.Set("type", String("feat"))
+		out.Set("body", String("first line\nEOF\nlast line"))
+	})
+	require.Equal(t, "type=feat\nbody<<DELIMITER\nfirst line\nEOF\nlast line\nDELIMITER\n", content)
+}
+
+func TestOutput_SetTyped(t *testing.T) {
+	content := readOutput(t, func(out *Output) {
+		out.SetString("version", "v1.0.0")
+		out.SetBool("exclamation", true)
+		out.SetJSON("components", []map[string]string{{"name": "api"}})
+	})
+	require.Equal(t, "version=v1.0.0\nexclamation=true\ncomponents=[{\"name\":\"api\"}]\n", content)
+}
+
+func TestOutput_Error(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", path)
+	err := OpenOutput(func(out *Output) {
+		out.SetString("a=b", "value")
+		out.SetJSON("invalid", make(chan int))
+		out.SetString("version", "v1.0.0")
+	})
+	require.EqualError(t, err, `failed to set outputs: invalid output name "a=b"`)
+	content, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Empty(t, content)
+
+	err = OpenOutput(func(out *Output) { out.SetJSON("invalid", make(chan int)) })
+	require.ErrorContains(t, err, "failed to encode output invalid")
+}
+
+func TestDelimiter(t *testing.T) {
+	require.Regexp(t, "^ghadelimiter_[0-9a-f]{32}$", Delimiter())
+	require.NotEqual(t, Delimiter(), Delimiter())
+}
+
+func TestOpenOutput_Unset(t *testing.T) {
+	t.Setenv("GITHUB_OUTPUT", "")
+	called := false
+	require.Nil(t, OpenOutput(func(out *Output) { called = true }))
+	require.False(t, called)
+}
+
+func TestAppendSummary(t *testing.T) {
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	require.Nil(t, AppendSummary("## ignored"))
+
+	path := filepath.Join(t.TempDir(), "summary")
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+	require.Nil(t, AppendSummary("## First"))
+	require.Nil(t, AppendSummary("## Second"))
+	content, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, "## First\n## Second\n", string(content))
+}
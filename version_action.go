@@ -1,28 +1,132 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	gogithub "github.com/google/go-github/v58/github"
+	"github.com/jakbytes/version_actions/action/explain"
 	"github.com/jakbytes/version_actions/action/extract_commit"
 	"github.com/jakbytes/version_actions/action/pull_request"
+	"github.com/jakbytes/version_actions/action/release"
 	"github.com/jakbytes/version_actions/action/version"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/config"
 	"github.com/jakbytes/version_actions/internal/logger"
+	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/jakbytes/version_actions/tools/github/composite"
 	"github.com/rs/zerolog/log"
+	"io"
 	"os"
 )
 
-func main() {
-	log.Logger = logger.Base()
-	action := os.Args[1]
-	switch action {
+const usage = `Usage: version_action <command> [flags]
+
+Commands:
+  version         compute the next version and open or update the release pull request
+  pull_request    open or update a draft pull request with the changelog of its commits
+  release         tag and publish a release when the release pull request was merged
+  extract_commit  output the last valid conventional commit of a branch
+  explain         explain how the next version is derived from the commits, as Markdown
+
+Run 'version_action <command> --help' for the flags of a command. Flags that are not provided fall back to the
+environment variables GitHub Actions sets, such as GITHUB_TOKEN, GITHUB_REPOSITORY and GITHUB_REF_NAME.
+`
+
+func printUsage(w io.Writer) {
+	_, _ = fmt.Fprint(w, usage)
+}
+
+// Exit codes of version_action, see the README.
+const (
+	ExitOK       = 0 // the command succeeded
+	ExitFailure  = 1 // any error not covered by the other codes
+	ExitUsage    = 2 // unknown command, invalid flags or missing inputs
+	ExitConfig   = 3 // invalid configuration file
+	ExitGitHub   = 4 // a GitHub API request failed
+	ExitConflict = 5 // the version already exists or does not match the merged release pull request
+)
+
+// commandError is an error of the named command.
+type commandError struct {
+	command string
+	err     error
+}
+
+func (e commandError) Error() string {
+	return e.err.Error()
+}
+
+func (e commandError) Unwrap() error {
+	return e.err
+}
+
+// run runs the command in args, the arguments following the program name.
+func run(args []string) error {
+	if len(args) < 1 {
+		printUsage(os.Stderr)
+		return cli.UsageError{Err: errors.New("missing command")}
+	}
+
+	command, input := args[0], args[1:]
+	var err error
+	switch command {
 	case "release":
 		log.Info().Msg("Release action")
+		err = release.Execute(input)
 	case "version":
 		log.Info().Msg("Version action")
-		version.Execute()
+		err = version.Execute(input)
 	case "pull_request":
 		log.Info().Msg("Pull request action")
-		pull_request.Execute()
+		err = pull_request.Execute(input)
 	case "extract_commit":
 		log.Info().Msg("Extract commit action")
-		extract_commit.ExtractCommit()
+		err = extract_commit.ExtractCommit(input)
+	case "explain":
+		log.Info().Msg("Explain action")
+		err = explain.Execute(input)
+	case "help", "-h", "-help", "--help":
+		printUsage(os.Stdout)
+	default:
+		printUsage(os.Stderr)
+		return cli.UsageError{Err: fmt.Errorf("unknown command %q", command)}
+	}
+	if err != nil {
+		return commandError{command: command, err: err}
+	}
+	return nil
+}
+
+// exitCode returns the exit code for the error and the title it is reported with.
+func exitCode(err error) (int, string) {
+	var command commandError
+	var githubErr *gogithub.ErrorResponse
+	var rateLimitErr *gogithub.RateLimitError
+	var abuseErr *gogithub.AbuseRateLimitError
+	switch {
+	case err == nil:
+		return ExitOK, ""
+	case errors.As(err, &cli.UsageError{}), errors.As(err, &cli.MissingInputError{}):
+		return ExitUsage, "Invalid input"
+	case errors.As(err, &config.Error{}):
+		return ExitConfig, "Invalid configuration"
+	case errors.As(err, &githubErr), errors.As(err, &rateLimitErr), errors.As(err, &abuseErr):
+		return ExitGitHub, "GitHub API request failed"
+	case errors.As(err, &github.VersionAlreadyExists{}), errors.As(err, &composite.VersionMismatchError{}),
+		errors.As(err, &composite.ReleaseAsError{}):
+		return ExitConflict, "Version conflict"
+	case errors.As(err, &command):
+		return ExitFailure, command.command + " failed"
+	}
+	return ExitFailure, "version_action failed"
+}
+
+func main() {
+	log.Logger = logger.Base()
+	err := run(os.Args[1:])
+	code, title := exitCode(err)
+	if err != nil {
+		logger.Error(title, err)
 	}
+	os.Exit(code)
 }
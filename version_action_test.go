@@ -2,11 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	gogithub "github.com/google/go-github/v58/github"
 	"github.com/jakbytes/version_actions/action/pull_request"
 	"github.com/jakbytes/version_actions/action/version"
+	"github.com/jakbytes/version_actions/internal/cli"
+	"github.com/jakbytes/version_actions/internal/config"
 	"github.com/jakbytes/version_actions/internal/mocks"
 	"github.com/jakbytes/version_actions/tools/changelog"
 	"github.com/jakbytes/version_actions/tools/github"
+	"github.com/jakbytes/version_actions/tools/github/composite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"os"
@@ -31,6 +37,12 @@ const devReleaseBranch = "release--branch--development"
 const devPromoteBranch = "release--branch--staging"
 const stagingPromoteBranch = "release--branch--main"
 
+func TestMain(m *testing.M) {
+	// the token is read from the environment, it is not accepted as a flag
+	_ = os.Setenv("INPUT_TOKEN", "token")
+	os.Exit(m.Run())
+}
+
 func newClient(ctx context.Context, token string, owner string, name string) *github.Client {
 	return &github.Client{
 		Repositories: repositories,
@@ -44,8 +56,12 @@ func newClient(ctx context.Context, token string, owner string, name string) *gi
 }
 
 func TestAction(t *testing.T) {
+	// the changelog and the release notes are written to the working directory
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+	require.Nil(t, os.Chdir(t.TempDir()))
+	defer func() { require.Nil(t, os.Chdir(wd)) }()
 	changelog.Path = "test_CHANGELOG.md"
-	defer os.Remove(changelog.Path)
 	// starting from repository with no tags and two branches main, development
 	version.NewClient = newClient
 	pull_request.NewClient = newClient
@@ -97,9 +113,9 @@ func testFeaturePR(t *testing.T) {
 
 	// starting from repository with no tags and three branches main, staging, development
 
-	os.Args = []string{"program", "action", "token", repositoryOwner, repositoryName, featureBranch, devBranch}
+	input := []string{"--owner", repositoryOwner, "--name", repositoryName, "--head", featureBranch, "--base", devBranch}
 	assert.NotPanics(t, func() {
-		pull_request.Execute()
+		pull_request.Execute(input)
 	}, "pull request generation should not panic")
 
 	require.Equal(t, 1, len(prs.PullRequests))
@@ -175,8 +191,9 @@ func testUpdateFeaturePR(t *testing.T) {
 
 	require.Equal(t, 1, len(prs.PullRequests))
 
+	input := []string{"--owner", repositoryOwner, "--name", repositoryName, "--head", featureBranch, "--base", devBranch}
 	assert.NotPanics(t, func() {
-		pull_request.Execute()
+		pull_request.Execute(input)
 	}, "pull request generation should not panic")
 
 	require.Equal(t, 1, len(prs.PullRequests))
@@ -220,10 +237,10 @@ func testUpdateFeaturePR(t *testing.T) {
 func testVersionRC(t *testing.T) {
 	prs.PullRequests = []*github.PullRequest{}    // pull request was merged into development
 	repositories.Tags = []*github.RepositoryTag{} // no tags in the repository
-	os.Args = []string{"program", "release", "token", repositoryOwner, repositoryName, devBranch, devBranch, devPrereleaseIdentifier, mainBranch, "none"}
+	input := []string{"--owner", repositoryOwner, "--name", repositoryName, "--head", devBranch, "--base", devBranch, "--prerelease", devPrereleaseIdentifier, "--release-branch", mainBranch, "--trigger", "none"}
 
 	assert.NotPanics(t, func() {
-		version.Execute()
+		version.Execute(input)
 	}, "version generation should not panic")
 
 	require.Equal(t, 1, len(prs.PullRequests))
@@ -314,10 +331,10 @@ func testUpdateVersionRC(t *testing.T) {
 
 	repositories.Comparison.Commits = repositories.Commits
 
-	os.Args = []string{"program", "release", "token", repositoryOwner, repositoryName, devBranch, devBranch, devPrereleaseIdentifier, mainBranch, "none"}
+	input := []string{"--owner", repositoryOwner, "--name", repositoryName, "--head", devBranch, "--base", devBranch, "--prerelease", devPrereleaseIdentifier, "--release-branch", mainBranch, "--trigger", "none"}
 
 	assert.NotPanics(t, func() {
-		version.Execute()
+		version.Execute(input)
 	}, "version generation should not panic")
 
 	require.Equal(t, 1, len(prs.PullRequests))
@@ -362,10 +379,10 @@ func testPrereleasePromotion(t *testing.T) {
 			Name: github.String("v0.0.0-drc.0"),
 		},
 	} // no tags in the repository
-	os.Args = []string{"program", "release", "token", repositoryOwner, repositoryName, devBranch, stagingBranch, stagingPrereleaseIdentifier, mainBranch, "none"}
+	input := []string{"--owner", repositoryOwner, "--name", repositoryName, "--head", devBranch, "--base", stagingBranch, "--prerelease", stagingPrereleaseIdentifier, "--release-branch", mainBranch, "--trigger", "none"}
 
 	assert.NotPanics(t, func() {
-		version.Execute()
+		version.Execute(input)
 	}, "promote generation should not panic")
 
 	require.Equal(t, 1, len(prs.PullRequests))
@@ -482,10 +499,10 @@ func testUpdatePrereleasePromotion(t *testing.T) {
 
 	repositories.Comparison.Commits = repositories.Commits
 
-	os.Args = []string{"program", "release", "token", repositoryOwner, repositoryName, devBranch, stagingBranch, stagingPrereleaseIdentifier, mainBranch, "none"}
+	input := []string{"--owner", repositoryOwner, "--name", repositoryName, "--head", devBranch, "--base", stagingBranch, "--prerelease", stagingPrereleaseIdentifier, "--release-branch", mainBranch, "--trigger", "none"}
 
 	assert.NotPanics(t, func() {
-		version.Execute()
+		version.Execute(input)
 	}, "version generation should not panic")
 
 	require.Equal(t, 1, len(prs.PullRequests))
@@ -600,10 +617,10 @@ func testPromotion(t *testing.T) {
 	}
 	repositories.Comparison.Commits = repositories.Commits
 
-	os.Args = []string{"program", "release", "token", repositoryOwner, repositoryName, stagingBranch, mainBranch, stagingPrereleaseIdentifier, mainBranch, "none"}
+	input := []string{"--owner", repositoryOwner, "--name", repositoryName, "--head", stagingBranch, "--base", mainBranch, "--prerelease", stagingPrereleaseIdentifier, "--release-branch", mainBranch, "--trigger", "none"}
 
 	assert.NotPanics(t, func() {
-		version.Execute()
+		version.Execute(input)
 	}, "promote generation should not panic")
 
 	require.Equal(t, 1, len(prs.PullRequests))
@@ -718,10 +735,10 @@ func testUpdatePromotion(t *testing.T) {
 		},
 	}
 
-	os.Args = []string{"program", "release", "token", repositoryOwner, repositoryName, stagingBranch, mainBranch, stagingPrereleaseIdentifier, mainBranch, "none"}
+	input := []string{"--owner", repositoryOwner, "--name", repositoryName, "--head", stagingBranch, "--base", mainBranch, "--prerelease", stagingPrereleaseIdentifier, "--release-branch", mainBranch, "--trigger", "none"}
 
 	assert.NotPanics(t, func() {
-		version.Execute()
+		version.Execute(input)
 	}, "version generation should not panic")
 
 	require.Equal(t, 1, len(prs.PullRequests))
@@ -759,3 +776,44 @@ func testUpdatePromotion(t *testing.T) {
 		}
 	}
 }
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		code  int
+		title string
+	}{
+		{"ok", nil, ExitOK, ""},
+		{"usage", cli.UsageError{Err: errors.New("flag provided but not defined: -token")}, ExitUsage, "Invalid input"},
+		{"missing input", commandError{"version", cli.MissingInputError{Name: "owner"}}, ExitUsage, "Invalid input"},
+		{"configuration", commandError{"version", config.Error{Path: ".version_actions.yml", Err: errors.New("version is required")}}, ExitConfig, "Invalid configuration"},
+		{"github", commandError{"release", fmt.Errorf("failed to create tag: %w", &gogithub.ErrorResponse{Message: "Bad credentials"})}, ExitGitHub, "GitHub API request failed"},
+		{"rate limit", commandError{"release", &gogithub.RateLimitError{Message: "API rate limit exceeded"}}, ExitGitHub, "GitHub API request failed"},
+		{"version exists", commandError{"version", github.VersionAlreadyExists{Version: "1.0.0"}}, ExitConflict, "Version conflict"},
+		{"version mismatch", commandError{"release", composite.VersionMismatchError{Number: 1, Proposed: "v1.1.0", Computed: "v1.2.0"}}, ExitConflict, "Version conflict"},
+		{"other", commandError{"pull_request", errors.New("failed")}, ExitFailure, "pull_request failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, title := exitCode(tt.err)
+			require.Equal(t, tt.code, code)
+			require.Equal(t, tt.title, title)
+		})
+	}
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	stderr := os.Stderr
+	os.Stderr, _ = os.Open(os.DevNull)
+	defer func() { os.Stderr = stderr }()
+
+	err := run([]string{"unknown"})
+	require.EqualError(t, err, `unknown command "unknown"`)
+	code, _ := exitCode(err)
+	require.Equal(t, ExitUsage, code)
+
+	code, _ = exitCode(run(nil))
+	require.Equal(t, ExitUsage, code)
+}